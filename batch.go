@@ -0,0 +1,82 @@
+package checkfs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/andreimerlescu/checkfs/common"
+	"github.com/andreimerlescu/checkfs/file"
+)
+
+// FileJob is a single path and the file.Options to validate it against in FileBatch
+type FileJob struct {
+	Path string
+	Opts file.Options
+}
+
+// FileBatch validates every job with at most concurrency goroutines, runtime.NumCPU() when concurrency is zero or
+// negative, and returns each job's error keyed by its Path; passing jobs map to nil. Once ctx is done, jobs that have
+// not started yet fail with ctx.Err(). When several jobs share a Path the last one to finish wins.
+func FileBatch(ctx context.Context, jobs []FileJob, concurrency int) map[string]error {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(jobs))
+		sem     = make(chan struct{}, concurrency)
+	)
+	record := func(path string, err error) {
+		mu.Lock()
+		results[path] = err
+		mu.Unlock()
+	}
+	for _, job := range jobs {
+		if err := ctx.Err(); err != nil {
+			record(job.Path, err)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			record(job.Path, ctx.Err())
+			continue
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(job FileJob) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			record(job.Path, file.FileContext(ctx, job.Path, job.Opts))
+		}(job)
+	}
+	wg.Wait()
+	return results
+}
+
+// FileGlob validates every path matching pattern, as filepath.Glob expands it, against opts and returns each path's
+// error; passing paths map to nil. The error is for the call as a whole: a malformed pattern or invalid opts, both
+// wrapping file.ErrInvalidOptions, or, when requireMatch is set, a pattern matching nothing, which wraps
+// file.ErrDoesNotExist. Without requireMatch no match is an empty map.
+func FileGlob(pattern string, opts file.Options, requireMatch bool) (map[string]error, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: glob pattern %q: %w", file.ErrInvalidOptions, pattern, err)
+	}
+	if len(matches) == 0 && requireMatch {
+		return nil, common.Errorf(file.ErrDoesNotExist, "no file matches %s", pattern)
+	}
+	results := make(map[string]error, len(matches))
+	for _, path := range matches {
+		results[path] = file.File(path, opts)
+	}
+	return results, nil
+}
@@ -0,0 +1,118 @@
+package checkfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andreimerlescu/checkfs/file"
+)
+
+func TestFileBatch(t *testing.T) {
+	dir := t.TempDir()
+	var jobs []FileJob
+	for i := 0; i < 20; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("file-%02d.txt", i))
+		if i%2 == 0 {
+			if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+		}
+		jobs = append(jobs, FileJob{Path: path, Opts: file.Options{Exists: true}})
+	}
+	jobs = append(jobs, FileJob{Path: filepath.Join(dir, "invalid.txt"), Opts: file.Options{NonEmpty: true, MustBeEmpty: true}})
+
+	for _, concurrency := range []int{0, 1, 4, 100} {
+		t.Run(fmt.Sprintf("Concurrency %d", concurrency), func(t *testing.T) {
+			results := FileBatch(context.Background(), jobs, concurrency)
+			if len(results) != len(jobs) {
+				t.Fatalf("FileBatch() returned %d results, want %d", len(results), len(jobs))
+			}
+			for i, job := range jobs[:20] {
+				err := results[job.Path]
+				if i%2 == 0 && err != nil {
+					t.Errorf("FileBatch()[%s] = %v, want nil", job.Path, err)
+				}
+				if i%2 == 1 && !errors.Is(err, file.ErrDoesNotExist) {
+					t.Errorf("FileBatch()[%s] = %v, want file.ErrDoesNotExist", job.Path, err)
+				}
+			}
+			if err := results[jobs[20].Path]; !errors.Is(err, file.ErrInvalidOptions) {
+				t.Errorf("FileBatch()[%s] = %v, want file.ErrInvalidOptions", jobs[20].Path, err)
+			}
+		})
+	}
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := FileBatch(ctx, jobs, 2)
+		if len(results) != len(jobs) {
+			t.Fatalf("FileBatch() returned %d results, want %d", len(results), len(jobs))
+		}
+		for path, err := range results {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("FileBatch()[%s] = %v, want context.Canceled", path, err)
+			}
+		}
+	})
+
+	t.Run("No jobs", func(t *testing.T) {
+		if results := FileBatch(context.Background(), nil, 0); len(results) != 0 {
+			t.Errorf("FileBatch() = %v, want an empty map", results)
+		}
+	})
+}
+
+func TestFileGlob(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"app.conf": "port=80", "db.conf": "", "notes.txt": "x"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	results, err := FileGlob(filepath.Join(dir, "*.conf"), file.Options{NonEmpty: true}, true)
+	if err != nil {
+		t.Fatalf("FileGlob() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("FileGlob() = %v, want 2 results", results)
+	}
+	if err := results[filepath.Join(dir, "app.conf")]; err != nil {
+		t.Errorf("FileGlob() app.conf error = %v, want nil", err)
+	}
+	if err := results[filepath.Join(dir, "db.conf")]; !errors.Is(err, file.ErrSizeMismatch) {
+		t.Errorf("FileGlob() db.conf error = %v, want ErrSizeMismatch", err)
+	}
+
+	tests := []struct {
+		name         string
+		pattern      string
+		opts         file.Options
+		requireMatch bool
+		wantErr      error
+	}{
+		{"No match allowed", "*.yaml", file.Options{}, false, nil},
+		{"No match required", "*.yaml", file.Options{}, true, file.ErrDoesNotExist},
+		{"Malformed pattern", "[", file.Options{}, false, file.ErrInvalidOptions},
+		{"Invalid options", "*.conf", file.Options{NonEmpty: true, MustBeEmpty: true}, false, file.ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := FileGlob(filepath.Join(dir, tt.pattern), tt.opts, tt.requireMatch)
+			if tt.wantErr == nil {
+				if err != nil || len(results) != 0 {
+					t.Errorf("FileGlob() = %v, %v, want no results and no error", results, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) || results != nil {
+				t.Errorf("FileGlob() = %v, %v, want nil and %v", results, err, tt.wantErr)
+			}
+		})
+	}
+}
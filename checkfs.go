@@ -3,6 +3,11 @@
 package checkfs
 
 import (
+	"context"
+	"io/fs"
+	"os"
+
+	"github.com/andreimerlescu/checkfs/common"
 	"github.com/andreimerlescu/checkfs/directory"
 	"github.com/andreimerlescu/checkfs/file"
 )
@@ -16,3 +21,153 @@ func File(path string, opts file.Options) error {
 func Directory(path string, opts directory.Options) error {
 	return directory.Directory(path, opts)
 }
+
+// FileContext will use the file package to validate the file.Options passed into the path, stopping when ctx is done
+func FileContext(ctx context.Context, path string, opts file.Options) error {
+	return file.FileContext(ctx, path, opts)
+}
+
+// DirectoryContext will use the directory package to validate the directory.Options passed into the path, stopping
+// when ctx is done
+func DirectoryContext(ctx context.Context, path string, opts directory.Options) error {
+	return directory.DirectoryContext(ctx, path, opts)
+}
+
+// FileInfoCheck will use the file package to validate the file.Options passed into the path against info, the
+// os.FileInfo the caller already has, without stat'ing path again
+func FileInfoCheck(path string, info os.FileInfo, opts file.Options) error {
+	return file.FileWithInfo(path, info, opts)
+}
+
+// StatFile will use the file package to validate the file.Options passed into the path and return the os.FileInfo the
+// checks ran against, nil when there was no file to check
+func StatFile(path string, opts file.Options) (os.FileInfo, error) {
+	return file.Stat(path, opts)
+}
+
+// StatDirectory will use the directory package to validate the directory.Options passed into the path and return the
+// os.FileInfo the checks ran against, nil when there was no directory to check
+func StatDirectory(path string, opts directory.Options) (os.FileInfo, error) {
+	return directory.Stat(path, opts)
+}
+
+// FileAll will use the file package to validate every file.Options check and return all failures
+func FileAll(path string, opts file.Options) []error {
+	return file.FileAll(path, opts)
+}
+
+// DirectoryAll will use the directory package to validate every directory.Options check and return all failures
+func DirectoryAll(path string, opts directory.Options) []error {
+	return directory.DirectoryAll(path, opts)
+}
+
+// Result is the structured outcome of CheckFileResult and CheckDirectoryResult
+type Result = common.Result
+
+// Violation is a single failed check in a Result
+type Violation = common.Violation
+
+// Code is the stable, machine-readable category of a Violation
+type Code = common.Code
+
+// Codes a Violation can carry; they never change once released
+const (
+	CodeInvalidOptions     = common.CodeInvalidOptions
+	CodeDoesNotExist       = common.CodeDoesNotExist
+	CodeAlreadyExists      = common.CodeAlreadyExists
+	CodeNotRegularFile     = common.CodeNotRegularFile
+	CodeNotDirectory       = common.CodeNotDirectory
+	CodeSizeMismatch       = common.CodeSizeMismatch
+	CodeTimeMismatch       = common.CodeTimeMismatch
+	CodeNameMismatch       = common.CodeNameMismatch
+	CodeBadBaseDir         = common.CodeBadBaseDir
+	CodeSymlinkMismatch    = common.CodeSymlinkMismatch
+	CodePermissionMismatch = common.CodePermissionMismatch
+	CodeOwnerMismatch      = common.CodeOwnerMismatch
+	CodeGroupMismatch      = common.CodeGroupMismatch
+	CodeContentMismatch    = common.CodeContentMismatch
+	CodeIdentityMismatch   = common.CodeIdentityMismatch
+	CodeUnsupported        = common.CodeUnsupported
+	CodeCanceled           = common.CodeCanceled
+	CodeUnknown            = common.CodeUnknown
+)
+
+// CheckFileResult will use the file package to validate every file.Options check and return them as a Result
+func CheckFileResult(path string, opts file.Options) Result {
+	return file.FileResult(path, opts)
+}
+
+// CheckDirectoryResult will use the directory package to validate every directory.Options check and return them as a
+// Result
+func CheckDirectoryResult(path string, opts directory.Options) Result {
+	return directory.DirectoryResult(path, opts)
+}
+
+// Report describes a path and every check configured for it, see InspectFile
+type Report = common.Report
+
+// CheckResult is the outcome of a single check in a Report
+type CheckResult = common.CheckResult
+
+// InspectFile will use the file package to run every file.Options check and report each one as passed or failed,
+// returning an error only when the file cannot be checked at all
+func InspectFile(path string, opts file.Options) (*Report, error) {
+	return file.Inspect(path, opts)
+}
+
+// FileFS will use the file package to validate the file.Options passed into the path inside fsys
+func FileFS(fsys fs.FS, path string, opts file.Options) error {
+	return file.FileFS(fsys, path, opts)
+}
+
+// DirectoryFS will use the directory package to validate the directory.Options passed into the path inside fsys
+func DirectoryFS(fsys fs.FS, path string, opts directory.Options) error {
+	return directory.DirectoryFS(fsys, path, opts)
+}
+
+// CheckerOptions configures a Checker
+type CheckerOptions struct {
+	CacheStats     bool // CacheStats remembers each successful os.Stat for the life of the Checker, or until Reset
+	MaxCachedStats int  // MaxCachedStats caps the number of remembered stats; 0 means no cap
+}
+
+// Checker validates many paths with shared state. With CacheStats set, every path and parent it stats is stat'd once
+// and the result reused, keyed by cleaned absolute path, so results go stale as soon as the filesystem changes: use it
+// for short-lived batch runs and Reset it between them. A Checker is safe for concurrent use; the zero Checker caches
+// nothing and behaves like File and Directory.
+type Checker struct {
+	cache *common.StatCache
+}
+
+// NewChecker returns a Checker configured by opts
+func NewChecker(opts CheckerOptions) *Checker {
+	c := &Checker{}
+	if opts.CacheStats {
+		c.cache = common.NewStatCache(opts.MaxCachedStats)
+	}
+	return c
+}
+
+// File is checkfs.File through the Checker's stat cache
+func (c *Checker) File(path string, opts file.Options) error {
+	return file.FileContext(c.context(), path, opts)
+}
+
+// Directory is checkfs.Directory through the Checker's stat cache
+func (c *Checker) Directory(path string, opts directory.Options) error {
+	return directory.DirectoryContext(c.context(), path, opts)
+}
+
+// Reset drops every cached stat
+func (c *Checker) Reset() {
+	if c.cache != nil {
+		c.cache.Reset()
+	}
+}
+
+func (c *Checker) context() context.Context {
+	if c.cache == nil {
+		return context.Background()
+	}
+	return common.WithStatCache(context.Background(), c.cache)
+}
@@ -1,10 +1,16 @@
 package checkfs
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/andreimerlescu/checkfs/directory"
 	"github.com/andreimerlescu/checkfs/file"
 	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"testing/fstest"
 )
 
 func TestFile(t *testing.T) {
@@ -24,9 +30,182 @@ func TestFile(t *testing.T) {
 func TestDirectory(t *testing.T) {
 	dir := t.TempDir()
 
-	err := Directory(dir, directory.Options{})
-	if err == nil {
-		t.Errorf("Directory() should have thrown err but got %v", err)
+	if err := Directory(dir, directory.Options{}); err != nil {
+		t.Errorf("Directory() with zero Options error = %v, want nil", err)
+	}
+	if err := Directory(dir, directory.Options{MustNotExist: true}); !errors.Is(err, directory.ErrAlreadyExists) {
+		t.Errorf("Directory() with MustNotExist error = %v, want ErrAlreadyExists", err)
+	}
+	if err := Directory(filepath.Join(dir, "missing"), directory.Options{MustNotExist: true}); err != nil {
+		t.Errorf("Directory() of a missing directory with MustNotExist error = %v, want nil", err)
+	}
+}
+
+func TestFileAll(t *testing.T) {
+	dir := t.TempDir()
+	filePath := dir + "/file.txt"
+	if err := os.WriteFile(filePath, []byte("test"), 0644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	errs := FileAll(filePath, file.Options{RequireExt: ".csv", IsSize: 10})
+	if len(errs) != 2 {
+		t.Errorf("FileAll() = %v, want 2 errors", errs)
+	}
+}
+
+func TestDirectoryAll(t *testing.T) {
+	dir := t.TempDir()
+
+	errs := DirectoryAll(dir, directory.Options{Exists: true, RequirePrefix: "nope", RequireBaseDir: "/invalid"})
+	if len(errs) != 2 {
+		t.Errorf("DirectoryAll() = %v, want 2 errors", errs)
+	}
+}
+
+func TestStat(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(filePath, []byte("test"), 0644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	same := func(got os.FileInfo, path string) {
+		t.Helper()
+		want, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("os.Stat() error = %v", err)
+		}
+		if got == nil || !os.SameFile(got, want) || got.Size() != want.Size() || got.Mode() != want.Mode() || !got.ModTime().Equal(want.ModTime()) {
+			t.Errorf("info = %v, want the os.Stat of %s", got, path)
+		}
+	}
+
+	info, err := StatFile(filePath, file.Options{Exists: true, IsSize: 4})
+	if err != nil {
+		t.Fatalf("StatFile() error = %v", err)
+	}
+	same(info, filePath)
+	if info, err := StatFile(filePath, file.Options{IsSize: 5}); !errors.Is(err, file.ErrSizeMismatch) || info != nil {
+		t.Errorf("StatFile() = %v, %v, want nil and ErrSizeMismatch", info, err)
+	}
+	if info, err := StatFile(filepath.Join(dir, "missing.txt"), file.Options{}); err != nil || info != nil {
+		t.Errorf("StatFile() of an allowed missing file = %v, %v, want nil, nil", info, err)
+	}
+
+	info, err = StatDirectory(dir, directory.Options{Exists: true})
+	if err != nil {
+		t.Fatalf("StatDirectory() error = %v", err)
+	}
+	same(info, dir)
+	if info, err := StatDirectory(dir, directory.Options{Exists: true, RequirePrefix: "nope"}); !errors.Is(err, directory.ErrNameMismatch) || info != nil {
+		t.Errorf("StatDirectory() = %v, %v, want nil and ErrNameMismatch", info, err)
+	}
+}
+
+func TestFileInfoCheck(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(filePath, []byte("test"), 0644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatalf("os.Stat() error = %v", err)
+	}
+	if err := FileInfoCheck(filePath, info, file.Options{RequireExt: ".txt", IsSize: 4}); err != nil {
+		t.Errorf("FileInfoCheck() error = %v", err)
+	}
+	if err := FileInfoCheck(filePath, info, file.Options{IsSize: 5}); !errors.Is(err, file.ErrSizeMismatch) {
+		t.Errorf("FileInfoCheck() error = %v, want ErrSizeMismatch", err)
+	}
+}
+
+func TestCheckResult(t *testing.T) {
+	dir := t.TempDir()
+	filePath := dir + "/file.txt"
+	if err := os.WriteFile(filePath, []byte("test"), 0644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	t.Run("File violations", func(t *testing.T) {
+		result := CheckFileResult(filePath, file.Options{RequireExt: ".csv", IsSize: 10, RequirePrefix: "nope"})
+		want := []Violation{
+			{Code: CodeNameMismatch, Field: "RequireExt", Path: filePath},
+			{Code: CodeNameMismatch, Field: "RequirePrefix", Path: filePath},
+			{Code: CodeSizeMismatch, Field: "IsSize", Path: filePath},
+		}
+		if result.Passed {
+			t.Error("CheckFileResult().Passed = true, want false")
+		}
+		if len(result.Violations) != len(want) {
+			t.Fatalf("CheckFileResult().Violations = %+v, want %d", result.Violations, len(want))
+		}
+		for i, v := range result.Violations {
+			if v.Code != want[i].Code || v.Field != want[i].Field || v.Path != want[i].Path || v.Message == "" {
+				t.Errorf("Violations[%d] = %+v, want %+v with a message", i, v, want[i])
+			}
+		}
+	})
+
+	t.Run("Directory violations", func(t *testing.T) {
+		result := CheckDirectoryResult(dir, directory.Options{Exists: true, RequirePrefix: "nope", RequireBaseDir: "/invalid"})
+		var got []Code
+		for _, v := range result.Violations {
+			got = append(got, v.Code)
+		}
+		if result.Passed || len(got) != 2 || got[0] != CodeNameMismatch || got[1] != CodeBadBaseDir {
+			t.Errorf("CheckDirectoryResult() codes = %v, want [%s %s]", got, CodeNameMismatch, CodeBadBaseDir)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		result := CheckFileResult(dir+"/missing.txt", file.Options{Exists: true})
+		if len(result.Violations) != 1 || result.Violations[0].Code != CodeDoesNotExist || result.Violations[0].Field != "" {
+			t.Errorf("CheckFileResult() = %+v, want a single %s violation without a field", result, CodeDoesNotExist)
+		}
+	})
+
+	t.Run("Passed", func(t *testing.T) {
+		result := CheckFileResult(filePath, file.Options{Exists: true, IsSize: 4})
+		if !result.Passed || len(result.Violations) != 0 {
+			t.Errorf("CheckFileResult() = %+v, want passed", result)
+		}
+	})
+}
+
+func TestInspectFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(filePath, []byte("test"), 0644); err != nil {
+		t.Fatalf("Error writing file: %v", err)
+	}
+
+	report, err := InspectFile(filePath, file.Options{RequireExt: ".csv", IsSize: 4})
+	if err != nil {
+		t.Fatalf("InspectFile() error = %v", err)
+	}
+	want := []CheckResult{{Name: "RequireExt"}, {Name: "IsSize", Passed: true}}
+	if len(report.Checks) != len(want) {
+		t.Fatalf("InspectFile().Checks = %+v, want %+v", report.Checks, want)
+	}
+	for i, c := range report.Checks {
+		if c.Name != want[i].Name || c.Passed != want[i].Passed {
+			t.Errorf("Checks[%d] = %+v, want %+v", i, c, want[i])
+		}
+	}
+	if report.Passed() || len(report.Failed()) != 1 {
+		t.Errorf("InspectFile() Passed() = %v, Failed() = %+v, want one failure", report.Passed(), report.Failed())
+	}
+}
+
+func TestContext(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := FileContext(ctx, dir+"/file.txt", file.Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("FileContext() error = %v, want context.Canceled", err)
+	}
+	if err := DirectoryContext(ctx, dir, directory.Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("DirectoryContext() error = %v, want context.Canceled", err)
 	}
 }
 
@@ -50,3 +229,89 @@ func BenchmarkDirectory(b *testing.B) {
 		_ = Directory(dir, directory.Options{})
 	}
 }
+
+func TestChecker(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cached := NewChecker(CheckerOptions{CacheStats: true})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cached.File(path, file.Options{Exists: true, IsSize: 4}); err != nil {
+				t.Errorf("Checker.File() error = %v", err)
+			}
+			if err := cached.Directory(dir, directory.Options{Exists: true}); err != nil {
+				t.Errorf("Checker.Directory() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove test file: %v", err)
+	}
+	if err := cached.File(path, file.Options{Exists: true}); err != nil {
+		t.Errorf("Checker.File() on a removed file error = %v, want the stale cached stat to pass", err)
+	}
+	cached.Reset()
+	if err := cached.File(path, file.Options{Exists: true}); !errors.Is(err, file.ErrDoesNotExist) {
+		t.Errorf("Checker.File() after Reset error = %v, want file.ErrDoesNotExist", err)
+	}
+
+	var uncached Checker
+	if err := uncached.File(path, file.Options{Exists: true}); !errors.Is(err, file.ErrDoesNotExist) {
+		t.Errorf("zero Checker.File() error = %v, want file.ErrDoesNotExist", err)
+	}
+}
+
+func BenchmarkChecker(b *testing.B) {
+	dir := b.TempDir()
+	paths := make([]string, 1000)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("file-%04d.txt", i))
+		if err := os.WriteFile(paths[i], []byte("test"), 0644); err != nil {
+			b.Fatalf("Error writing file: %v", err)
+		}
+	}
+	opts := file.Options{Exists: true, RequireBaseDir: dir, IsSize: 4}
+
+	for _, bm := range []struct {
+		name    string
+		checker *Checker
+	}{
+		{"Uncached", NewChecker(CheckerOptions{})},
+		{"Cached", NewChecker(CheckerOptions{CacheStats: true})},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, path := range paths {
+					if err := bm.checker.File(path, opts); err != nil {
+						b.Fatalf("Checker.File() error = %v", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/logo.svg": {Data: []byte("<svg/>"), Mode: 0644},
+	}
+
+	if err := FileFS(fsys, "assets/logo.svg", file.Options{Exists: true, RequireExt: ".svg", IsSize: 6}); err != nil {
+		t.Errorf("FileFS() error = %v", err)
+	}
+	if err := DirectoryFS(fsys, "assets", directory.Options{Exists: true, RequirePrefix: "assets"}); err != nil {
+		t.Errorf("DirectoryFS() error = %v", err)
+	}
+	if err := FileFS(os.DirFS(t.TempDir()), "missing.txt", file.Options{Exists: true}); !errors.Is(err, file.ErrDoesNotExist) {
+		t.Errorf("FileFS() error = %v, want file.ErrDoesNotExist", err)
+	}
+}
@@ -0,0 +1,62 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccessMode is a set of the accesses Access asks about, combined with |, e.g. AccessRead|AccessWrite
+type AccessMode uint8
+
+const (
+	AccessExecute AccessMode = 1 << iota // AccessExecute is X_OK: execute a file or search a directory
+	AccessWrite                          // AccessWrite is W_OK
+	AccessRead                           // AccessRead is R_OK
+)
+
+// String returns the accesses in m as "rwx" letters, such as "rw" or "x"; the empty set is ""
+func (m AccessMode) String() string {
+	var b strings.Builder
+	for _, a := range accessLetters {
+		if m&a.mode != 0 {
+			b.WriteByte(a.letter)
+		}
+	}
+	if rest := m &^ (AccessRead | AccessWrite | AccessExecute); rest != 0 {
+		fmt.Fprintf(&b, "+AccessMode(%d)", uint8(rest))
+	}
+	return b.String()
+}
+
+// accessLetters pairs each AccessMode with its letter, in "rwx" order
+var accessLetters = []struct {
+	mode   AccessMode
+	letter byte
+}{{AccessRead, 'r'}, {AccessWrite, 'w'}, {AccessExecute, 'x'}}
+
+// MarshalText encodes m as its String letters, such as "rx"
+func (m AccessMode) MarshalText() ([]byte, error) {
+	if m&^(AccessRead|AccessWrite|AccessExecute) != 0 {
+		return nil, fmt.Errorf("%w: unknown AccessMode %d", ErrInvalidOptions, uint8(m))
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes any combination of the letters r, w and x, such as "rw"; an empty string is no access check
+func (m *AccessMode) UnmarshalText(text []byte) error {
+	var mode AccessMode
+	for _, c := range text {
+		found := false
+		for _, a := range accessLetters {
+			if c == a.letter {
+				mode |= a.mode
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: unknown AccessMode %q, want letters from \"rwx\"", ErrInvalidOptions, text)
+		}
+	}
+	*m = mode
+	return nil
+}
@@ -0,0 +1,39 @@
+//go:build !unix
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Access reports whether the current process may access path in every way mode asks for. Without access(2), such as
+// on Windows, it approximates by opening path: AccessRead and AccessExecute open it for reading, AccessWrite for writing
+// without truncating. A denied open is false with a nil error; any other failure, such as a missing path, is an error.
+func Access(path string, mode AccessMode) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		return false, fmt.Errorf("failed to check %s access to %s: %w", mode, path, err)
+	}
+	if mode&(AccessRead|AccessExecute) != 0 {
+		if ok, err := canOpen(path, os.O_RDONLY); !ok || err != nil {
+			return ok, err
+		}
+	}
+	if mode&AccessWrite != 0 {
+		return canOpen(path, os.O_WRONLY)
+	}
+	return true, nil
+}
+
+// canOpen opens and closes path with flag, reporting a permission error as false
+func canOpen(path string, flag int) (bool, error) {
+	f, err := os.OpenFile(path, flag, 0)
+	if errors.Is(err, os.ErrPermission) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return true, f.Close()
+}
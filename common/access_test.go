@@ -0,0 +1,86 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAccess(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data.txt")
+	if err := os.WriteFile(data, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	script := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to create test script: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		mode     AccessMode
+		want     bool
+		unixOnly bool // execute is approximated by opening for reading where access(2) is missing
+	}{
+		{"Readable", data, AccessRead, true, false},
+		{"Writable", data, AccessWrite, true, false},
+		{"Readable and writable", data, AccessRead | AccessWrite, true, false},
+		{"Not executable", data, AccessExecute, false, true},
+		{"Not readable and executable", data, AccessRead | AccessExecute, false, true},
+		{"Executable", script, AccessExecute, true, false},
+		{"Directory searchable", dir, AccessRead | AccessExecute, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unixOnly && runtime.GOOS == "windows" {
+				t.Skip("execute access is approximated on Windows")
+			}
+			got, err := Access(tt.path, tt.mode)
+			if err != nil || got != tt.want {
+				t.Errorf("Access(%s, %s) = %v, %v, want %v", filepath.Base(tt.path), tt.mode, got, err, tt.want)
+			}
+		})
+	}
+
+	if runtime.GOOS != "windows" && os.Geteuid() != 0 {
+		readOnly := filepath.Join(dir, "readonly.txt")
+		if err := os.WriteFile(readOnly, []byte("x"), 0444); err != nil {
+			t.Fatalf("Failed to create read-only file: %v", err)
+		}
+		if got, err := Access(readOnly, AccessWrite); err != nil || got {
+			t.Errorf("Access(readonly.txt, w) = %v, %v, want false", got, err)
+		}
+	}
+
+	if _, err := Access(filepath.Join(dir, "missing"), AccessRead); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Access() of a missing path error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestAccessModeText(t *testing.T) {
+	for _, mode := range []AccessMode{0, AccessRead, AccessWrite | AccessExecute, AccessRead | AccessWrite | AccessExecute} {
+		data, err := json.Marshal(mode)
+		if err != nil {
+			t.Fatalf("json.Marshal(%s) error = %v", mode, err)
+		}
+		var got AccessMode
+		if err := json.Unmarshal(data, &got); err != nil || got != mode {
+			t.Errorf("round trip of %s = %s, %v, want %s", data, got, err, mode)
+		}
+	}
+	if got := (AccessRead | AccessExecute).String(); got != "rx" {
+		t.Errorf("String() = %q, want \"rx\"", got)
+	}
+	var mode AccessMode
+	if err := json.Unmarshal([]byte(`"rwz"`), &mode); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("json.Unmarshal(\"rwz\") error = %v, want ErrInvalidOptions", err)
+	}
+	if _, err := json.Marshal(AccessMode(1 << 5)); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("json.Marshal(AccessMode(32)) error = %v, want ErrInvalidOptions", err)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+)
+
+// Access reports whether the current process may access path in every way mode asks for, using access(2) so that the
+// answer accounts for the process's user and groups (the real IDs, as access(2) does) rather than just the mode bits.
+// A denied access, including writing on a read-only filesystem, is false with a nil error; any other failure, such as
+// a missing path, is an error.
+func Access(path string, mode AccessMode) (bool, error) {
+	err := syscall.Access(path, uint32(mode))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, syscall.EACCES), errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EROFS), errors.Is(err, syscall.ETXTBSY):
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check %s access to %s: %w", mode, path, err)
+}
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// Checksum is a single entry of a coreutils-format checksums file (sha256sum, shasum -a 256)
+type Checksum struct {
+	Digest string // Digest is the lowercase hex-encoded digest
+	Path   string // Path is the file name exactly as written in the manifest
+	Binary bool   // Binary is true when the entry was written in binary mode ("<hex> *<path>")
+}
+
+// ParseChecksums reads a coreutils-format SHA-256 checksums manifest where every line is "<hex>  <path>" or
+// "<hex> *<path>". Blank lines are ignored; any other malformed line, including a digest that is not 64 hex characters
+// such as a SHA-1 or truncated one, returns an error naming the line number
+func ParseChecksums(r io.Reader) ([]Checksum, error) {
+	var sums []Checksum
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		digest, rest, found := strings.Cut(text, " ")
+		if !found || len(rest) < 2 || (rest[0] != ' ' && rest[0] != '*') {
+			return nil, fmt.Errorf("malformed checksum on line %d: %q", line, text)
+		}
+		if _, err := hex.DecodeString(digest); err != nil || len(digest) != sha256.Size*2 {
+			return nil, fmt.Errorf("malformed digest on line %d: %q", line, digest)
+		}
+		sums = append(sums, Checksum{
+			Digest: strings.ToLower(digest),
+			Path:   rest[1:],
+			Binary: rest[0] == '*',
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read checksums: %w", err)
+	}
+	return sums, nil
+}
+
+// SHA256File streams the file at path through sha256 and returns the lowercase hex-encoded digest
+func SHA256File(path string) (string, error) {
+	return SHA256FileContext(context.Background(), path)
+}
+
+// SHA256FileContext is SHA256File that stops reading with ctx.Err() once ctx is done
+func SHA256FileContext(ctx context.Context, path string) (string, error) {
+	return SHA256FS(ctx, nil, path)
+}
+
+// SHA256FS is SHA256FileContext reading name from fsys, or from the OS filesystem when fsys is nil
+func SHA256FS(ctx context.Context, fsys fs.FS, name string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	f, err := OpenFS(fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, ContextReader(ctx, f)); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
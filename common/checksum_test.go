@@ -0,0 +1,62 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseChecksums(t *testing.T) {
+	digest := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	tests := []struct {
+		name    string
+		input   string
+		want    []Checksum
+		wantErr bool
+	}{
+		{"Text mode entry", digest + "  file.txt\n", []Checksum{{Digest: digest, Path: "file.txt"}}, false},
+		{"Binary mode entry", digest + " *file.bin\n", []Checksum{{Digest: digest, Path: "file.bin", Binary: true}}, false},
+		{"Uppercase digest", strings.ToUpper(digest) + "  a b.txt\r\n", []Checksum{{Digest: digest, Path: "a b.txt"}}, false},
+		{"Blank lines", "\n" + digest + "  x\n\n", []Checksum{{Digest: digest, Path: "x"}}, false},
+		{"Missing path", digest + "\n", nil, true},
+		{"Bad separator", digest + " file.txt\n", nil, true},
+		{"Non hex digest", "zzzz  file.txt\n", nil, true},
+		{"SHA-1 digest", "da39a3ee5e6b4b0d3255bfef95601890afd80709  file.txt\n", nil, true},
+		{"Truncated digest", digest[:32] + "  file.txt\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChecksums(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChecksums() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseChecksums() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseChecksums()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSHA256File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "abc.txt")
+	if err := os.WriteFile(file, []byte("abc"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	got, err := SHA256File(file)
+	if err != nil {
+		t.Fatalf("SHA256File() error = %v", err)
+	}
+	if want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != want {
+		t.Errorf("SHA256File() = %s, want %s", got, want)
+	}
+	if _, err := SHA256File(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("SHA256File() should fail on a missing file")
+	}
+}
@@ -1,13 +1,27 @@
 package common
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 )
 
-// IsPathInBase checks if a path is within the base directory
+// IsPathInBase checks if a path is within the base directory, ignoring case on Windows where paths are
+// case-insensitive, see IsPathInBaseCase
 func IsPathInBase(path, baseDir string) (bool, error) {
+	return IsPathInBaseCase(path, baseDir, caseInsensitivePaths)
+}
+
+// IsPathInBaseCase is IsPathInBase with the case sensitivity chosen by the caller, for example caseInsensitive on macOS
+// where the default APFS volume ignores case but others may not
+func IsPathInBaseCase(path, baseDir string, caseInsensitive bool) (bool, error) {
 	if path == "" {
 		return false, fmt.Errorf("path cannot be empty")
 	}
@@ -23,6 +37,9 @@ func IsPathInBase(path, baseDir string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to get absolute path of base directory %s: %w", baseDir, err)
 	}
+	if caseInsensitive {
+		absPath, absBaseDir = strings.ToLower(absPath), strings.ToLower(absBaseDir)
+	}
 	rel, err := filepath.Rel(absBaseDir, absPath)
 	if err != nil {
 		return false, fmt.Errorf("failed to get relative path: %w", err)
@@ -30,17 +47,354 @@ func IsPathInBase(path, baseDir string) (bool, error) {
 	return !RelStartsWithParent(rel), nil
 }
 
+// IsPathInBaseResolved is IsPathInBase that also follows symlinks: the deepest existing ancestor of path's parent is
+// resolved and the components below it rejoined, and the result must still be within baseDir once that is resolved
+// the same way, so a symlink inside baseDir cannot lead path outside of it however much of path is still missing.
+// path itself may not exist yet and is not followed. An ancestor that cannot be resolved, such as a dangling symlink,
+// is an error rather than a pass.
+func IsPathInBaseResolved(path, baseDir string) (bool, error) {
+	inBase, err := IsPathInBase(path, baseDir)
+	if err != nil || !inBase {
+		return inBase, err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+	parent, err := resolveExisting(filepath.Dir(absPath))
+	if err != nil {
+		return false, err
+	}
+	resolvedBase, err := resolveExisting(baseDir)
+	if err != nil {
+		return false, err
+	}
+	return IsPathInBase(filepath.Join(parent, filepath.Base(absPath)), resolvedBase)
+}
+
+// resolveExisting resolves the symlinks in the deepest ancestor of path that exists and rejoins the missing components
+// below it as written. A missing component that is itself a symlink dangles somewhere that cannot be checked, and any
+// error other than fs.ErrNotExist is returned.
+func resolveExisting(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+	var missing []string
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			for i := len(missing) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, missing[i])
+			}
+			return resolved, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+		}
+		if info, lerr := os.Lstat(dir); lerr == nil && info.Mode()&fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("failed to resolve %s: dangling symlink", dir)
+		}
+		if filepath.Dir(dir) == dir {
+			return abs, nil
+		}
+		missing = append(missing, filepath.Base(dir))
+	}
+}
+
+// ResolvesIntoBase reports whether path is within baseDir either as written or once the symlinks in both are resolved,
+// so that for a denylist a link from elsewhere cannot hide a path inside baseDir. A path that does not exist yet is
+// resolved through its parent directory, and a baseDir that does not exist is compared as written.
+func ResolvesIntoBase(path, baseDir string) (bool, error) {
+	inBase, err := IsPathInBase(path, baseDir)
+	if err != nil || inBase {
+		return inBase, err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		var parent string
+		if parent, err = filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+			resolved = filepath.Join(parent, filepath.Base(path))
+		}
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	resolvedBase, err := filepath.EvalSymlinks(baseDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		resolvedBase = baseDir
+	} else if err != nil {
+		return false, fmt.Errorf("failed to resolve base directory %s: %w", baseDir, err)
+	}
+	return IsPathInBase(resolved, resolvedBase)
+}
+
+// IsSamePath reports whether a and b name the same path once both are made absolute and their symlinks resolved,
+// ignoring case on Windows as IsPathInBase does. A path that does not exist yet is resolved through its parent
+// directory, or compared lexically when that is missing too.
+func IsSamePath(a, b string) (bool, error) {
+	if a == "" || b == "" {
+		return false, fmt.Errorf("path cannot be empty")
+	}
+	resolvedA, err := canonicalPath(a)
+	if err != nil {
+		return false, err
+	}
+	resolvedB, err := canonicalPath(b)
+	if err != nil {
+		return false, err
+	}
+	if caseInsensitivePaths {
+		return strings.EqualFold(resolvedA, resolvedB), nil
+	}
+	return resolvedA == resolvedB, nil
+}
+
+// canonicalPath returns path made absolute with its symlinks resolved as far as it exists
+func canonicalPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if errors.Is(err, fs.ErrNotExist) {
+		var parent string
+		if parent, err = filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+			resolved = filepath.Join(parent, filepath.Base(abs))
+		}
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return abs, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return resolved, nil
+}
+
+// IsFilesystemRoot reports whether path is the root of a filesystem or volume, such as / or C:\, which no helper
+// should ever remove
+func IsFilesystemRoot(path string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return filepath.Dir(abs) == abs
+}
+
+// IsStrictlyInBaseResolved is IsPathInBaseResolved that also rejects baseDir itself, or any path IsSamePath as it, for
+// operations such as removal that must stay below baseDir
+func IsStrictlyInBaseResolved(path, baseDir string) (bool, error) {
+	inBase, err := IsPathInBaseResolved(path, baseDir)
+	if err != nil || !inBase {
+		return inBase, err
+	}
+	same, err := IsSamePath(path, baseDir)
+	return !same, err
+}
+
 // RelStartsWithParent checks if a relative path escapes the base directory
 func RelStartsWithParent(rel string) bool {
 	rel = filepath.Clean(rel)
 	return strings.HasPrefix(rel, "..") && (len(rel) == 2 || strings.HasPrefix(rel[2:], string(filepath.Separator)))
 }
 
+// SanitizeOptions configures SanitizePathWith
+type SanitizeOptions struct {
+	// Absolute resolves the cleaned path against the working directory, which on Windows also resolves a
+	// drive-relative path such as C:file against the working directory of that drive
+	Absolute bool
+}
+
 // SanitizePath removes redundant separators and resolves relative components in a path
 func SanitizePath(path string) (string, error) {
-	cleaned := filepath.Clean(path)
+	return SanitizePathWith(path, SanitizeOptions{})
+}
+
+// SanitizePathWith is SanitizePath with options. On Windows both also strip the extended-length \\?\ prefix, so
+// \\?\C:\dir cleans to C:\dir and \\?\UNC\server\share to \\server\share, and turn forward slashes into
+// backslashes.
+func SanitizePathWith(path string, opts SanitizeOptions) (string, error) {
+	cleaned := filepath.Clean(stripExtendedPrefix(path))
 	if cleaned == "" {
 		return "", fmt.Errorf("path cannot be empty after cleaning")
 	}
+	if opts.Absolute {
+		abs, err := filepath.Abs(cleaned)
+		if err != nil {
+			return "", fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+		}
+		return abs, nil
+	}
 	return cleaned, nil
 }
+
+// SymlinkComponents lstats every component of the absolute form of path, from the root down to the leaf, and counts
+// how many of them are symbolic links. Links are not followed, so a single link pointing at another link counts once;
+// ".." is resolved lexically by filepath.Abs before any component is inspected.
+func SymlinkComponents(path string) (int, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+	volume := filepath.VolumeName(abs)
+	current := volume + string(filepath.Separator)
+	count := 0
+	for _, part := range strings.Split(abs[len(volume):], string(filepath.Separator)) {
+		if part == "" {
+			continue
+		}
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if err != nil {
+			return 0, fmt.Errorf("failed to lstat %s: %w", current, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FirstSymlink lstats the components of the absolute form of path, from the root down to the leaf, and returns the
+// first one that is a symbolic link, or "" when none is. It stops without error at the first component that does not
+// exist, since nothing below it can be a link yet; ".." is resolved lexically by filepath.Abs as in SymlinkComponents.
+func FirstSymlink(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+	volume := filepath.VolumeName(abs)
+	current := volume + string(filepath.Separator)
+	for _, part := range strings.Split(abs[len(volume):], string(filepath.Separator)) {
+		if part == "" {
+			continue
+		}
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to lstat %s: %w", current, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return current, nil
+		}
+	}
+	return "", nil
+}
+
+// HasSymlinkComponent reports whether any ancestor of path is a symbolic link and returns the first one, walking from
+// the root down as FirstSymlink does. The leaf itself is not considered, so this answers whether creating path would
+// go through a link an attacker could have swapped in for a parent directory.
+func HasSymlinkComponent(path string) (bool, string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get absolute path of %s: %w", path, err)
+	}
+	link, err := FirstSymlink(filepath.Dir(abs))
+	if err != nil {
+		return false, "", err
+	}
+	return link != "", link, nil
+}
+
+// IsBrokenSymlink reports whether path is a symbolic link whose target does not resolve. A path that does not exist
+// or is not a symlink returns false with a nil error.
+func IsBrokenSymlink(path string) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return false, nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return false, nil
+}
+
+// SymlinkTarget returns where the symlink at path points, with a relative target resolved against the directory of
+// path. Only the link itself is read, so the result may point at another link or at nothing.
+func SymlinkTarget(path string) (string, error) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read link %s: %w", path, err)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return target, nil
+}
+
+// GetModificationTime retrieves the modification time (mtime) of a file or directory, following symlinks. Unlike the
+// creation and access times it is portable, being os.FileInfo.ModTime, and is here to round out those helpers.
+func GetModificationTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return info.ModTime(), nil
+}
+
+// IsFDExhausted reports whether err was caused by the process (EMFILE) or the system (ENFILE) running out of file
+// descriptors
+func IsFDExhausted(err error) bool {
+	return errors.Is(err, syscall.EMFILE) || errors.Is(err, syscall.ENFILE)
+}
+
+// ContextReader wraps r so every Read fails with ctx.Err() once ctx is done, letting long streams be cancelled between
+// reads
+func ContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// ModeDiff compares the permission bits of actual against expected and returns the bits expected has that actual lacks
+// and the bits actual has beyond expected. Everything but the nine permission bits is ignored.
+func ModeDiff(actual, expected os.FileMode) (missing, extra os.FileMode) {
+	actual, expected = actual.Perm(), expected.Perm()
+	return expected &^ actual, actual &^ expected
+}
+
+// ValidatePermissions rejects permission options that no mode can satisfy: readOnly with requireWrite, a more
+// (minimum) mode with bits the less (maximum) mode lacks, readOnly with a minimum holding write bits, and requireWrite
+// with a maximum lacking the owner write bit. A zero more or less is unset.
+func ValidatePermissions(readOnly, requireWrite bool, more, less os.FileMode) error {
+	more, less = more.Perm(), less.Perm()
+	if readOnly && requireWrite {
+		return fmt.Errorf("%w: ReadOnly and RequireWrite are mutually exclusive", ErrInvalidOptions)
+	}
+	if more != 0 && less != 0 && more&^less != 0 {
+		return fmt.Errorf("%w: MorePermissiveThan %#o has bits LessPermissiveThan %#o forbids", ErrInvalidOptions, more, less)
+	}
+	if readOnly && more&0222 != 0 {
+		return fmt.Errorf("%w: ReadOnly forbids the write bits MorePermissiveThan %#o requires", ErrInvalidOptions, more)
+	}
+	if requireWrite && less != 0 && less&0200 == 0 {
+		return fmt.Errorf("%w: RequireWrite needs the owner write bit LessPermissiveThan %#o forbids", ErrInvalidOptions, less)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+//go:build freebsd || openbsd
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetCreationTime(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	path := filepath.Join(t.TempDir(), "birth.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	after := time.Now().Add(time.Second)
+
+	created, err := GetCreationTime(path)
+	if err != nil {
+		t.Fatalf("GetCreationTime() error = %v", err)
+	}
+	if created.Unix() <= 0 {
+		t.Skipf("filesystem backing %s reports neither birth nor change time", path)
+	}
+	if created.Before(before) || created.After(after) {
+		t.Errorf("GetCreationTime() = %v, want between %v and %v", created, before, after)
+	}
+}
+
+func TestGetOwnerAndGroup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owner.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	uid, gid, err := GetOwnerAndGroup(path)
+	if err != nil {
+		t.Fatalf("GetOwnerAndGroup() error = %v", err)
+	}
+	if want := strconv.Itoa(os.Getuid()); uid != want {
+		t.Errorf("GetOwnerAndGroup() uid = %s, want %s", uid, want)
+	}
+	if _, err := strconv.ParseUint(gid, 10, 32); err != nil {
+		t.Errorf("GetOwnerAndGroup() gid = %q is not a number: %v", gid, err)
+	}
+}
@@ -9,6 +9,10 @@ import (
 	"time"
 )
 
+// caseInsensitivePaths is the default case sensitivity of IsPathInBase; macOS volumes may be either, so it
+// assumes case-sensitive and callers can use IsPathInBaseCase
+const caseInsensitivePaths = false
+
 // HasPermissions checks if a file or directory has at least the specified permissions
 func HasPermissions(path string, perms os.FileMode) (bool, error) {
 	info, err := os.Stat(path)
@@ -19,14 +23,38 @@ func HasPermissions(path string, perms os.FileMode) (bool, error) {
 	return actualPerms&perms == perms, nil
 }
 
+// HasPermissionsL is HasPermissions for the symlink itself rather than its target
+func HasPermissionsL(path string, perms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	actualPerms := info.Mode().Perm()
+	return actualPerms&perms == perms, nil
+}
+
 // IsMorePermissiveThan checks if a file or directory’s permissions are at least as permissive as the given mode
 func IsMorePermissiveThan(path string, minPerms os.FileMode) (bool, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return false, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return IsMorePermissiveThanInfo(info, minPerms), nil
+}
+
+// IsMorePermissiveThanL is IsMorePermissiveThan for the symlink itself rather than its target
+func IsMorePermissiveThanL(path string, minPerms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	return IsMorePermissiveThanInfo(info, minPerms), nil
+}
+
+// IsMorePermissiveThanInfo is IsMorePermissiveThan for an os.FileInfo the caller already has, avoiding another stat
+func IsMorePermissiveThanInfo(info os.FileInfo, minPerms os.FileMode) bool {
 	perms := info.Mode().Perm()
-	return perms&minPerms == minPerms, nil
+	return perms&minPerms == minPerms
 }
 
 // IsLessPermissiveThan checks if a file or directory’s permissions are no more permissive than the given mode
@@ -35,8 +63,22 @@ func IsLessPermissiveThan(path string, maxPerms os.FileMode) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return IsLessPermissiveThanInfo(info, maxPerms), nil
+}
+
+// IsLessPermissiveThanL is IsLessPermissiveThan for the symlink itself rather than its target
+func IsLessPermissiveThanL(path string, maxPerms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	return IsLessPermissiveThanInfo(info, maxPerms), nil
+}
+
+// IsLessPermissiveThanInfo is IsLessPermissiveThan for an os.FileInfo the caller already has, avoiding another stat
+func IsLessPermissiveThanInfo(info os.FileInfo, maxPerms os.FileMode) bool {
 	perms := info.Mode().Perm()
-	return perms&^maxPerms == 0, nil
+	return perms&^maxPerms == 0
 }
 
 // GetOwnerAndGroup retrieves the owner UID and group GID of a file or directory on Darwin
@@ -45,9 +87,14 @@ func GetOwnerAndGroup(path string) (uid, gid string, err error) {
 	if err != nil {
 		return "", "", fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return GetOwnerAndGroupInfo(info)
+}
+
+// GetOwnerAndGroupInfo is GetOwnerAndGroup for an os.FileInfo the caller already has, avoiding another stat
+func GetOwnerAndGroupInfo(info os.FileInfo) (uid, gid string, err error) {
 	stat, ok := info.Sys().(*syscall.Stat_t)
 	if !ok {
-		return "", "", fmt.Errorf("unable to get detailed stats for %s", path)
+		return "", "", fmt.Errorf("unable to get detailed stats for %s", info.Name())
 	}
 	return fmt.Sprint(stat.Uid), fmt.Sprint(stat.Gid), nil
 }
@@ -58,9 +105,113 @@ func GetCreationTime(path string) (time.Time, error) {
 	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return GetCreationTimeInfo(info)
+}
+
+// GetCreationTimeInfo is GetCreationTime for an os.FileInfo the caller already has, avoiding another stat
+func GetCreationTimeInfo(info os.FileInfo) (time.Time, error) {
 	stat, ok := info.Sys().(*syscall.Stat_t)
 	if !ok {
-		return time.Time{}, fmt.Errorf("unable to get detailed stats for %s", path)
+		return time.Time{}, fmt.Errorf("unable to get detailed stats for %s", info.Name())
 	}
 	return time.Unix(stat.Birthtimespec.Sec, stat.Birthtimespec.Nsec), nil
 }
+
+// GetAccessTime retrieves the last access time (atime) of a file or directory on Darwin; it does not move on filesystems mounted
+// noatime.
+func GetAccessTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetAccessTimeInfo(info)
+}
+
+// GetAccessTimeInfo is GetAccessTime for an os.FileInfo the caller already has, avoiding another stat
+func GetAccessTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: no stat data for %s", ErrAccessTimeUnsupported, info.Name())
+	}
+	return time.Unix(stat.Atimespec.Sec, stat.Atimespec.Nsec), nil
+}
+
+// mntNoatime is MNT_NOATIME from <sys/mount.h>, which the syscall package does not define on Darwin
+const mntNoatime = 0x10000000
+
+// AccessTimeDisabled reports whether the filesystem holding path is mounted noatime, so its access times never move
+// and GetAccessTime returns whatever was last recorded
+func AccessTimeDisabled(path string) (bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	return st.Flags&mntNoatime != 0, nil
+}
+
+// GetBirthAndChangeTime retrieves the birth time and the inode change time of a file or directory on Darwin
+func GetBirthAndChangeTime(path string) (birth, change time.Time, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetBirthAndChangeTimeInfo(info)
+}
+
+// GetBirthAndChangeTimeInfo is GetBirthAndChangeTime for an os.FileInfo the caller already has, avoiding another stat
+func GetBirthAndChangeTimeInfo(info os.FileInfo) (birth, change time.Time, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	birth = time.Unix(stat.Birthtimespec.Sec, stat.Birthtimespec.Nsec)
+	change = time.Unix(stat.Ctimespec.Sec, stat.Ctimespec.Nsec)
+	return birth, change, nil
+}
+
+// LinkCount returns the number of hard links to the file at path
+func LinkCount(path string) (uint64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return LinkCountInfo(info)
+}
+
+// LinkCountInfo is LinkCount for an os.FileInfo the caller already has, avoiding another stat
+func LinkCountInfo(info os.FileInfo) (uint64, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return uint64(stat.Nlink), nil
+}
+
+// FileIdentity returns the device and inode numbers of the file at path, which together identify it for as long as it
+// exists: a file replaced at the same path, even by a rename, gets a different pair
+func FileIdentity(path string) (dev, ino uint64, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return FileIdentityInfo(info)
+}
+
+// FileIdentityInfo is FileIdentity for an os.FileInfo the caller already has, avoiding another stat
+func FileIdentityInfo(info os.FileInfo) (dev, ino uint64, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return uint64(stat.Dev), uint64(stat.Ino), nil
+}
+
+// AvailableBytes returns the bytes an unprivileged user may still write to the filesystem holding path, using
+// statfs(2); the blocks reserved for root are not counted
+func AvailableBytes(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
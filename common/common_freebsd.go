@@ -0,0 +1,122 @@
+//go:build freebsd
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// GetOwnerAndGroup retrieves the owner UID and group GID of a file or directory on FreeBSD
+func GetOwnerAndGroup(path string) (uid, gid string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetOwnerAndGroupInfo(info)
+}
+
+// GetOwnerAndGroupInfo is GetOwnerAndGroup for an os.FileInfo the caller already has, avoiding another stat
+func GetOwnerAndGroupInfo(info os.FileInfo) (uid, gid string, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return fmt.Sprint(stat.Uid), fmt.Sprint(stat.Gid), nil
+}
+
+// GetCreationTime retrieves the creation time of a file or directory on FreeBSD
+//
+// UFS2 and ZFS record a birth time; filesystems that do not (e.g. msdosfs, older UFS1) report tv_sec as -1 or 0, in
+// which case the inode change time is returned instead.
+func GetCreationTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetCreationTimeInfo(info)
+}
+
+// GetCreationTimeInfo is GetCreationTime for an os.FileInfo the caller already has, avoiding another stat
+func GetCreationTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	if birth := stat.Birthtimespec; int64(birth.Sec) > 0 {
+		return time.Unix(int64(birth.Sec), int64(birth.Nsec)), nil
+	}
+	return time.Unix(int64(stat.Ctimespec.Sec), int64(stat.Ctimespec.Nsec)), nil
+}
+
+// GetAccessTime retrieves the last access time (atime) of a file or directory on FreeBSD; it does not move on filesystems mounted
+// noatime.
+func GetAccessTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetAccessTimeInfo(info)
+}
+
+// GetAccessTimeInfo is GetAccessTime for an os.FileInfo the caller already has, avoiding another stat
+func GetAccessTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: no stat data for %s", ErrAccessTimeUnsupported, info.Name())
+	}
+	return time.Unix(int64(stat.Atimespec.Sec), int64(stat.Atimespec.Nsec)), nil
+}
+
+// mntNoatime is MNT_NOATIME from <sys/mount.h>, which the syscall package does not define on FreeBSD
+const mntNoatime = 0x10000000
+
+// AccessTimeDisabled reports whether the filesystem holding path is mounted noatime, so its access times never move
+// and GetAccessTime returns whatever was last recorded
+func AccessTimeDisabled(path string) (bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	return st.Flags&mntNoatime != 0, nil
+}
+
+// GetBirthAndChangeTime retrieves the birth time and the inode change time of a file or directory on FreeBSD, returning
+// ErrBirthTimeUnsupported when the filesystem does not record a birth time
+func GetBirthAndChangeTime(path string) (birth, change time.Time, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetBirthAndChangeTimeInfo(info)
+}
+
+// GetBirthAndChangeTimeInfo is GetBirthAndChangeTime for an os.FileInfo the caller already has, avoiding another stat
+func GetBirthAndChangeTimeInfo(info os.FileInfo) (birth, change time.Time, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	if int64(stat.Birthtimespec.Sec) <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w on the filesystem holding %s", ErrBirthTimeUnsupported, info.Name())
+	}
+	birth = time.Unix(int64(stat.Birthtimespec.Sec), int64(stat.Birthtimespec.Nsec))
+	change = time.Unix(int64(stat.Ctimespec.Sec), int64(stat.Ctimespec.Nsec))
+	return birth, change, nil
+}
+
+// AvailableBytes returns the bytes an unprivileged user may still write to the filesystem holding path, using
+// statfs(2); the blocks reserved for root are not counted. Bavail goes negative once root has eaten into its
+// reserve, which is reported as 0.
+func AvailableBytes(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	if st.Bavail < 0 {
+		return 0, nil
+	}
+	return uint64(st.Bavail) * st.Bsize, nil
+}
@@ -0,0 +1,105 @@
+//go:build linux
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// GetOwnerAndGroup retrieves the owner UID and group GID of a file or directory on Linux
+func GetOwnerAndGroup(path string) (uid, gid string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetOwnerAndGroupInfo(info)
+}
+
+// GetOwnerAndGroupInfo is GetOwnerAndGroup for an os.FileInfo the caller already has, avoiding another stat
+func GetOwnerAndGroupInfo(info os.FileInfo) (uid, gid string, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return fmt.Sprint(stat.Uid), fmt.Sprint(stat.Gid), nil
+}
+
+// GetCreationTime retrieves the creation time of a file or directory on Linux
+//
+// Linux exposes no birth time through stat(2), so the inode change time (Ctim) is used as a proxy. It matches the
+// creation time until the inode is modified (chmod, chown, rename, write), after which it moves forward. Ctim.Sec and
+// Ctim.Nsec are int32 on 386 and arm but int64 on amd64 and arm64, so both are widened before calling time.Unix.
+func GetCreationTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetCreationTimeInfo(info)
+}
+
+// GetCreationTimeInfo is GetCreationTime for an os.FileInfo the caller already has, avoiding another stat
+func GetCreationTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec)), nil
+}
+
+// GetAccessTime retrieves the last access time (atime) of a file or directory on Linux. How often it moves depends on
+// the mount: relatime, the Linux default, only updates it when it is older than the modification time or a day old,
+// and noatime never does.
+func GetAccessTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetAccessTimeInfo(info)
+}
+
+// GetAccessTimeInfo is GetAccessTime for an os.FileInfo the caller already has, avoiding another stat
+func GetAccessTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: no stat data for %s", ErrAccessTimeUnsupported, info.Name())
+	}
+	return time.Unix(int64(stat.Atim.Sec), int64(stat.Atim.Nsec)), nil
+}
+
+// AccessTimeDisabled reports whether the filesystem holding path is mounted noatime, so its access times never move
+// and GetAccessTime returns whatever was last recorded. relatime and strictatime mounts report false.
+func AccessTimeDisabled(path string) (bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	return st.Flags&syscall.MS_NOATIME != 0, nil
+}
+
+// GetBirthAndChangeTime always returns ErrBirthTimeUnsupported on Linux: the birth time is only exposed through
+// statx(2), which the syscall package does not wrap
+func GetBirthAndChangeTime(path string) (birth, change time.Time, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetBirthAndChangeTimeInfo(info)
+}
+
+// GetBirthAndChangeTimeInfo is GetBirthAndChangeTime for an os.FileInfo the caller already has, avoiding another stat
+func GetBirthAndChangeTimeInfo(info os.FileInfo) (birth, change time.Time, err error) {
+	return time.Time{}, time.Time{}, fmt.Errorf("%w on linux: %s", ErrBirthTimeUnsupported, info.Name())
+}
+
+// AvailableBytes returns the bytes an unprivileged user may still write to the filesystem holding path, using
+// statfs(2); the blocks reserved for root are not counted
+func AvailableBytes(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	return st.Bavail * uint64(st.Bsize), nil
+}
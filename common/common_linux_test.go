@@ -0,0 +1,51 @@
+//go:build linux
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetCreationTime(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	path := filepath.Join(t.TempDir(), "ctime.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	after := time.Now().Add(time.Second)
+
+	created, err := GetCreationTime(path)
+	if err != nil {
+		t.Fatalf("GetCreationTime() error = %v", err)
+	}
+	// compare as time.Time rather than raw seconds so the test holds whether Ctim is int32 or int64
+	if created.Before(before) || created.After(after) {
+		t.Errorf("GetCreationTime() = %v, want between %v and %v", created, before, after)
+	}
+
+	if _, err := GetCreationTime(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("GetCreationTime() on missing path returned nil error")
+	}
+}
+
+func TestGetOwnerAndGroup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owner.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	uid, gid, err := GetOwnerAndGroup(path)
+	if err != nil {
+		t.Fatalf("GetOwnerAndGroup() error = %v", err)
+	}
+	if want := strconv.Itoa(os.Getuid()); uid != want {
+		t.Errorf("GetOwnerAndGroup() uid = %s, want %s", uid, want)
+	}
+	if _, err := strconv.ParseUint(gid, 10, 32); err != nil {
+		t.Errorf("GetOwnerAndGroup() gid = %q is not a number: %v", gid, err)
+	}
+}
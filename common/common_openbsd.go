@@ -0,0 +1,122 @@
+//go:build openbsd
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// GetOwnerAndGroup retrieves the owner UID and group GID of a file or directory on OpenBSD
+func GetOwnerAndGroup(path string) (uid, gid string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetOwnerAndGroupInfo(info)
+}
+
+// GetOwnerAndGroupInfo is GetOwnerAndGroup for an os.FileInfo the caller already has, avoiding another stat
+func GetOwnerAndGroupInfo(info os.FileInfo) (uid, gid string, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return fmt.Sprint(stat.Uid), fmt.Sprint(stat.Gid), nil
+}
+
+// GetCreationTime retrieves the creation time of a file or directory on OpenBSD
+//
+// FFS2 records a birth time in __st_birthtim; when it is not populated (FFS1, msdosfs) the inode change time is
+// returned instead.
+func GetCreationTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetCreationTimeInfo(info)
+}
+
+// GetCreationTimeInfo is GetCreationTime for an os.FileInfo the caller already has, avoiding another stat
+func GetCreationTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	if birth := stat.X__st_birthtim; int64(birth.Sec) > 0 {
+		return time.Unix(int64(birth.Sec), int64(birth.Nsec)), nil
+	}
+	return time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec)), nil
+}
+
+// GetAccessTime retrieves the last access time (atime) of a file or directory on OpenBSD; it does not move on filesystems mounted
+// noatime.
+func GetAccessTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetAccessTimeInfo(info)
+}
+
+// GetAccessTimeInfo is GetAccessTime for an os.FileInfo the caller already has, avoiding another stat
+func GetAccessTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: no stat data for %s", ErrAccessTimeUnsupported, info.Name())
+	}
+	return time.Unix(int64(stat.Atim.Sec), int64(stat.Atim.Nsec)), nil
+}
+
+// mntNoatime is MNT_NOATIME from <sys/mount.h>, which the syscall package does not define on OpenBSD
+const mntNoatime = 0x00008000
+
+// AccessTimeDisabled reports whether the filesystem holding path is mounted noatime, so its access times never move
+// and GetAccessTime returns whatever was last recorded
+func AccessTimeDisabled(path string) (bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	return st.F_flags&mntNoatime != 0, nil
+}
+
+// GetBirthAndChangeTime retrieves the birth time and the inode change time of a file or directory on OpenBSD, returning
+// ErrBirthTimeUnsupported when the filesystem does not record a birth time
+func GetBirthAndChangeTime(path string) (birth, change time.Time, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetBirthAndChangeTimeInfo(info)
+}
+
+// GetBirthAndChangeTimeInfo is GetBirthAndChangeTime for an os.FileInfo the caller already has, avoiding another stat
+func GetBirthAndChangeTimeInfo(info os.FileInfo) (birth, change time.Time, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	if int64(stat.X__st_birthtim.Sec) <= 0 {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w on the filesystem holding %s", ErrBirthTimeUnsupported, info.Name())
+	}
+	birth = time.Unix(int64(stat.X__st_birthtim.Sec), int64(stat.X__st_birthtim.Nsec))
+	change = time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec))
+	return birth, change, nil
+}
+
+// AvailableBytes returns the bytes an unprivileged user may still write to the filesystem holding path, using
+// statfs(2); the blocks reserved for root are not counted. F_bavail goes negative once root has eaten into its
+// reserve, which is reported as 0.
+func AvailableBytes(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("failed to statfs %s: %w", path, err)
+	}
+	if st.F_bavail < 0 {
+		return 0, nil
+	}
+	return uint64(st.F_bavail) * uint64(st.F_bsize), nil
+}
@@ -0,0 +1,90 @@
+//go:build unix && !darwin && !linux && !freebsd && !openbsd
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// GetOwnerAndGroup retrieves the owner UID and group GID of a file or directory on Unix
+func GetOwnerAndGroup(path string) (uid, gid string, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetOwnerAndGroupInfo(info)
+}
+
+// GetOwnerAndGroupInfo is GetOwnerAndGroup for an os.FileInfo the caller already has, avoiding another stat
+func GetOwnerAndGroupInfo(info os.FileInfo) (uid, gid string, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", "", fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return fmt.Sprint(stat.Uid), fmt.Sprint(stat.Gid), nil
+}
+
+// GetCreationTime retrieves the creation time of a file or directory on Unix
+func GetCreationTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetCreationTimeInfo(info)
+}
+
+// GetCreationTimeInfo is GetCreationTime for an os.FileInfo the caller already has, avoiding another stat
+func GetCreationTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec)), nil
+}
+
+// GetAccessTime retrieves the last access time (atime) of a file or directory on Unix; it does not move on filesystems mounted
+// noatime.
+func GetAccessTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetAccessTimeInfo(info)
+}
+
+// GetAccessTimeInfo is GetAccessTime for an os.FileInfo the caller already has, avoiding another stat
+func GetAccessTimeInfo(info os.FileInfo) (time.Time, error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: no stat data for %s", ErrAccessTimeUnsupported, info.Name())
+	}
+	return time.Unix(int64(stat.Atim.Sec), int64(stat.Atim.Nsec)), nil
+}
+
+// AccessTimeDisabled always reports false on this platform, where the syscall package has no statfs to read the
+// mount flags from
+func AccessTimeDisabled(string) (bool, error) {
+	return false, nil
+}
+
+// GetBirthAndChangeTime always returns ErrBirthTimeUnsupported on this platform
+func GetBirthAndChangeTime(path string) (birth, change time.Time, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetBirthAndChangeTimeInfo(info)
+}
+
+// GetBirthAndChangeTimeInfo is GetBirthAndChangeTime for an os.FileInfo the caller already has, avoiding another stat
+func GetBirthAndChangeTimeInfo(info os.FileInfo) (birth, change time.Time, err error) {
+	return time.Time{}, time.Time{}, fmt.Errorf("%w on this platform: %s", ErrBirthTimeUnsupported, info.Name())
+}
+
+// AvailableBytes always returns ErrFreeSpaceUnsupported on this platform, where the syscall package has no statfs
+func AvailableBytes(path string) (uint64, error) {
+	return 0, fmt.Errorf("%w on this platform: %s", ErrFreeSpaceUnsupported, path)
+}
@@ -1,9 +1,16 @@
 package common
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestCommonUtils(t *testing.T) {
@@ -56,6 +63,17 @@ func TestCommonUtils(t *testing.T) {
 			t.Errorf("SanitizePath failed: %v, got %v", err, clean)
 		}
 	})
+
+	t.Run("SanitizePathWith Absolute", func(t *testing.T) {
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		abs, err := SanitizePathWith("dir//file/../test", SanitizeOptions{Absolute: true})
+		if want := filepath.Join(wd, "dir", "test"); err != nil || abs != want {
+			t.Errorf("SanitizePathWith failed: %v, got %v, want %v", err, abs, want)
+		}
+	})
 }
 
 func TestIsPathInBase(t *testing.T) {
@@ -86,6 +104,206 @@ func TestIsPathInBase(t *testing.T) {
 	}
 }
 
+func TestResolvesIntoBase(t *testing.T) {
+	root := t.TempDir()
+	denied := filepath.Join(root, "etc")
+	if err := os.Mkdir(denied, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(denied, "shadow"), []byte("x"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(denied, "shadow"), filepath.Join(root, "innocent.txt")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(denied, filepath.Join(root, "conf")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"Inside as written", filepath.Join(denied, "shadow"), true},
+		{"Outside", filepath.Join(root, "other.txt"), false},
+		{"Symlink to a file inside", filepath.Join(root, "innocent.txt"), true},
+		{"Missing file below a symlinked directory", filepath.Join(root, "conf", "new.conf"), true},
+		{"Traversal out", filepath.Join(denied, "..", "other.txt"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolvesIntoBase(tt.path, denied)
+			if err != nil || got != tt.want {
+				t.Errorf("ResolvesIntoBase(%s) = %v, %v; want %v", tt.path, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSamePath(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	target := filepath.Join(root, "releases", "v1")
+	if err := os.MkdirAll(target, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.Symlink(target, filepath.Join(root, "current")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"Identical", target, target, true},
+		{"Dot dot", filepath.Join(root, "releases", "..", "releases", "v1"), target, true},
+		{"Trailing separator", target + string(filepath.Separator), target, true},
+		{"Symlink and its target", filepath.Join(root, "current"), target, true},
+		{"Different directories", filepath.Join(root, "releases"), target, false},
+		{"Missing below a symlink", filepath.Join(root, "current", "new.txt"), filepath.Join(target, "new.txt"), true},
+		{"Missing parent", filepath.Join(root, "gone", "..", "gone", "new.txt"), filepath.Join(root, "gone", "new.txt"), true},
+		{"Missing and different", filepath.Join(root, "gone", "a.txt"), filepath.Join(root, "gone", "b.txt"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsSamePath(tt.a, tt.b)
+			if err != nil || got != tt.want {
+				t.Errorf("IsSamePath(%s, %s) = %v, %v; want %v", tt.a, tt.b, got, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("Platform default", func(t *testing.T) {
+		got, err := IsSamePath(strings.ToUpper(target), strings.ToLower(target))
+		if err != nil {
+			t.Fatalf("IsSamePath() error = %v", err)
+		}
+		if want := runtime.GOOS == "windows"; got != want {
+			t.Errorf("IsSamePath() on %s = %v, want %v", runtime.GOOS, got, want)
+		}
+	})
+
+	if _, err := IsSamePath("", target); err == nil {
+		t.Error("IsSamePath() with an empty path error = nil, want an error")
+	}
+}
+
+func TestIsPathInBaseCase(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		baseDir         string
+		caseInsensitive bool
+		want            bool
+	}{
+		{"Mixed case folded", "/Tmp/Test/file.txt", "/tmp/test", true, true},
+		{"Mixed case base folded", "/tmp/test/file.txt", "/TMP/Test", true, true},
+		{"Mixed case sensitive", "/Tmp/Test/file.txt", "/tmp/test", false, false},
+		{"Outside base folded", "/Tmp/Other/file.txt", "/tmp/test", true, false},
+		{"Escaping base folded", "/TMP/test/../file.txt", "/tmp/test", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsPathInBaseCase(tt.path, tt.baseDir, tt.caseInsensitive)
+			if err != nil {
+				t.Fatalf("IsPathInBaseCase() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsPathInBaseCase() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Platform default", func(t *testing.T) {
+		base := t.TempDir()
+		got, err := IsPathInBase(filepath.Join(strings.ToUpper(base), "file.txt"), strings.ToLower(base))
+		if err != nil {
+			t.Fatalf("IsPathInBase() error = %v", err)
+		}
+		if want := runtime.GOOS == "windows"; got != want {
+			t.Errorf("IsPathInBase() on %s = %v, want %v", runtime.GOOS, got, want)
+		}
+	})
+}
+
+func TestIsPathInBaseResolved(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "base")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{filepath.Join(base, "real"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	if err := os.Symlink(filepath.Join("..", "outside"), filepath.Join(base, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("real", filepath.Join(base, "alias")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("..", "outside", "gone"), filepath.Join(base, "dangling")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    bool
+		wantErr bool
+	}{
+		{"Existing parent", filepath.Join(base, "real", "file.txt"), true, false},
+		{"Nested missing path", filepath.Join(base, "real", "new", "deep"), true, false},
+		{"Nested missing path below an in-base symlink", filepath.Join(base, "alias", "new", "deep"), true, false},
+		{"Below a symlink out of the base", filepath.Join(base, "link", "file.txt"), false, false},
+		{"Nested missing path below a symlink out of the base", filepath.Join(base, "link", "new", "deep"), false, false},
+		{"Below a dangling symlink", filepath.Join(base, "dangling", "new"), false, true},
+		{"Outside the base", filepath.Join(root, "missing", "file.txt"), false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsPathInBaseResolved(tt.path, base)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("IsPathInBaseResolved(%s) = %v, %v; want %v, error %v", tt.path, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Missing base", func(t *testing.T) {
+		missing := filepath.Join(root, "missing")
+		if got, err := IsPathInBaseResolved(filepath.Join(missing, "a", "b"), missing); err != nil || !got {
+			t.Errorf("IsPathInBaseResolved() = %v, %v; want true", got, err)
+		}
+	})
+}
+
+func TestIsStrictlyInBaseResolved(t *testing.T) {
+	base := t.TempDir()
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"Below base", filepath.Join(base, "child"), true},
+		{"Base itself", base, false},
+		{"Base with trailing separator", base + string(filepath.Separator), false},
+		{"Outside base", filepath.Dir(base), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := IsStrictlyInBaseResolved(tt.path, base); err != nil || got != tt.want {
+				t.Errorf("IsStrictlyInBaseResolved(%s) = %v, %v, want %v", tt.path, got, err, tt.want)
+			}
+		})
+	}
+	if !IsFilesystemRoot(string(filepath.Separator)) || IsFilesystemRoot(base) {
+		t.Errorf("IsFilesystemRoot() does not tell the root from %s", base)
+	}
+}
+
 func TestRelStartsWithParent(t *testing.T) {
 	tests := []struct {
 		name string
@@ -107,6 +325,19 @@ func TestRelStartsWithParent(t *testing.T) {
 	}
 }
 
+func TestContextReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := ContextReader(ctx, strings.NewReader("abcdef"))
+	buf := make([]byte, 3)
+	if n, err := r.Read(buf); err != nil || n != 3 {
+		t.Fatalf("Read() = %d, %v; want 3, nil", n, err)
+	}
+	cancel()
+	if _, err := r.Read(buf); !errors.Is(err, context.Canceled) {
+		t.Errorf("Read() after cancel error = %v, want context.Canceled", err)
+	}
+}
+
 func BenchmarkIsPathInBase(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = IsPathInBase("/tmp/test/file.txt", "/tmp/test")
@@ -118,3 +349,527 @@ func BenchmarkRelStartsWithParent(b *testing.B) {
 		_ = RelStartsWithParent("../file.txt")
 	}
 }
+
+func TestErrorf(t *testing.T) {
+	inner := errors.New("inner")
+	err := Errorf(ErrSizeMismatch, "size of %s is wrong: %w", "a.txt", inner)
+	if err.Error() != "size of a.txt is wrong: inner" {
+		t.Errorf("Errorf() message = %q", err.Error())
+	}
+	if !errors.Is(err, ErrSizeMismatch) {
+		t.Error("Errorf() does not match its sentinel")
+	}
+	if !errors.Is(err, inner) {
+		t.Error("Errorf() does not match the wrapped error")
+	}
+	if errors.Is(err, ErrNameMismatch) {
+		t.Error("Errorf() matches an unrelated sentinel")
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Code
+	}{
+		{Errorf(ErrSizeMismatch, "size of %s is wrong", "a.txt"), CodeSizeMismatch},
+		{fmt.Errorf("%w: RequireOwner", ErrUnsupportedFS), CodeUnsupported},
+		{Errorf(ErrIdentityMismatch, "%s was replaced", "a.txt"), CodeIdentityMismatch},
+		{context.DeadlineExceeded, CodeCanceled},
+		{errors.New("permission denied"), CodeUnknown},
+	}
+	for _, tt := range tests {
+		if got := CodeOf(tt.err); got != tt.want {
+			t.Errorf("CodeOf(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	if result := NewResult("a.txt", nil); !result.Passed || result.Violations != nil {
+		t.Errorf("NewResult(nil) = %+v, want passed", result)
+	}
+	result := NewResult("a.txt", []Failure{{Field: "IsSize", Err: Errorf(ErrSizeMismatch, "size is wrong")}})
+	want := Violation{Code: CodeSizeMismatch, Field: "IsSize", Message: "size is wrong", Path: "a.txt"}
+	if result.Passed || len(result.Violations) != 1 || result.Violations[0] != want {
+		t.Errorf("NewResult() = %+v, want one %+v", result, want)
+	}
+}
+
+func TestSymlinkComponents(t *testing.T) {
+	dir := t.TempDir()
+	baseline, err := SymlinkComponents(dir)
+	if err != nil {
+		t.Fatalf("SymlinkComponents() error = %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "real"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.Symlink("real", filepath.Join(dir, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("link", filepath.Join(dir, "chain")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{filepath.Join(dir, "real"), baseline},
+		{filepath.Join(dir, "link"), baseline + 1},
+		{filepath.Join(dir, "chain"), baseline + 1}, // a chain is a single component
+		{filepath.Join(dir, "link", "..", "link"), baseline + 1},
+	}
+	for _, tt := range tests {
+		got, err := SymlinkComponents(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("SymlinkComponents(%s) = %d, %v; want %d", tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := SymlinkComponents(filepath.Join(dir, "missing", "file")); err == nil {
+		t.Error("SymlinkComponents() on missing path returned nil error")
+	}
+}
+
+func TestFirstSymlink(t *testing.T) {
+	// resolve the temp dir itself, which sits below a symlink on some systems (/var on macOS)
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "real", "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink("real", link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(dir, "real", "sub"), ""},
+		{filepath.Join(dir, "real", "missing", "file"), ""},
+		{link, link},
+		{filepath.Join(link, "sub"), link},
+		{filepath.Join(link, "missing", "file"), link},
+	}
+	for _, tt := range tests {
+		got, err := FirstSymlink(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("FirstSymlink(%s) = %q, %v; want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestHasSymlinkComponent(t *testing.T) {
+	// resolve the temp dir itself, which sits below a symlink on some systems (/var on macOS)
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "real", "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink("real", link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		found bool
+		want  string
+	}{
+		{filepath.Join(dir, "real", "sub", "file"), false, ""},
+		{link, false, ""}, // the leaf is not an ancestor
+		{filepath.Join(link, "file"), true, link},
+		{filepath.Join(link, "sub", "file"), true, link},
+		{filepath.Join(dir, "missing", "file"), false, ""},
+	}
+	for _, tt := range tests {
+		found, got, err := HasSymlinkComponent(tt.path)
+		if err != nil || found != tt.found || got != tt.want {
+			t.Errorf("HasSymlinkComponent(%s) = %v, %q, %v; want %v, %q", tt.path, found, got, err, tt.found, tt.want)
+		}
+	}
+}
+
+func TestIsBrokenSymlink(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "regular.txt")
+	if err := os.WriteFile(regular, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Symlink("regular.txt", filepath.Join(dir, "valid")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("nested", "gone.txt"), filepath.Join(dir, "broken")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"Valid symlink", filepath.Join(dir, "valid"), false},
+		{"Broken symlink", filepath.Join(dir, "broken"), true},
+		{"Regular file", regular, false},
+		{"Missing path", filepath.Join(dir, "missing"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsBrokenSymlink(tt.path)
+			if err != nil {
+				t.Fatalf("IsBrokenSymlink() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsBrokenSymlink() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	target, err := SymlinkTarget(filepath.Join(dir, "broken"))
+	if err != nil {
+		t.Fatalf("SymlinkTarget() error = %v", err)
+	}
+	if want := filepath.Join(dir, "nested", "gone.txt"); target != want {
+		t.Errorf("SymlinkTarget() = %s, want %s", target, want)
+	}
+}
+
+func TestStatCache(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	cache := NewStatCache(2)
+	for _, name := range []string{
+		filepath.Join(dir, "a.txt"),
+		filepath.Join(dir, ".", "a.txt"),
+		filepath.Join(dir, "sub", "..", "a.txt"),
+		filepath.Join(dir, "missing.txt"),
+	} {
+		_, _ = cache.Stat(name)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1: equivalent paths share a key and failures are not cached", got)
+	}
+	for _, name := range []string{"b.txt", "."} {
+		if _, err := cache.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+	}
+	if got := cache.Len(); got != 2 {
+		t.Errorf("Len() = %d, want the cap of 2", got)
+	}
+
+	ctx := WithStatCache(context.Background(), cache)
+	if err := os.Remove(filepath.Join(dir, "a.txt")); err != nil {
+		t.Fatalf("Failed to remove test file: %v", err)
+	}
+	if _, err := StatContext(ctx, nil, filepath.Join(dir, "a.txt")); err != nil {
+		t.Errorf("StatContext() error = %v, want the cached result", err)
+	}
+	cache.Reset()
+	if _, err := StatContext(ctx, nil, filepath.Join(dir, "a.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("StatContext() after Reset error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestPermissionsL(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	if err := os.WriteFile(target, []byte("x"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(target, 0600); err != nil {
+		t.Fatalf("Failed to chmod test file: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("Lstat() error = %v", err)
+	}
+	linkPerm := info.Mode().Perm()
+	if linkPerm == 0600 {
+		t.Skipf("symlink mode %o matches its target", linkPerm)
+	}
+
+	if ok, err := IsLessPermissiveThan(link, 0600); err != nil || !ok {
+		t.Errorf("IsLessPermissiveThan() = %v, %v, want the target's 0600 to pass", ok, err)
+	}
+	if ok, err := IsLessPermissiveThanL(link, 0600); err != nil || ok {
+		t.Errorf("IsLessPermissiveThanL() = %v, %v, want the link's %o to fail", ok, err, linkPerm)
+	}
+	if ok, err := IsMorePermissiveThanL(link, linkPerm); err != nil || !ok {
+		t.Errorf("IsMorePermissiveThanL() = %v, %v, want true", ok, err)
+	}
+	if ok, err := HasPermissionsL(link, linkPerm); err != nil || !ok {
+		t.Errorf("HasPermissionsL() = %v, %v, want true", ok, err)
+	}
+	if _, err := HasPermissionsL(filepath.Join(dir, "missing"), 0600); err == nil {
+		t.Error("HasPermissionsL() on a missing path error = nil")
+	}
+}
+
+func TestMoveAll(t *testing.T) {
+	for _, crossDevice := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Cross device %v", crossDevice), func(t *testing.T) {
+			if crossDevice {
+				defer func(original func(string, string) error) { rename = original }(rename)
+				rename = func(src, dst string) error {
+					return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EXDEV}
+				}
+			}
+			dir := t.TempDir()
+			src := filepath.Join(dir, "src")
+			if err := os.MkdirAll(filepath.Join(src, "nested"), 0755); err != nil {
+				t.Fatalf("Failed to create test directory: %v", err)
+			}
+			if err := os.WriteFile(filepath.Join(src, "nested", "a.txt"), []byte("a"), 0640); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			if err := os.Chmod(filepath.Join(src, "nested"), 0500); err != nil {
+				t.Fatalf("Failed to chmod test directory: %v", err)
+			}
+			defer os.Chmod(filepath.Join(dir, "dst", "nested"), 0755)
+			symlinks := os.Symlink(filepath.Join("nested", "a.txt"), filepath.Join(src, "link")) == nil
+
+			dst := filepath.Join(dir, "dst")
+			if err := MoveAll(src, dst); err != nil {
+				t.Fatalf("MoveAll() error = %v", err)
+			}
+			if _, err := os.Stat(src); !os.IsNotExist(err) {
+				t.Errorf("MoveAll() left %s behind: %v", src, err)
+			}
+			data, err := os.ReadFile(filepath.Join(dst, "nested", "a.txt"))
+			if err != nil || string(data) != "a" {
+				t.Errorf("moved file = %q, %v, want %q", data, err, "a")
+			}
+			if info, err := os.Stat(filepath.Join(dst, "nested")); err != nil || info.Mode().Perm() != 0500 {
+				t.Errorf("moved directory = %v, %v, want mode 0500", info, err)
+			}
+			if symlinks {
+				if target, err := os.Readlink(filepath.Join(dst, "link")); err != nil || target != filepath.Join("nested", "a.txt") {
+					t.Errorf("moved symlink = %q, %v", target, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := SyncDir(dir); err != nil {
+		t.Errorf("SyncDir() error = %v", err)
+	}
+	if err := SyncDir(filepath.Join(dir, "missing")); runtime.GOOS != "windows" && err == nil {
+		t.Error("SyncDir() on a missing directory error = nil")
+	}
+}
+
+func TestLinkCount(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hard link counts are not supported on Windows")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "original.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if count, err := LinkCount(path); err != nil || count != 1 {
+		t.Errorf("LinkCount() = %d, %v, want 1", count, err)
+	}
+	if err := os.Link(path, filepath.Join(dir, "second.txt")); err != nil {
+		t.Fatalf("Failed to create hard link: %v", err)
+	}
+	if count, err := LinkCount(path); err != nil || count != 2 {
+		t.Errorf("LinkCount() after os.Link = %d, %v, want 2", count, err)
+	}
+	if _, err := LinkCount(filepath.Join(dir, "missing.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LinkCount() of a missing file error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestFileIdentity(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file identity is not supported on Windows")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("a: 1"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	dev, ino, err := FileIdentity(path)
+	if err != nil || ino == 0 {
+		t.Fatalf("FileIdentity() = %d, %d, %v, want a non-zero inode", dev, ino, err)
+	}
+	if err := os.WriteFile(path, []byte("a: 2"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite test file: %v", err)
+	}
+	if d, i, err := FileIdentity(path); err != nil || d != dev || i != ino {
+		t.Errorf("FileIdentity() after rewriting in place = %d, %d, %v, want %d, %d", d, i, err, dev, ino)
+	}
+
+	replacement := filepath.Join(dir, "config.yaml.new")
+	if err := os.WriteFile(replacement, []byte("a: 3"), 0644); err != nil {
+		t.Fatalf("Failed to create replacement: %v", err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatalf("Failed to rename replacement: %v", err)
+	}
+	if _, i, err := FileIdentity(path); err != nil || i == ino {
+		t.Errorf("FileIdentity() after replacing = %d, %v, want an inode other than %d", i, err, ino)
+	}
+	if _, _, err := FileIdentity(filepath.Join(dir, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("FileIdentity() of a missing file error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestModeDiff(t *testing.T) {
+	tests := []struct {
+		name           string
+		actual         os.FileMode
+		expected       os.FileMode
+		missing, extra os.FileMode
+	}{
+		{"Equal", 0644, 0644, 0, 0},
+		{"Group and other writable", 0666, 0644, 0, 0022},
+		{"World readable key", 0644, 0600, 0, 0044},
+		{"Missing execute", 0644, 0755, 0111, 0},
+		{"Both ways", 0640, 0604, 0004, 0040},
+		{"Every bit missing", 0, 0777, 0777, 0},
+		{"Every bit extra", 0777, 0, 0, 0777},
+		{"Single bits", 0001, 0400, 0400, 0001},
+		{"Type and special bits ignored", os.ModeDir | os.ModeSetuid | 0755, os.ModeSticky | 0750, 0, 0005},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			missing, extra := ModeDiff(tt.actual, tt.expected)
+			if missing != tt.missing || extra != tt.extra {
+				t.Errorf("ModeDiff(%o, %o) = %04o, %04o, want %04o, %04o", tt.actual, tt.expected, missing, extra, tt.missing, tt.extra)
+			}
+		})
+	}
+
+	// Every one of the nine bits on its own lands on exactly one side
+	for bit := os.FileMode(1); bit <= 0400; bit <<= 1 {
+		if missing, extra := ModeDiff(0, bit); missing != bit || extra != 0 {
+			t.Errorf("ModeDiff(0, %04o) = %04o, %04o, want %04o, 0", bit, missing, extra, bit)
+		}
+		if missing, extra := ModeDiff(bit, 0); missing != 0 || extra != bit {
+			t.Errorf("ModeDiff(%04o, 0) = %04o, %04o, want 0, %04o", bit, missing, extra, bit)
+		}
+	}
+}
+
+func TestAvailableBytes(t *testing.T) {
+	dir := t.TempDir()
+	available, err := AvailableBytes(dir)
+	if errors.Is(err, ErrFreeSpaceUnsupported) {
+		t.Skipf("free space not supported: %v", err)
+	}
+	if err != nil || available == 0 {
+		t.Fatalf("AvailableBytes() = %d, %v, want a non-zero amount", available, err)
+	}
+	if available > 1<<60 {
+		t.Errorf("AvailableBytes() = %d, an implausible amount", available)
+	}
+	if _, err := AvailableBytes(filepath.Join(dir, "missing")); err == nil {
+		t.Error("AvailableBytes() of a missing directory error = nil")
+	}
+}
+
+func TestFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.lock")
+	first, err := FileLock(path)
+	if errors.Is(err, ErrLockUnsupported) {
+		t.Skipf("file locking not supported: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("FileLock() error = %v", err)
+	}
+
+	locked := make(chan error)
+	go func() {
+		second, err := FileLock(path)
+		if err == nil {
+			err = Unlock(second)
+		}
+		locked <- err
+	}()
+	select {
+	case err := <-locked:
+		t.Fatalf("second FileLock() returned %v while the first lock was held", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := Unlock(first); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	select {
+	case err := <-locked:
+		if err != nil {
+			t.Errorf("second FileLock() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second FileLock() still blocked after Unlock()")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("lock file was removed: %v", err)
+	}
+	if _, err := FileLock(filepath.Join(path, "missing", "app.lock")); err == nil {
+		t.Error("FileLock() in a missing directory error = nil")
+	}
+}
+
+func TestAccessAndModificationTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stale.log")
+	before := time.Now()
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	after := time.Now()
+
+	// filesystem timestamps can be coarser than the clock, so allow some slack around the write
+	mtime, err := GetModificationTime(path)
+	if err != nil || mtime.Before(before.Add(-2*time.Second)) || mtime.After(after.Add(2*time.Second)) {
+		t.Errorf("GetModificationTime() = %v, %v, want about %v", mtime, err, before)
+	}
+	atime, err := GetAccessTime(path)
+	if err != nil || atime.IsZero() {
+		t.Errorf("GetAccessTime() = %v, %v, want a non-zero time", atime, err)
+	}
+
+	wantAtime, wantMtime := time.Unix(1_600_000_000, 0), time.Unix(1_700_000_000, 0)
+	if err := os.Chtimes(path, wantAtime, wantMtime); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+	if got, err := GetModificationTime(path); err != nil || !got.Equal(wantMtime) {
+		t.Errorf("GetModificationTime() after Chtimes = %v, %v, want %v", got, err, wantMtime)
+	}
+	if got, err := GetAccessTime(path); err != nil || !got.Equal(wantAtime) {
+		t.Errorf("GetAccessTime() after Chtimes = %v, %v, want %v", got, err, wantAtime)
+	}
+
+	if _, err := AccessTimeDisabled(path); err != nil {
+		t.Errorf("AccessTimeDisabled() error = %v", err)
+	}
+
+	missing := filepath.Join(filepath.Dir(path), "missing.log")
+	if _, err := GetModificationTime(missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("GetModificationTime() of a missing file error = %v, want os.ErrNotExist", err)
+	}
+	if _, err := GetAccessTime(missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("GetAccessTime() of a missing file error = %v, want os.ErrNotExist", err)
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"os"
 	"syscall"
-	"time"
 )
 
+// caseInsensitivePaths is the default case sensitivity of IsPathInBase; paths on Linux and the other unix systems
+// are case-sensitive
+const caseInsensitivePaths = false
+
 // HasPermissions checks if a file or directory has at least the specified permissions
 func HasPermissions(path string, perms os.FileMode) (bool, error) {
 	info, err := os.Stat(path)
@@ -19,48 +22,97 @@ func HasPermissions(path string, perms os.FileMode) (bool, error) {
 	return actualPerms&perms == perms, nil
 }
 
+// HasPermissionsL is HasPermissions for the symlink itself rather than its target
+func HasPermissionsL(path string, perms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	actualPerms := info.Mode().Perm()
+	return actualPerms&perms == perms, nil
+}
+
 // IsMorePermissiveThan checks if a file or directory’s permissions are at least as permissive as the given mode
 func IsMorePermissiveThan(path string, minPerms os.FileMode) (bool, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return false, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return IsMorePermissiveThanInfo(info, minPerms), nil
+}
+
+// IsMorePermissiveThanL is IsMorePermissiveThan for the symlink itself rather than its target
+func IsMorePermissiveThanL(path string, minPerms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	return IsMorePermissiveThanInfo(info, minPerms), nil
+}
+
+// IsMorePermissiveThanInfo is IsMorePermissiveThan for an os.FileInfo the caller already has, avoiding another stat
+func IsMorePermissiveThanInfo(info os.FileInfo, minPerms os.FileMode) bool {
 	perms := info.Mode().Perm()
-	return perms&minPerms == minPerms, nil
+	return perms&minPerms == minPerms
 }
 
-// GetOwnerAndGroup retrieves the owner UID and group GID of a file or directory on Unix
-func GetOwnerAndGroup(path string) (uid, gid string, err error) {
+// IsLessPermissiveThan checks if a file or directory’s permissions are no more permissive than the given mode
+func IsLessPermissiveThan(path string, maxPerms os.FileMode) (bool, error) {
 	info, err := os.Stat(path)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to stat %s: %w", path, err)
+		return false, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
-	stat, ok := info.Sys().(*syscall.Stat_t)
-	if !ok {
-		return "", "", fmt.Errorf("unable to get detailed stats for %s", path)
+	return IsLessPermissiveThanInfo(info, maxPerms), nil
+}
+
+// IsLessPermissiveThanL is IsLessPermissiveThan for the symlink itself rather than its target
+func IsLessPermissiveThanL(path string, maxPerms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
 	}
-	return fmt.Sprint(stat.Uid), fmt.Sprint(stat.Gid), nil
+	return IsLessPermissiveThanInfo(info, maxPerms), nil
 }
 
-// GetCreationTime retrieves the creation time of a file or directory on Unix
-func GetCreationTime(path string) (time.Time, error) {
+// IsLessPermissiveThanInfo is IsLessPermissiveThan for an os.FileInfo the caller already has, avoiding another stat
+func IsLessPermissiveThanInfo(info os.FileInfo, maxPerms os.FileMode) bool {
+	perms := info.Mode().Perm()
+	return perms&^maxPerms == 0
+}
+
+// LinkCount returns the number of hard links to the file at path
+func LinkCount(path string) (uint64, error) {
 	info, err := os.Stat(path)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+		return 0, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return LinkCountInfo(info)
+}
+
+// LinkCountInfo is LinkCount for an os.FileInfo the caller already has, avoiding another stat
+func LinkCountInfo(info os.FileInfo) (uint64, error) {
 	stat, ok := info.Sys().(*syscall.Stat_t)
 	if !ok {
-		return time.Time{}, fmt.Errorf("unable to get detailed stats for %s", path)
+		return 0, fmt.Errorf("unable to get detailed stats for %s", info.Name())
 	}
-	return time.Unix(int64(stat.Ctim.Sec), int64(stat.Ctim.Nsec)), nil
+	return uint64(stat.Nlink), nil
 }
 
-// IsLessPermissiveThan checks if a file or directory’s permissions are no more permissive than the given mode
-func IsLessPermissiveThan(path string, maxPerms os.FileMode) (bool, error) {
+// FileIdentity returns the device and inode numbers of the file at path, which together identify it for as long as it
+// exists: a file replaced at the same path, even by a rename, gets a different pair
+func FileIdentity(path string) (dev, ino uint64, err error) {
 	info, err := os.Stat(path)
 	if err != nil {
-		return false, fmt.Errorf("failed to stat %s: %w", path, err)
+		return 0, 0, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
-	perms := info.Mode().Perm()
-	return perms&^maxPerms == 0, nil
+	return FileIdentityInfo(info)
+}
+
+// FileIdentityInfo is FileIdentity for an os.FileInfo the caller already has, avoiding another stat
+func FileIdentityInfo(info os.FileInfo) (dev, ino uint64, err error) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, fmt.Errorf("unable to get detailed stats for %s", info.Name())
+	}
+	return uint64(stat.Dev), uint64(stat.Ino), nil
 }
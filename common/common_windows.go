@@ -7,8 +7,12 @@ import (
 	"os"
 	"syscall"
 	"time"
+	"unsafe"
 )
 
+// caseInsensitivePaths is the default case sensitivity of IsPathInBase; Windows paths are case-insensitive
+const caseInsensitivePaths = true
+
 // HasPermissions checks if a file or directory has at least the specified permissions
 // On Windows, this is simplified due to NTFS permissions not mapping directly to Unix modes
 func HasPermissions(path string, perms os.FileMode) (bool, error) {
@@ -21,6 +25,17 @@ func HasPermissions(path string, perms os.FileMode) (bool, error) {
 	return actualPerms&perms&0666 != 0, nil // Ignore execute bits, focus on read/write
 }
 
+// HasPermissionsL is HasPermissions for the symlink itself rather than its target; Windows has no symlink modes, so
+// this is only meaningful for regular paths
+func HasPermissionsL(path string, perms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	actualPerms := info.Mode().Perm()
+	return actualPerms&perms&0666 != 0, nil
+}
+
 // IsMorePermissiveThan checks if a file or directory’s permissions are at least as permissive as the given mode
 // Adjusted for Windows behavior where strict Unix perms aren't enforced
 func IsMorePermissiveThan(path string, minPerms os.FileMode) (bool, error) {
@@ -28,24 +43,73 @@ func IsMorePermissiveThan(path string, minPerms os.FileMode) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return IsMorePermissiveThanInfo(info, minPerms), nil
+}
+
+// IsMorePermissiveThanL is IsMorePermissiveThan for the symlink itself rather than its target
+func IsMorePermissiveThanL(path string, minPerms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	return IsMorePermissiveThanInfo(info, minPerms), nil
+}
+
+// IsMorePermissiveThanInfo is IsMorePermissiveThan for an os.FileInfo the caller already has, avoiding another stat
+func IsMorePermissiveThanInfo(info os.FileInfo, minPerms os.FileMode) bool {
 	perms := info.Mode().Perm()
 	// On Windows, assume read/write perms are broader; mask to relevant bits
-	return perms&0444 >= minPerms&0444, nil // Focus on read bits as a minimum
+	return perms&0444 >= minPerms&0444 // Focus on read bits as a minimum
 }
 
 func GetOwnerAndGroup(path string) (uid, gid string, err error) {
 	return "", "", fmt.Errorf("owner and group checks are not supported on Windows: %s", path)
 }
 
+// GetOwnerAndGroupInfo is GetOwnerAndGroup for an os.FileInfo the caller already has, avoiding another stat
+func GetOwnerAndGroupInfo(info os.FileInfo) (uid, gid string, err error) {
+	return "", "", fmt.Errorf("owner and group checks are not supported on Windows: %s", info.Name())
+}
+
 func GetCreationTime(path string) (time.Time, error) {
 	info, err := os.Stat(path)
 	if err != nil {
 		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return GetCreationTimeInfo(info)
+}
+
+// GetCreationTimeInfo is GetCreationTime for an os.FileInfo the caller already has, avoiding another stat
+func GetCreationTimeInfo(info os.FileInfo) (time.Time, error) {
 	if stat, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
 		return time.Unix(0, stat.CreationTime.Nanoseconds()), nil
 	}
-	return time.Time{}, fmt.Errorf("unable to get creation time for %s on Windows", path)
+	return time.Time{}, fmt.Errorf("unable to get creation time for %s on Windows", info.Name())
+}
+
+// GetAccessTime retrieves the last access time of a file or directory on Windows. NTFS updates it lazily, within
+// an hour, and not at all when last access updates are disabled.
+func GetAccessTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetAccessTimeInfo(info)
+}
+
+// GetAccessTimeInfo is GetAccessTime for an os.FileInfo the caller already has, avoiding another stat. It needs the
+// *syscall.Win32FileAttributeData os.Stat returns on Windows, and fails for any other FileInfo.
+func GetAccessTimeInfo(info os.FileInfo) (time.Time, error) {
+	if stat, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, stat.LastAccessTime.Nanoseconds()), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: no Win32 file attributes for %s", ErrAccessTimeUnsupported, info.Name())
+}
+
+// AccessTimeDisabled always reports false on Windows, where last access updates are a system-wide registry setting
+// rather than a property of the volume
+func AccessTimeDisabled(string) (bool, error) {
+	return false, nil
 }
 
 // IsLessPermissiveThan checks if a file or directory’s permissions are no more permissive than the given mode
@@ -55,7 +119,79 @@ func IsLessPermissiveThan(path string, maxPerms os.FileMode) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to stat %s: %w", path, err)
 	}
+	return IsLessPermissiveThanInfo(info, maxPerms), nil
+}
+
+// IsLessPermissiveThanL is IsLessPermissiveThan for the symlink itself rather than its target
+func IsLessPermissiveThanL(path string, maxPerms os.FileMode) (bool, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to lstat %s: %w", path, err)
+	}
+	return IsLessPermissiveThanInfo(info, maxPerms), nil
+}
+
+// IsLessPermissiveThanInfo is IsLessPermissiveThan for an os.FileInfo the caller already has, avoiding another stat
+func IsLessPermissiveThanInfo(info os.FileInfo, maxPerms os.FileMode) bool {
 	perms := info.Mode().Perm()
 	// Windows perms are often broader; check if within maxPerms bounds
-	return perms&0666 <= maxPerms&0666, nil // Focus on read/write bits
+	return perms&0666 <= maxPerms&0666 // Focus on read/write bits
+}
+
+// GetBirthAndChangeTime always returns ErrBirthTimeUnsupported on Windows: the change time is not part of the attribute
+// data returned by os.Stat
+func GetBirthAndChangeTime(path string) (birth, change time.Time, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return GetBirthAndChangeTimeInfo(info)
+}
+
+// GetBirthAndChangeTimeInfo is GetBirthAndChangeTime for an os.FileInfo the caller already has, avoiding another stat
+func GetBirthAndChangeTimeInfo(info os.FileInfo) (birth, change time.Time, err error) {
+	return time.Time{}, time.Time{}, fmt.Errorf("%w on windows: %s", ErrBirthTimeUnsupported, info.Name())
+}
+
+// LinkCount is not supported on Windows, where os.FileInfo does not carry the hard link count
+func LinkCount(path string) (uint64, error) {
+	return 0, fmt.Errorf("hard link counts are not supported on Windows: %s", path)
+}
+
+// LinkCountInfo is not supported on Windows, see LinkCount
+func LinkCountInfo(info os.FileInfo) (uint64, error) {
+	return 0, fmt.Errorf("hard link counts are not supported on Windows: %s", info.Name())
+}
+
+// FileIdentity is not supported on Windows, where os.FileInfo does not carry device and inode numbers
+func FileIdentity(path string) (dev, ino uint64, err error) {
+	return 0, 0, fmt.Errorf("file identity is not supported on Windows: %s", path)
+}
+
+// FileIdentityInfo is not supported on Windows, see FileIdentity
+func FileIdentityInfo(info os.FileInfo) (dev, ino uint64, err error) {
+	return 0, 0, fmt.Errorf("file identity is not supported on Windows: %s", info.Name())
+}
+
+// getDiskFreeSpaceEx is GetDiskFreeSpaceExW from kernel32, which the syscall package does not wrap
+var getDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// AvailableBytes returns the bytes the calling user may still write to the volume holding path, which must be a
+// directory, using GetDiskFreeSpaceExW; disk quotas are taken into account
+func AvailableBytes(path string) (uint64, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, fmt.Errorf("invalid path %s: %w", path, err)
+	}
+	var available, total, free uint64
+	ok, _, err := getDiskFreeSpaceEx.Call(
+		uintptr(unsafe.Pointer(name)),
+		uintptr(unsafe.Pointer(&available)),
+		uintptr(unsafe.Pointer(&total)),
+		uintptr(unsafe.Pointer(&free)),
+	)
+	if ok == 0 {
+		return 0, fmt.Errorf("failed to get free space of %s: %w", path, err)
+	}
+	return available, nil
 }
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+)
+
+// DirSize returns the total size in bytes of the regular files in the tree rooted at path. Symlinks are neither
+// followed nor counted, and a file hard linked more than once inside the tree counts once on platforms that expose
+// inode numbers (every unix). path itself may be a symlink to the directory.
+func DirSize(path string) (int64, error) {
+	return DirSizeContext(context.Background(), path)
+}
+
+// DirSizeContext is DirSize, returning ctx.Err() as soon as ctx is done
+func DirSizeContext(ctx context.Context, path string) (int64, error) {
+	return DirSizeWith(ctx, path, WalkOptions{})
+}
+
+// DirSizeWith is DirSizeContext counting only the files a WalkSafeContext walk with opts reaches
+func DirSizeWith(ctx context.Context, path string, opts WalkOptions) (int64, error) {
+	var total int64
+	seen := make(map[fileID]bool)
+	err := WalkSafeContext(ctx, path, opts, func(name string, d fs.DirEntry) error {
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if id, linked := hardLinkID(info); linked {
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+		}
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute the size of %s: %w", path, err)
+	}
+	return total, nil
+}
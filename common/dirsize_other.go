@@ -0,0 +1,26 @@
+//go:build !unix
+
+package common
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// fileID identifies a file by device and inode
+type fileID struct{ dev, ino uint64 }
+
+// hardLinkID reports no hard links where inode numbers are not exposed through fs.FileInfo
+func hardLinkID(fs.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
+
+// dirKey identifies the directory path by its absolute, symlink-free path for WalkSafe, since inode numbers are not
+// exposed here
+func dirKey(path string, _ fs.FileInfo) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(resolved)
+}
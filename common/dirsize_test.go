@@ -0,0 +1,66 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestDirSize(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]int{
+		"a.txt":            10,
+		"sub/b.txt":        200,
+		"sub/deeper/c.bin": 3000,
+		"empty/.keep":      0,
+	}
+	for name, size := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	const want = 3210
+
+	if got, err := DirSize(root); err != nil || got != want {
+		t.Fatalf("DirSize() = %d, %v, want %d", got, err, want)
+	}
+
+	if runtime.GOOS != "windows" {
+		outside := filepath.Join(t.TempDir(), "outside.bin")
+		if err := os.WriteFile(outside, make([]byte, 1<<16), 0644); err != nil {
+			t.Fatalf("Failed to create outside file: %v", err)
+		}
+		if err := os.Symlink(outside, filepath.Join(root, "link.bin")); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		if err := os.Link(filepath.Join(root, "sub", "deeper", "c.bin"), filepath.Join(root, "hardlink.bin")); err != nil {
+			t.Fatalf("Failed to create hard link: %v", err)
+		}
+		if got, err := DirSize(root); err != nil || got != want {
+			t.Errorf("DirSize() with a symlink and a hard link = %d, %v, want %d", got, err, want)
+		}
+		linkedRoot := filepath.Join(t.TempDir(), "root")
+		if err := os.Symlink(root, linkedRoot); err != nil {
+			t.Fatalf("Failed to create symlink to root: %v", err)
+		}
+		if got, err := DirSize(linkedRoot); err != nil || got != want {
+			t.Errorf("DirSize() through a symlinked root = %d, %v, want %d", got, err, want)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := DirSizeContext(ctx, root); !errors.Is(err, context.Canceled) {
+		t.Errorf("DirSizeContext() with a canceled context error = %v, want context.Canceled", err)
+	}
+	if _, err := DirSize(filepath.Join(root, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("DirSize() of a missing path error = %v, want os.ErrNotExist", err)
+	}
+}
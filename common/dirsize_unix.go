@@ -0,0 +1,31 @@
+//go:build unix
+
+package common
+
+import (
+	"fmt"
+	"io/fs"
+	"syscall"
+)
+
+// fileID identifies a file by device and inode
+type fileID struct{ dev, ino uint64 }
+
+// hardLinkID returns the fileID of info when the file has more than one hard link, the only case where it can be
+// seen twice in a walk
+func hardLinkID(info fs.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || uint64(st.Nlink) < 2 {
+		return fileID{}, false
+	}
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true
+}
+
+// dirKey identifies the directory path by device and inode for WalkSafe
+func dirKey(path string, info fs.FileInfo) (string, error) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", fmt.Errorf("unable to get detailed stats for %s", path)
+	}
+	return fmt.Sprintf("%d:%d", uint64(st.Dev), uint64(st.Ino)), nil
+}
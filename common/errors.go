@@ -0,0 +1,66 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors categorize why a check failed so callers can branch with errors.Is instead of matching strings.
+// The file and directory packages re-export these so errors.Is(err, file.ErrSizeMismatch) works.
+var (
+	ErrInvalidOptions     = errors.New("invalid options")
+	ErrDoesNotExist       = errors.New("does not exist")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrNotRegularFile     = errors.New("not a regular file")
+	ErrNotDirectory       = errors.New("not a directory")
+	ErrSizeMismatch       = errors.New("size mismatch")
+	ErrTimeMismatch       = errors.New("time mismatch")
+	ErrNameMismatch       = errors.New("name mismatch")
+	ErrBadBaseDir         = errors.New("not in required base directory")
+	ErrSymlinkMismatch    = errors.New("symlink policy violated")
+	ErrPermissionMismatch = errors.New("permission mismatch")
+	ErrOwnerMismatch      = errors.New("owner mismatch")
+	ErrGroupMismatch      = errors.New("group mismatch")
+	ErrContentMismatch    = errors.New("content mismatch")
+	ErrIdentityMismatch   = errors.New("identity mismatch")
+)
+
+// ErrUnsupportedFS is returned by the fs.FS variants of the checks when Options ask for something an fs.FS cannot
+// answer, such as ownership, creation time or anything that needs a real OS path
+var ErrUnsupportedFS = errors.New("unsupported on fs.FS")
+
+// ErrSymlinkLoop is returned by WalkSafeContext with FollowSymlinks when a symlink leads back to a directory the walk
+// is already inside
+var ErrSymlinkLoop = errors.New("symlink loop")
+
+// ErrBirthTimeUnsupported is returned by GetBirthAndChangeTime where the platform or filesystem records no birth time
+var ErrBirthTimeUnsupported = errors.New("birth time is not available")
+
+// ErrAccessTimeUnsupported is returned where the platform or filesystem does not keep access times: the os.FileInfo
+// carries none, or the filesystem is mounted noatime
+var ErrAccessTimeUnsupported = errors.New("access time is not available")
+
+// ErrFreeSpaceUnsupported is returned by AvailableBytes where the platform offers no way to query free space
+var ErrFreeSpaceUnsupported = errors.New("free space is not available")
+
+// ErrLockUnsupported is returned by FileLock where the platform offers no advisory file locks
+var ErrLockUnsupported = errors.New("file locking is not available")
+
+// Errorf formats an error exactly like fmt.Errorf (including any %w verbs) and additionally makes it match sentinel
+// with errors.Is, without the sentinel's text appearing in the message
+func Errorf(sentinel error, format string, args ...any) error {
+	return &categorizedError{sentinel: sentinel, err: fmt.Errorf(format, args...)}
+}
+
+type categorizedError struct {
+	sentinel error
+	err      error
+}
+
+func (e *categorizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *categorizedError) Unwrap() []error {
+	return []error{e.sentinel, e.err}
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// The helpers below let a check run against either the OS filesystem or an io/fs.FS (embed.FS, fstest.MapFS,
+// os.DirFS). A nil fsys always means the OS filesystem and paths are used as given; otherwise name must be a valid
+// fs.FS path (slash-separated, unrooted).
+
+// StatFS is os.Stat when fsys is nil and fs.Stat(fsys, name) otherwise
+func StatFS(fsys fs.FS, name string) (fs.FileInfo, error) {
+	if fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(fsys, name)
+}
+
+// OpenFS is os.Open when fsys is nil and fsys.Open(name) otherwise
+func OpenFS(fsys fs.FS, name string) (fs.File, error) {
+	if fsys == nil {
+		return os.Open(name)
+	}
+	return fsys.Open(name)
+}
+
+// ReadFileFS is os.ReadFile when fsys is nil and fs.ReadFile(fsys, name) otherwise
+func ReadFileFS(fsys fs.FS, name string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(fsys, name)
+}
+
+// JoinFS is filepath.Join when fsys is nil and path.Join otherwise, since fs.FS paths always use forward slashes
+func JoinFS(fsys fs.FS, elem ...string) string {
+	if fsys == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// ReadDirFS is os.ReadDir when fsys is nil and fs.ReadDir(fsys, name) otherwise
+func ReadDirFS(fsys fs.FS, name string) ([]fs.DirEntry, error) {
+	if fsys == nil {
+		return os.ReadDir(name)
+	}
+	return fs.ReadDir(fsys, name)
+}
+
+// WalkDirFS is filepath.WalkDir when fsys is nil and fs.WalkDir(fsys, root, fn) otherwise; neither follows symlinks
+func WalkDirFS(fsys fs.FS, root string, fn fs.WalkDirFunc) error {
+	if fsys == nil {
+		return filepath.WalkDir(root, fn)
+	}
+	return fs.WalkDir(fsys, root, fn)
+}
+
+// WalkDirFSWith is WalkDirFS honoring the MaxDepth and SkipHidden of opts; FollowSymlinks is ignored. Entries the
+// options leave out never reach fn.
+func WalkDirFSWith(fsys fs.FS, root string, opts WalkOptions, fn fs.WalkDirFunc) error {
+	return WalkDirFS(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(name, d, err)
+		}
+		visit, descend := opts.filter(d.Name(), walkDepth(fsys, root, name))
+		if !visit {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if err := fn(name, d, nil); err != nil {
+			return err
+		}
+		if d.IsDir() && !descend {
+			return fs.SkipDir
+		}
+		return nil
+	})
+}
+
+// walkDepth returns how many levels name, as passed to a WalkDirFS callback, is below root
+func walkDepth(fsys fs.FS, root, name string) int {
+	if name == root {
+		return 0
+	}
+	var rel string
+	switch {
+	case fsys == nil:
+		rel, _ = filepath.Rel(root, name)
+		rel = filepath.ToSlash(rel)
+	case root == ".":
+		rel = name
+	default:
+		rel = strings.TrimPrefix(name, root+"/")
+	}
+	if rel == "." {
+		return 0
+	}
+	return strings.Count(rel, "/") + 1
+}
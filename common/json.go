@@ -0,0 +1,55 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// OctalMode is an os.FileMode that marshals to JSON as an octal string such as "0644", the way modes are written in
+// policy files, instead of the decimal number encoding/json would produce
+type OctalMode os.FileMode
+
+// MarshalJSON encodes m as a zero-padded octal string
+func (m OctalMode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%04o", uint32(m)))
+}
+
+// UnmarshalJSON decodes an octal string such as "0644" or "644", wrapping ErrInvalidOptions for anything else
+func (m *OctalMode) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: file mode %s must be an octal string such as \"0644\"", ErrInvalidOptions, data)
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return fmt.Errorf("%w: file mode %q is not an octal number such as \"0644\"", ErrInvalidOptions, s)
+	}
+	*m = OctalMode(mode)
+	return nil
+}
+
+// Duration is a time.Duration that marshals to JSON as a string such as "1h30m", as accepted by time.ParseDuration,
+// instead of a number of nanoseconds
+type Duration time.Duration
+
+// MarshalJSON encodes d with time.Duration.String
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a time.ParseDuration string such as "90s", wrapping ErrInvalidOptions for anything else
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: duration %s must be a string such as \"1h30m\"", ErrInvalidOptions, data)
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%w: duration %q is not valid, want a string such as \"1h30m\"", ErrInvalidOptions, s)
+	}
+	*d = Duration(duration)
+	return nil
+}
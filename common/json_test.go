@@ -0,0 +1,55 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOctalMode(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    OctalMode
+		wantErr bool
+	}{
+		{`"0644"`, 0644, false},
+		{`"755"`, 0755, false},
+		{`"0"`, 0, false},
+		{`"0o644"`, 0, true},
+		{`"0888"`, 0, true},
+		{`420`, 0, true},
+		{`""`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			var got OctalMode
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("Unmarshal(%s) = %o, %v, want %o, wantErr %v", tt.data, got, err, tt.want, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Unmarshal(%s) error = %v, want ErrInvalidOptions", tt.data, err)
+			}
+		})
+	}
+	if data, err := json.Marshal(OctalMode(0640)); err != nil || string(data) != `"0640"` {
+		t.Errorf("Marshal(0640) = %s, %v, want \"0640\"", data, err)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	data, err := json.Marshal(Duration(90 * time.Minute))
+	if err != nil || string(data) != `"1h30m0s"` {
+		t.Errorf("Marshal(90m) = %s, %v, want \"1h30m0s\"", data, err)
+	}
+	var got Duration
+	if err := json.Unmarshal(data, &got); err != nil || got != Duration(90*time.Minute) {
+		t.Errorf("Unmarshal(%s) = %v, %v, want 90m", data, got, err)
+	}
+	for _, bad := range []string{`"90 minutes"`, `5400`} {
+		if err := json.Unmarshal([]byte(bad), &got); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Unmarshal(%s) error = %v, want ErrInvalidOptions", bad, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"fmt"
+	"os"
+)
+
+// FileLock opens the file at path, creating it with mode 0600 when missing, and blocks until the process holds an
+// exclusive advisory lock on it: flock(2) on unix, LockFileEx on Windows. The lock only keeps out other callers of
+// FileLock on the same path, in this process or any other; it does not stop anyone from opening the file. Release it
+// with Unlock. The file is never removed, since deleting a lock file lets a waiter lock a file no newcomer will see.
+func FileLock(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Unlock releases the lock FileLock took on f and closes it
+func Unlock(f *os.File) error {
+	if err := unlockFile(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to unlock %s: %w", f.Name(), err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,18 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+
+package common
+
+import (
+	"fmt"
+	"os"
+)
+
+// lockFile always returns ErrLockUnsupported on this platform, where the syscall package has no flock
+func lockFile(f *os.File) error {
+	return fmt.Errorf("%w on this platform: %s", ErrLockUnsupported, f.Name())
+}
+
+// unlockFile does nothing, lockFile never succeeds here
+func unlockFile(*os.File) error {
+	return nil
+}
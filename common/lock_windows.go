@@ -0,0 +1,38 @@
+//go:build windows
+
+package common
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// lockFileEx and unlockFileEx are LockFileEx and UnlockFileEx from kernel32, which the syscall package does not wrap
+var (
+	lockFileEx   = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+	unlockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("UnlockFileEx")
+)
+
+// lockfileExclusiveLock is LOCKFILE_EXCLUSIVE_LOCK; without LOCKFILE_FAIL_IMMEDIATELY, LockFileEx waits for the lock
+const lockfileExclusiveLock = 0x2
+
+// lockFile blocks until LockFileEx grants an exclusive lock on the first byte of f
+func lockFile(f *os.File) error {
+	var overlapped syscall.Overlapped
+	ok, _, err := lockFileEx.Call(f.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if ok == 0 {
+		return err
+	}
+	return nil
+}
+
+// unlockFile releases the LockFileEx lock on f
+func unlockFile(f *os.File) error {
+	var overlapped syscall.Overlapped
+	ok, _, err := unlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&overlapped)))
+	if ok == 0 {
+		return err
+	}
+	return nil
+}
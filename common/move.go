@@ -0,0 +1,120 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// rename is os.Rename, tests replace it to simulate a move across filesystems
+var rename = os.Rename
+
+// MoveAll moves the file or directory tree at src to dst with os.Rename, falling back to CopyAll followed by removing
+// src when they are on different filesystems
+func MoveAll(src, dst string) error {
+	err := rename(src, dst)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := CopyAll(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return fmt.Errorf("failed to remove %s after copying it to %s: %w", src, dst, err)
+	}
+	return nil
+}
+
+// CopyAll copies the file or directory tree at src to dst, which must not exist yet, keeping permission bits and
+// modification times. Symlinks are copied as symlinks rather than followed.
+func CopyAll(src, dst string) error {
+	type copiedDir struct {
+		path string
+		info fs.FileInfo
+	}
+	var dirs []copiedDir
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk %s: %w", path, err)
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		switch {
+		case d.IsDir():
+			// the owner bits are widened so the copy can be filled in, the real mode and times are applied after
+			if err := os.Mkdir(target, info.Mode().Perm()|0700); err != nil {
+				return fmt.Errorf("failed to create %s: %w", target, err)
+			}
+			dirs = append(dirs, copiedDir{target, info})
+			return nil
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %s: %w", path, err)
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", target, err)
+			}
+			return nil
+		case d.Type().IsRegular():
+			if err := copyFile(path, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("cannot copy %s: unsupported file type %s", path, d.Type())
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+	if err != nil {
+		return err
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dir := dirs[i]
+		if err := os.Chmod(dir.path, dir.info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to chmod %s: %w", dir.path, err)
+		}
+		if err := os.Chtimes(dir.path, dir.info.ModTime(), dir.info.ModTime()); err != nil {
+			return fmt.Errorf("failed to set times on %s: %w", dir.path, err)
+		}
+	}
+	return nil
+}
+
+// copyFile copies the regular file src to the new file dst with mode perm
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", dst, err)
+	}
+	return nil
+}
+
+// BackupPath returns a new path inside dir for a backup of path, its base name suffixed with the current UTC time
+func BackupPath(dir, path string) string {
+	return filepath.Join(dir, filepath.Base(path)+"."+time.Now().UTC().Format("20060102T150405.000000000Z"))
+}
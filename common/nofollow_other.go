@@ -0,0 +1,6 @@
+//go:build !unix
+
+package common
+
+// ONoFollow is 0 here, where the platform has no O_NOFOLLOW; see the unix definition
+const ONoFollow = 0
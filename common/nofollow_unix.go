@@ -0,0 +1,9 @@
+//go:build unix
+
+package common
+
+import "syscall"
+
+// ONoFollow is O_NOFOLLOW: OR it into the flags of os.OpenFile to fail rather than open through a symlink at the
+// last component of the path. It is 0 where the platform has no such flag.
+const ONoFollow = syscall.O_NOFOLLOW
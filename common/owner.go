@@ -0,0 +1,75 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+)
+
+// OwnerCache memoizes the numeric owner and group of Path and their resolved names, so a single run of checks stats
+// the path and consults the user database at most once each, no matter how many owner/group checks are enabled
+type OwnerCache struct {
+	Path string
+	Info os.FileInfo // Info, when set, is used instead of stat'ing Path again
+
+	uid, gid  string
+	idErr     error
+	idsDone   bool
+	owner     string
+	ownerErr  error
+	ownerDone bool
+	group     string
+	groupErr  error
+	groupDone bool
+}
+
+// IDs returns the numeric owner UID and group GID of Path as strings, see GetOwnerAndGroup
+func (c *OwnerCache) IDs() (uid, gid string, err error) {
+	if !c.idsDone {
+		if c.Info != nil {
+			c.uid, c.gid, c.idErr = GetOwnerAndGroupInfo(c.Info)
+		} else {
+			c.uid, c.gid, c.idErr = GetOwnerAndGroup(c.Path)
+		}
+		c.idsDone = true
+	}
+	return c.uid, c.gid, c.idErr
+}
+
+// OwnerName resolves the owner UID of Path to a user name with os/user.LookupId
+func (c *OwnerCache) OwnerName() (string, error) {
+	if !c.ownerDone {
+		c.owner, c.ownerErr = c.lookup(func(uid, _ string) (string, error) {
+			u, err := user.LookupId(uid)
+			if err != nil {
+				return "", fmt.Errorf("failed to look up user %s: %w", uid, err)
+			}
+			return u.Username, nil
+		})
+		c.ownerDone = true
+	}
+	return c.owner, c.ownerErr
+}
+
+// GroupName resolves the group GID of Path to a group name with os/user.LookupGroupId
+func (c *OwnerCache) GroupName() (string, error) {
+	if !c.groupDone {
+		c.group, c.groupErr = c.lookup(func(_, gid string) (string, error) {
+			g, err := user.LookupGroupId(gid)
+			if err != nil {
+				return "", fmt.Errorf("failed to look up group %s: %w", gid, err)
+			}
+			return g.Name, nil
+		})
+		c.groupDone = true
+	}
+	return c.group, c.groupErr
+}
+
+func (c *OwnerCache) lookup(resolve func(uid, gid string) (string, error)) (string, error) {
+	uid, gid, err := c.IDs()
+	if err != nil {
+		return "", err
+	}
+	return resolve(uid, gid)
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestOwnerCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("owner and group are not supported on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "owned.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cache := &OwnerCache{Path: path}
+	uid, gid, err := cache.IDs()
+	if err != nil {
+		t.Fatalf("IDs() error = %v", err)
+	}
+	// the result is memoized, so it must survive the file disappearing
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove test file: %v", err)
+	}
+	if uid2, gid2, err := cache.IDs(); err != nil || uid2 != uid || gid2 != gid {
+		t.Errorf("IDs() second call = %s, %s, %v; want cached %s, %s", uid2, gid2, err, uid, gid)
+	}
+	if _, err := cache.OwnerName(); err != nil {
+		t.Skipf("cannot resolve uid %s: %v", uid, err)
+	}
+}
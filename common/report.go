@@ -0,0 +1,53 @@
+package common
+
+import "os"
+
+// CheckResult is the outcome of a single configured check in a Report
+type CheckResult struct {
+	Name   string // Name is the Options field the check belongs to
+	Passed bool
+	Detail string // Detail is the failure message, empty when the check passed
+}
+
+// Report describes a path and every check configured for it, passed or failed, for callers that render a checklist
+// rather than only reporting the first error
+type Report struct {
+	Path   string
+	Info   os.FileInfo
+	UID    string // UID is the numeric owner, empty when it could not be read
+	GID    string // GID is the numeric group, empty when it could not be read
+	Owner  string // Owner is the owner user name, empty when it could not be resolved
+	Group  string // Group is the group name, empty when it could not be resolved
+	Checks []CheckResult
+}
+
+// Passed reports whether every check in the report passed
+func (r *Report) Passed() bool {
+	for _, c := range r.Checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the checks that did not pass, or nil when every check passed
+func (r *Report) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// NewReport builds the Report of path once its checks have run, resolving the owner and group through cache so lookups
+// the owner checks already made are not repeated
+func NewReport(path string, info os.FileInfo, cache *OwnerCache, checks []CheckResult) *Report {
+	r := &Report{Path: path, Info: info, Checks: checks}
+	r.UID, r.GID, _ = cache.IDs()
+	r.Owner, _ = cache.OwnerName()
+	r.Group, _ = cache.GroupName()
+	return r
+}
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"context"
+	"errors"
+)
+
+// Code is a stable, machine-readable identifier for why a check failed. Codes never change once released, so tooling
+// can switch on them instead of matching error messages.
+type Code string
+
+const (
+	CodeInvalidOptions     Code = "invalid_options"
+	CodeDoesNotExist       Code = "does_not_exist"
+	CodeAlreadyExists      Code = "already_exists"
+	CodeNotRegularFile     Code = "not_regular_file"
+	CodeNotDirectory       Code = "not_directory"
+	CodeSizeMismatch       Code = "size_mismatch"
+	CodeTimeMismatch       Code = "time_mismatch"
+	CodeNameMismatch       Code = "name_mismatch"
+	CodeBadBaseDir         Code = "bad_base_dir"
+	CodeSymlinkMismatch    Code = "symlink_mismatch"
+	CodePermissionMismatch Code = "permission_mismatch"
+	CodeOwnerMismatch      Code = "owner_mismatch"
+	CodeGroupMismatch      Code = "group_mismatch"
+	CodeContentMismatch    Code = "content_mismatch"
+	CodeIdentityMismatch   Code = "identity_mismatch"
+	CodeUnsupported        Code = "unsupported"
+	CodeCanceled           Code = "canceled"
+	CodeUnknown            Code = "unknown"
+)
+
+// codes maps each sentinel to its Code, in the order CodeOf tries them
+var codes = []struct {
+	sentinel error
+	code     Code
+}{
+	{ErrInvalidOptions, CodeInvalidOptions},
+	{ErrDoesNotExist, CodeDoesNotExist},
+	{ErrAlreadyExists, CodeAlreadyExists},
+	{ErrNotRegularFile, CodeNotRegularFile},
+	{ErrNotDirectory, CodeNotDirectory},
+	{ErrSizeMismatch, CodeSizeMismatch},
+	{ErrTimeMismatch, CodeTimeMismatch},
+	{ErrNameMismatch, CodeNameMismatch},
+	{ErrBadBaseDir, CodeBadBaseDir},
+	{ErrSymlinkMismatch, CodeSymlinkMismatch},
+	{ErrPermissionMismatch, CodePermissionMismatch},
+	{ErrOwnerMismatch, CodeOwnerMismatch},
+	{ErrGroupMismatch, CodeGroupMismatch},
+	{ErrContentMismatch, CodeContentMismatch},
+	{ErrIdentityMismatch, CodeIdentityMismatch},
+	{ErrSymlinkLoop, CodeSymlinkMismatch},
+	{ErrUnsupportedFS, CodeUnsupported},
+	{ErrBirthTimeUnsupported, CodeUnsupported},
+	{ErrAccessTimeUnsupported, CodeUnsupported},
+	{ErrFreeSpaceUnsupported, CodeUnsupported},
+	{ErrLockUnsupported, CodeUnsupported},
+	{context.Canceled, CodeCanceled},
+	{context.DeadlineExceeded, CodeCanceled},
+}
+
+// CodeOf returns the Code of the first sentinel err matches with errors.Is, or CodeUnknown
+func CodeOf(err error) Code {
+	for _, c := range codes {
+		if errors.Is(err, c.sentinel) {
+			return c.code
+		}
+	}
+	return CodeUnknown
+}
+
+// Failure is a check error together with the Options field whose check produced it
+type Failure struct {
+	Field string // Field is the Options field name, empty when the failure is not tied to one (invalid Options, stat)
+	Err   error
+}
+
+// Errors returns the error of every failure, or nil when there are none
+func Errors(failures []Failure) []error {
+	if len(failures) == 0 {
+		return nil
+	}
+	errs := make([]error, len(failures))
+	for i, f := range failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// Violation describes a single failed check in a Result
+type Violation struct {
+	Code    Code   // Code is the stable category of the failure
+	Field   string // Field is the Options field that failed, empty when the failure is not tied to one
+	Message string // Message is the error text
+	Path    string // Path is the path that was checked
+}
+
+// Result is the structured outcome of checking a path with every check enabled
+type Result struct {
+	Passed     bool
+	Violations []Violation
+}
+
+// NewResult builds the Result of checking path from the failures of the run
+func NewResult(path string, failures []Failure) Result {
+	result := Result{Passed: len(failures) == 0}
+	for _, f := range failures {
+		result.Violations = append(result.Violations, Violation{
+			Code:    CodeOf(f.Err),
+			Field:   f.Field,
+			Message: f.Err.Error(),
+			Path:    path,
+		})
+	}
+	return result
+}
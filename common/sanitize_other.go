@@ -0,0 +1,8 @@
+//go:build !windows
+
+package common
+
+// stripExtendedPrefix returns path unchanged; extended-length \\?\ paths only exist on Windows
+func stripExtendedPrefix(path string) string {
+	return path
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package common
+
+import "strings"
+
+// stripExtendedPrefix turns an extended-length path back into its ordinary form: \\?\C:\dir becomes C:\dir and
+// \\?\UNC\server\share becomes \\server\share, so both spellings of a path compare equal after cleaning
+func stripExtendedPrefix(path string) string {
+	const prefix = `\\?\`
+	if !strings.HasPrefix(path, prefix) {
+		return path
+	}
+	rest := path[len(prefix):]
+	if len(rest) >= 4 && strings.EqualFold(rest[:4], `UNC\`) {
+		return `\\` + rest[4:]
+	}
+	return rest
+}
@@ -0,0 +1,51 @@
+//go:build windows
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSanitizePathWindows(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"Extended drive path", `\\?\C:\dir\..\file.txt`, `C:\file.txt`},
+		{"Extended UNC path", `\\?\UNC\server\share\dir\file.txt`, `\\server\share\dir\file.txt`},
+		{"Extended UNC lower case", `\\?\unc\server\share\file.txt`, `\\server\share\file.txt`},
+		{"UNC path", `\\server\share\dir\\file.txt`, `\\server\share\dir\file.txt`},
+		{"Forward slashes", `C:/dir//sub/../file.txt`, `C:\dir\file.txt`},
+		{"Drive relative", `C:dir\..\file.txt`, `C:file.txt`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SanitizePath(tt.path)
+			if err != nil {
+				t.Fatalf("SanitizePath() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SanitizePath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Drive relative absolute", func(t *testing.T) {
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		drive := filepath.VolumeName(wd)
+		got, err := SanitizePathWith(drive+"file.txt", SanitizeOptions{Absolute: true})
+		if err != nil {
+			t.Fatalf("SanitizePathWith() error = %v", err)
+		}
+		if want := filepath.Join(wd, "file.txt"); !strings.EqualFold(got, want) {
+			t.Errorf("SanitizePathWith() = %q, want %q", got, want)
+		}
+	})
+}
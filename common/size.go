@@ -0,0 +1,97 @@
+package common
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// sizeUnits maps the accepted unit suffixes to their multiplier, 1024-based to match the file.KB constants
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1 << 10,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1 << 20,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1 << 30,
+	"GIB": 1 << 30,
+	"T":   1 << 40,
+	"TB":  1 << 40,
+	"TIB": 1 << 40,
+}
+
+// decimalSizeUnits is sizeUnits with the SI suffixes (K, KB, M, MB, ...) 1000-based; the IEC suffixes (KiB, MiB, ...)
+// stay 1024-based
+var decimalSizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"K":   1e3,
+	"KB":  1e3,
+	"KIB": 1 << 10,
+	"M":   1e6,
+	"MB":  1e6,
+	"MIB": 1 << 20,
+	"G":   1e9,
+	"GB":  1e9,
+	"GIB": 1 << 30,
+	"T":   1e12,
+	"TB":  1e12,
+	"TIB": 1 << 40,
+}
+
+// ParseSize parses a byte count such as "1024", "512KB", "1.5GB" or "10 MiB" into bytes. Units are case-insensitive and
+// 1024-based; a plain number is a number of bytes. Fractions are allowed and the result is rounded down to whole bytes.
+func ParseSize(s string) (int64, error) {
+	return parseSize(s, sizeUnits)
+}
+
+// ParseDecimalSize is ParseSize with 1000-based SI units, so "1.5GB" is 1500000000 bytes; the IEC units KiB, MiB, GiB
+// and TiB remain 1024-based
+func ParseDecimalSize(s string) (int64, error) {
+	return parseSize(s, decimalSizeUnits)
+}
+
+func parseSize(s string, units map[string]int64) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return 0, fmt.Errorf("size cannot be empty")
+	}
+	split := len(trimmed)
+	digits, dots := 0, 0
+	for i, r := range trimmed {
+		if r == '.' {
+			dots++
+			continue
+		}
+		if r < '0' || r > '9' {
+			split = i
+			break
+		}
+		digits++
+	}
+	number, unit := trimmed[:split], strings.ToUpper(strings.TrimSpace(trimmed[split:]))
+	if digits == 0 {
+		return 0, fmt.Errorf("invalid size %q: missing number", s)
+	}
+	if dots > 1 {
+		return 0, fmt.Errorf("invalid size %q: malformed number %q", s, number)
+	}
+	multiplier, ok := units[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, unit)
+	}
+	n, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: malformed number %q", s, number)
+	}
+	n.Mul(n, new(big.Rat).SetInt64(multiplier))
+	bytes := new(big.Int).Quo(n.Num(), n.Denom())
+	if !bytes.IsInt64() {
+		return 0, fmt.Errorf("invalid size %q: overflows int64", s)
+	}
+	return bytes.Int64(), nil
+}
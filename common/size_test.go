@@ -0,0 +1,72 @@
+package common
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"Plain bytes", "1024", 1024, false},
+		{"Bytes unit", "12B", 12, false},
+		{"Kilobytes", "512KB", 512 << 10, false},
+		{"Lowercase unit", "512kb", 512 << 10, false},
+		{"Short unit", "2M", 2 << 20, false},
+		{"IEC unit", "10 MiB", 10 << 20, false},
+		{"Gigabytes", "3GB", 3 << 30, false},
+		{"Terabytes", "1TB", 1 << 40, false},
+		{"Surrounding whitespace", " 7 \n", 7, false},
+		{"Fractional", "1.5GB", 3 << 29, false},
+		{"Fractional without leading digit", ".5KB", 512, false},
+		{"Fractional bytes round down", "0.1KB", 102, false},
+		{"Fractional missing unit", "2.9", 2, false},
+		{"Empty", "", 0, true},
+		{"Missing number", "MB", 0, true},
+		{"Lone dot", ".MB", 0, true},
+		{"Two dots", "1.2.3MB", 0, true},
+		{"Exponent", "1e3", 0, true},
+		{"Unknown unit", "5XB", 0, true},
+		{"Negative", "-5", 0, true},
+		{"Overflow", "9999999999TB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDecimalSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"512KB", 512000, false},
+		{"1.5GB", 1500000000, false},
+		{"10M", 10000000, false},
+		{"2TB", 2000000000000, false},
+		{"10 MiB", 10 << 20, false},
+		{"1.5GiB", 3 << 29, false},
+		{"5XB", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDecimalSize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseDecimalSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseDecimalSize(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StatCache remembers successful os.Stat results keyed by cleaned absolute path so batch runs over many paths that
+// share parents stat each of them once. Failed stats are never cached. Cached results go stale as soon as the
+// filesystem changes, so a StatCache is meant for short-lived batch runs and should be Reset (or dropped) between
+// them. It is safe for concurrent use.
+type StatCache struct {
+	mu         sync.RWMutex
+	entries    map[string]fs.FileInfo
+	maxEntries int
+}
+
+// NewStatCache returns an empty StatCache holding at most maxEntries results, or any number when maxEntries is 0
+func NewStatCache(maxEntries int) *StatCache {
+	return &StatCache{entries: make(map[string]fs.FileInfo), maxEntries: maxEntries}
+}
+
+// Stat is os.Stat served from the cache when name was stat'd successfully before
+func (c *StatCache) Stat(name string) (fs.FileInfo, error) {
+	key, err := filepath.Abs(name)
+	if err != nil {
+		return os.Stat(name)
+	}
+	c.mu.RLock()
+	info, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		return info, nil
+	}
+	info, err = os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.maxEntries == 0 || len(c.entries) < c.maxEntries {
+		c.entries[key] = info
+	}
+	c.mu.Unlock()
+	return info, nil
+}
+
+// Len returns the number of cached results
+func (c *StatCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// Reset drops every cached result
+func (c *StatCache) Reset() {
+	c.mu.Lock()
+	c.entries = make(map[string]fs.FileInfo)
+	c.mu.Unlock()
+}
+
+type statCacheKey struct{}
+
+// WithStatCache returns a copy of ctx carrying cache, which the checks then use for OS filesystem stats
+func WithStatCache(ctx context.Context, cache *StatCache) context.Context {
+	return context.WithValue(ctx, statCacheKey{}, cache)
+}
+
+// StatContext is StatFS that, for the OS filesystem, goes through the StatCache carried by ctx when there is one
+func StatContext(ctx context.Context, fsys fs.FS, name string) (fs.FileInfo, error) {
+	if fsys == nil {
+		if cache, ok := ctx.Value(statCacheKey{}).(*StatCache); ok && cache != nil {
+			return cache.Stat(name)
+		}
+	}
+	return StatFS(fsys, name)
+}
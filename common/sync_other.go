@@ -0,0 +1,9 @@
+//go:build !unix
+
+package common
+
+// SyncDir does nothing where directories cannot be opened and fsync'd, such as Windows; directory entries are made
+// durable by the filesystem itself there
+func SyncDir(dir string) error {
+	return nil
+}
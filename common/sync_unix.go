@@ -0,0 +1,24 @@
+//go:build unix
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// SyncDir fsyncs the directory dir so entries just created or renamed in it survive a crash. Filesystems that cannot
+// sync a directory (EINVAL, ENOTSUP) are skipped rather than reported.
+func SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", dir, err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+		return fmt.Errorf("failed to sync %s: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WalkOptions configures WalkSafeContext
+type WalkOptions struct {
+	// FollowSymlinks descends into symlinks to directories. A link back to a directory the walk is already inside fails
+	// with ErrSymlinkLoop; a directory reached a second time through another link is not walked again.
+	FollowSymlinks bool
+	// MaxDepth stops the walk from descending below this depth, counting root as depth 0, so 1 visits only the direct
+	// entries of root. 0 is unlimited.
+	MaxDepth int
+	// SkipHidden leaves out every entry whose name starts with a dot, and everything below it; root itself is always
+	// walked.
+	SkipHidden bool
+}
+
+// filter reports whether a walk with opts visits the entry name found at depth below root, and whether it may
+// descend into it when it is a directory
+func (opts WalkOptions) filter(name string, depth int) (visit, descend bool) {
+	if opts.SkipHidden && depth > 0 && strings.HasPrefix(name, ".") {
+		return false, false
+	}
+	return true, opts.MaxDepth <= 0 || depth < opts.MaxDepth
+}
+
+// WalkSafe calls fn for root and every entry below it in lexical order, like filepath.WalkDir, and never walks a
+// directory twice: directories are tracked by device and inode (by resolved path where inode numbers are not exposed),
+// so bind mounts and other cycles end instead of looping. Symlinks are passed to fn but not followed; root itself may
+// be a symlink to the directory. fn may return fs.SkipDir or fs.SkipAll with the same meaning as for filepath.WalkDir.
+func WalkSafe(root string, fn func(path string, d fs.DirEntry) error) error {
+	return WalkSafeContext(context.Background(), root, WalkOptions{}, fn)
+}
+
+// WalkSafeContext is WalkSafe with options, returning ctx.Err() as soon as ctx is done
+func WalkSafeContext(ctx context.Context, root string, opts WalkOptions, fn func(path string, d fs.DirEntry) error) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w := &walker{ctx: ctx, opts: opts, fn: fn, visited: make(map[string]bool), ancestors: make(map[string]string)}
+	err = fn(root, fs.FileInfoToDirEntry(info))
+	if err == nil && info.IsDir() {
+		err = w.walkDir(root, info, 0)
+	}
+	if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walker holds the state of one WalkSafeContext call. ancestors maps the key of every directory between root and the
+// one being read to its path, which is what tells a loop apart from a directory merely linked twice.
+type walker struct {
+	ctx       context.Context
+	opts      WalkOptions
+	fn        func(path string, d fs.DirEntry) error
+	visited   map[string]bool
+	ancestors map[string]string
+}
+
+// walkDir calls fn for the entries of the directory path at depth, whose own fn call has already happened, and
+// descends into the subdirectories neither fn nor the options skip
+func (w *walker) walkDir(path string, info fs.FileInfo, depth int) error {
+	key, err := dirKey(path, info)
+	if err != nil {
+		return fmt.Errorf("failed to identify %s: %w", path, err)
+	}
+	if ancestor, ok := w.ancestors[key]; ok {
+		if w.opts.FollowSymlinks {
+			return fmt.Errorf("%w: %s leads back to %s", ErrSymlinkLoop, path, ancestor)
+		}
+		return nil
+	}
+	if w.visited[key] {
+		return nil
+	}
+	w.visited[key] = true
+	w.ancestors[key] = path
+	defer delete(w.ancestors, key)
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fmt.Errorf("failed to walk %s: %w", path, err)
+	}
+	for _, entry := range entries {
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		visit, descend := w.opts.filter(entry.Name(), depth+1)
+		if !visit {
+			continue
+		}
+		child := filepath.Join(path, entry.Name())
+		d, dir, err := w.resolve(child, entry)
+		if err != nil {
+			return err
+		}
+		if err := w.fn(child, d); err != nil {
+			if !errors.Is(err, fs.SkipDir) {
+				return err
+			}
+			if dir == nil {
+				return nil
+			}
+			continue
+		}
+		if dir != nil && descend {
+			if err := w.walkDir(child, dir, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve returns the DirEntry to pass to fn for entry and, when the walk should descend into it, the FileInfo of the
+// directory. A followed symlink is reported as the directory it points to; broken links and links to anything else
+// are reported as links.
+func (w *walker) resolve(path string, entry fs.DirEntry) (fs.DirEntry, fs.FileInfo, error) {
+	switch {
+	case entry.IsDir():
+		info, err := entry.Info()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		return entry, info, nil
+	case entry.Type()&fs.ModeSymlink != 0 && w.opts.FollowSymlinks:
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return fs.FileInfoToDirEntry(info), info, nil
+		}
+	}
+	return entry, nil, nil
+}
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestWalkSafe(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+	root := t.TempDir()
+	for _, dir := range []string{"a/b", "shared"} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	for _, name := range []string{"a/b/file.txt", "shared/data.txt"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	// a/b/loop points back at a, so following it naively never ends
+	if err := os.Symlink(filepath.Join(root, "a"), filepath.Join(root, "a", "b", "loop")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	walk := func(opts WalkOptions, skip string) ([]string, error) {
+		var got []string
+		err := WalkSafeContext(context.Background(), root, opts, func(path string, d fs.DirEntry) error {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			got = append(got, filepath.ToSlash(rel))
+			if rel == skip {
+				return fs.SkipDir
+			}
+			return nil
+		})
+		return got, err
+	}
+
+	got, err := walk(WalkOptions{}, "")
+	want := []string{".", "a", "a/b", "a/b/file.txt", "a/b/loop", "shared", "shared/data.txt"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkSafe() visited %v, %v, want %v", got, err, want)
+	}
+
+	got, err = walk(WalkOptions{}, "a")
+	want = []string{".", "a", "shared", "shared/data.txt"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkSafe() skipping a visited %v, %v, want %v", got, err, want)
+	}
+
+	if _, err := walk(WalkOptions{FollowSymlinks: true}, ""); !errors.Is(err, ErrSymlinkLoop) || CodeOf(err) != CodeSymlinkMismatch {
+		t.Errorf("WalkSafe() following a symlink cycle error = %v, want ErrSymlinkLoop with CodeSymlinkMismatch", err)
+	}
+
+	// Without the cycle, a directory linked twice is walked once
+	if err := os.Remove(filepath.Join(root, "a", "b", "loop")); err != nil {
+		t.Fatalf("Failed to remove symlink: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, "shared"), filepath.Join(root, "a", "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	got, err = walk(WalkOptions{FollowSymlinks: true}, "")
+	want = []string{".", "a", "a/b", "a/b/file.txt", "a/link", "a/link/data.txt", "shared"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkSafe() following links visited %v, %v, want %v", got, err, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WalkSafeContext(ctx, root, WalkOptions{}, func(string, fs.DirEntry) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("WalkSafeContext() with a canceled context error = %v, want context.Canceled", err)
+	}
+	if err := WalkSafe(filepath.Join(root, "missing"), func(string, fs.DirEntry) error { return nil }); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("WalkSafe() of a missing root error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestWalkLimits(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "a", "b"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".cache"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	for _, name := range []string{".top", "top.txt", ".cache/x.txt", "a/.mid", "a/mid.txt", "a/b/.deep", "a/b/deep.txt"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts WalkOptions
+		want []string
+	}{
+		{"Unlimited", WalkOptions{},
+			[]string{".", ".cache", ".cache/x.txt", ".top", "a", "a/.mid", "a/b", "a/b/.deep", "a/b/deep.txt", "a/mid.txt", "top.txt"}},
+		{"Direct entries", WalkOptions{MaxDepth: 1}, []string{".", ".cache", ".top", "a", "top.txt"}},
+		{"Two levels", WalkOptions{MaxDepth: 2},
+			[]string{".", ".cache", ".cache/x.txt", ".top", "a", "a/.mid", "a/b", "a/mid.txt", "top.txt"}},
+		{"Skip hidden", WalkOptions{SkipHidden: true}, []string{".", "a", "a/b", "a/b/deep.txt", "a/mid.txt", "top.txt"}},
+		{"Both", WalkOptions{MaxDepth: 2, SkipHidden: true}, []string{".", "a", "a/b", "a/mid.txt", "top.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := WalkSafeContext(context.Background(), root, tt.opts, func(path string, d fs.DirEntry) error {
+				rel, err := filepath.Rel(root, path)
+				got = append(got, filepath.ToSlash(rel))
+				return err
+			})
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalkSafeContext() visited %v, %v, want %v", got, err, tt.want)
+			}
+
+			got = nil
+			err = WalkDirFSWith(nil, root, tt.opts, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(root, path)
+				got = append(got, filepath.ToSlash(rel))
+				return err
+			})
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalkDirFSWith(nil) visited %v, %v, want %v", got, err, tt.want)
+			}
+
+			got = nil
+			err = WalkDirFSWith(os.DirFS(root), ".", tt.opts, func(path string, d fs.DirEntry, err error) error {
+				got = append(got, path)
+				return err
+			})
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WalkDirFSWith(os.DirFS) visited %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
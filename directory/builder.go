@@ -0,0 +1,211 @@
+package directory
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// Builder assembles Options one chained call at a time, as sugar over an Options literal:
+//
+//	err := directory.Check(path).Exists().Owner("deploy").MaxEntries(100).Run()
+//
+// Every method sets the Options field its comment names and returns the Builder; With reaches any field without one.
+type Builder struct {
+	path string
+	opts Options
+}
+
+// Check starts a Builder for the directory at path
+func Check(path string) *Builder {
+	return &Builder{path: path}
+}
+
+// Exists sets Exists
+func (b *Builder) Exists() *Builder {
+	b.opts.Exists = true
+	return b
+}
+
+// Ext sets RequireExt
+func (b *Builder) Ext(ext string) *Builder {
+	b.opts.RequireExt = ext
+	return b
+}
+
+// Prefix sets RequirePrefix
+func (b *Builder) Prefix(prefix string) *Builder {
+	b.opts.RequirePrefix = prefix
+	return b
+}
+
+// Suffix sets RequireSuffix
+func (b *Builder) Suffix(suffix string) *Builder {
+	b.opts.RequireSuffix = suffix
+	return b
+}
+
+// Owner sets RequireOwnerName, e.g. "deploy"
+func (b *Builder) Owner(name string) *Builder {
+	b.opts.RequireOwnerName = name
+	return b
+}
+
+// Group sets RequireGroupName
+func (b *Builder) Group(name string) *Builder {
+	b.opts.RequireGroupName = name
+	return b
+}
+
+// OwnerUID sets RequireOwner, the numeric UID as a string
+func (b *Builder) OwnerUID(uid string) *Builder {
+	b.opts.RequireOwner = uid
+	return b
+}
+
+// GroupGID sets RequireGroup, the numeric GID as a string
+func (b *Builder) GroupGID(gid string) *Builder {
+	b.opts.RequireGroup = gid
+	return b
+}
+
+// BaseDir sets RequireBaseDir
+func (b *Builder) BaseDir(dir string) *Builder {
+	b.opts.RequireBaseDir = dir
+	return b
+}
+
+// Empty sets RequireEmpty
+func (b *Builder) Empty() *Builder {
+	b.opts.RequireEmpty = true
+	return b
+}
+
+// NonEmpty sets RequireNonEmpty
+func (b *Builder) NonEmpty() *Builder {
+	b.opts.RequireNonEmpty = true
+	return b
+}
+
+// Contains sets ContainsGlob, e.g. "*.pem"
+func (b *Builder) Contains(glob string) *Builder {
+	b.opts.ContainsGlob = glob
+	return b
+}
+
+// MinEntries sets MinEntries
+func (b *Builder) MinEntries(n int) *Builder {
+	b.opts.MinEntries = n
+	return b
+}
+
+// MaxEntries sets MaxEntries
+func (b *Builder) MaxEntries(n int) *Builder {
+	b.opts.MaxEntries = n
+	return b
+}
+
+// MinTotalSize sets MinTotalSize
+func (b *Builder) MinTotalSize(n int64) *Builder {
+	b.opts.MinTotalSize = n
+	return b
+}
+
+// MaxTotalSize sets MaxTotalSize
+func (b *Builder) MaxTotalSize(n int64) *Builder {
+	b.opts.MaxTotalSize = n
+	return b
+}
+
+// Recursive sets Recursive
+func (b *Builder) Recursive() *Builder {
+	b.opts.Recursive = true
+	return b
+}
+
+// MaxDepth sets MaxDepth
+func (b *Builder) MaxDepth(n int) *Builder {
+	b.opts.MaxDepth = n
+	return b
+}
+
+// SkipHidden sets SkipHidden
+func (b *Builder) SkipHidden() *Builder {
+	b.opts.SkipHidden = true
+	return b
+}
+
+// MorePermissiveThan sets MorePermissiveThan
+func (b *Builder) MorePermissiveThan(mode os.FileMode) *Builder {
+	b.opts.MorePermissiveThan = mode
+	return b
+}
+
+// LessPermissiveThan sets LessPermissiveThan
+func (b *Builder) LessPermissiveThan(mode os.FileMode) *Builder {
+	b.opts.LessPermissiveThan = mode
+	return b
+}
+
+// ReadOnly sets ReadOnly
+func (b *Builder) ReadOnly() *Builder {
+	b.opts.ReadOnly = true
+	return b
+}
+
+// Writable sets RequireWrite
+func (b *Builder) Writable() *Builder {
+	b.opts.RequireWrite = true
+	return b
+}
+
+// ModifiedBefore sets ModifiedBefore
+func (b *Builder) ModifiedBefore(t time.Time) *Builder {
+	b.opts.ModifiedBefore = t
+	return b
+}
+
+// ModifiedAfter sets ModifiedAfter
+func (b *Builder) ModifiedAfter(t time.Time) *Builder {
+	b.opts.ModifiedAfter = t
+	return b
+}
+
+// Create sets Create
+func (b *Builder) Create(create Create) *Builder {
+	b.opts.Create = create
+	return b
+}
+
+// With calls set with the Options being built, for fields that have no method of their own
+func (b *Builder) With(set func(opts *Options)) *Builder {
+	set(&b.opts)
+	return b
+}
+
+// Options returns the Options built so far
+func (b *Builder) Options() Options {
+	return b.opts
+}
+
+// Run performs the directory checks with the built Options, exactly as Directory does
+func (b *Builder) Run() error {
+	return Directory(b.path, b.opts)
+}
+
+// RunContext is Run with DirectoryContext
+func (b *Builder) RunContext(ctx context.Context) error {
+	return DirectoryContext(ctx, b.path, b.opts)
+}
+
+// All performs every check with the built Options like DirectoryAll
+func (b *Builder) All() []error {
+	return DirectoryAll(b.path, b.opts)
+}
+
+// Result performs every check with the built Options like DirectoryResult
+func (b *Builder) Result() common.Result {
+	return DirectoryResult(b.path, b.opts)
+}
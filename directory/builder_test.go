@@ -0,0 +1,64 @@
+package directory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	stamp := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    Options
+	}{
+		{"Entries", Check("/srv").Exists().Owner("deploy").MaxEntries(100).MinEntries(1).Contains("*.pem"),
+			Options{Exists: true, RequireOwnerName: "deploy", MaxEntries: 100, MinEntries: 1, ContainsGlob: "*.pem"}},
+		{"Names", Check("/srv/www-tmp").Ext(".d").Prefix("www").Suffix("-tmp").BaseDir("/srv"),
+			Options{RequireExt: ".d", RequirePrefix: "www", RequireSuffix: "-tmp", RequireBaseDir: "/srv"}},
+		{"Ownership", Check("/srv").OwnerUID("0").GroupGID("0").Group("wheel"),
+			Options{RequireOwner: "0", RequireGroup: "0", RequireGroupName: "wheel"}},
+		{"Sizes", Check("/srv").Recursive().NonEmpty().MinTotalSize(1).MaxTotalSize(1 << 30),
+			Options{Recursive: true, RequireNonEmpty: true, MinTotalSize: 1, MaxTotalSize: 1 << 30}},
+		{"Permissions", Check("/srv").MorePermissiveThan(0700).LessPermissiveThan(0755).Writable(),
+			Options{MorePermissiveThan: 0700, LessPermissiveThan: 0755, RequireWrite: true}},
+		{"Times", Check("/srv").ModifiedBefore(stamp).ModifiedAfter(stamp.Add(-time.Hour)),
+			Options{ModifiedBefore: stamp, ModifiedAfter: stamp.Add(-time.Hour)}},
+		{"With and Create", Check("/srv").ReadOnly().Empty().Create(Create{Kind: IfNotExists}).With(func(o *Options) { o.RequireIndexFile = "index.html" }),
+			Options{ReadOnly: true, RequireEmpty: true, Create: Create{Kind: IfNotExists}, RequireIndexFile: "index.html"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.builder.Options(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Options() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cert.pem"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := Check(dir).Exists().Contains("*.pem").MaxEntries(1).Run(); err != nil {
+		t.Errorf("Run() error = %v, want nil", err)
+	}
+
+	built := Check(dir).Exists().Contains("*.key").Prefix("www")
+	want := DirectoryAll(dir, Options{Exists: true, ContainsGlob: "*.key", RequirePrefix: "www"})
+	if got := built.All(); len(got) != 2 || !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+	err := built.Run()
+	if err == nil || err.Error() != Directory(dir, built.Options()).Error() {
+		t.Errorf("Run() error = %v, want the error Directory returns", err)
+	}
+	if result := built.Result(); result.Passed || len(result.Violations) != 2 {
+		t.Errorf("Result() = %+v, want 2 violations", result)
+	}
+}
@@ -0,0 +1,154 @@
+package directory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// DifferenceKind describes how a file on disk disagrees with a checksums manifest
+type DifferenceKind uint8
+
+const (
+	// Missing DifferenceKind means the manifest lists a file that is not on disk
+	Missing DifferenceKind = iota + 1
+
+	// Extra DifferenceKind means a file is on disk but absent from the manifest
+	Extra
+
+	// Mismatch DifferenceKind means the file hashes to a different digest than the manifest records
+	Mismatch
+)
+
+func (k DifferenceKind) String() string {
+	switch k {
+	case Missing:
+		return "missing"
+	case Extra:
+		return "extra"
+	case Mismatch:
+		return "mismatch"
+	default:
+		return fmt.Sprintf("DifferenceKind(%d)", uint8(k))
+	}
+}
+
+// Difference is a single disagreement between a directory tree and its checksums manifest
+type Difference struct {
+	Path     string         // Path is relative to the root, using forward slashes
+	Kind     DifferenceKind // Kind is Missing, Extra or Mismatch
+	Expected string         // Expected is the digest recorded in the manifest (empty for Extra)
+	Actual   string         // Actual is the digest computed from disk (empty for Missing)
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s", d.Kind, d.Path)
+}
+
+// VerifyChecksumsFile parses the coreutils-format SHA-256 manifest sumsFile (sha256sum output), hashes every file it
+// references under root and reports every Missing, Extra and Mismatch entry. Regular files found under root that the
+// manifest does not mention are reported as Extra; the manifest itself is never reported. When sumsFile is not an
+// absolute path it is resolved relative to root. A nil slice means the tree matches the manifest exactly.
+//
+// Example:
+//
+//	diffs, err := directory.VerifyChecksumsFile("/opt/release", "SHA256SUMS")
+func VerifyChecksumsFile(root, sumsFile string) ([]Difference, error) {
+	if !filepath.IsAbs(sumsFile) {
+		sumsFile = filepath.Join(root, sumsFile)
+	}
+	f, err := os.Open(sumsFile)
+	if err != nil {
+		if common.IsFDExhausted(err) {
+			return nil, fdExhausted(sumsFile, err)
+		}
+		return nil, fmt.Errorf("failed to open checksums file %s: %w", sumsFile, err)
+	}
+	sums, err := common.ParseChecksums(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse checksums file %s: %w", sumsFile, err)
+	}
+
+	var diffs []Difference
+	listed := make(map[string]bool, len(sums))
+	for _, sum := range sums {
+		rel := filepath.ToSlash(filepath.Clean(filepath.FromSlash(sum.Path)))
+		full := filepath.Join(root, filepath.FromSlash(rel))
+		inBase, err := common.IsPathInBase(full, root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check manifest entry %s: %w", sum.Path, err)
+		}
+		if !inBase {
+			return nil, &ErrCheckDirBadBaseDir{Path: full, BaseDir: root}
+		}
+		if listed[rel] {
+			continue
+		}
+		listed[rel] = true
+
+		info, err := os.Stat(full)
+		if err != nil {
+			if os.IsNotExist(err) {
+				diffs = append(diffs, Difference{Path: rel, Kind: Missing, Expected: sum.Digest})
+				continue
+			}
+			return nil, fmt.Errorf("failed to stat %s: %w", full, err)
+		}
+		if !info.Mode().IsRegular() {
+			diffs = append(diffs, Difference{Path: rel, Kind: Missing, Expected: sum.Digest})
+			continue
+		}
+		// every file is hashed and closed before the next one is opened so a large tree never holds more than one
+		// descriptor at a time
+		actual, err := common.SHA256File(full)
+		if err != nil {
+			return nil, fdExhausted(full, err)
+		}
+		if actual != sum.Digest {
+			diffs = append(diffs, Difference{Path: rel, Kind: Mismatch, Expected: sum.Digest, Actual: actual})
+		}
+	}
+
+	absSums, err := filepath.Abs(sumsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path of %s: %w", sumsFile, err)
+	}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fdExhausted(path, err)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if abs, err := filepath.Abs(path); err == nil && abs == absSums {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if listed[rel] {
+			return nil
+		}
+		actual, err := common.SHA256File(path)
+		if err != nil {
+			return fdExhausted(path, err)
+		}
+		diffs = append(diffs, Difference{Path: rel, Kind: Extra, Actual: actual})
+		return nil
+	})
+	if err != nil {
+		var fdErr *ErrCheckFDExhausted
+		if errors.As(err, &fdErr) {
+			return nil, fdErr
+		}
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+	return diffs, nil
+}
@@ -0,0 +1,81 @@
+//go:build linux || darwin || openbsd
+
+package directory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// highestOpenFD returns the largest file descriptor number currently open in the test process
+func highestOpenFD(t *testing.T) uint64 {
+	entries, err := os.ReadDir("/dev/fd")
+	if err != nil {
+		t.Skipf("cannot list open file descriptors: %v", err)
+	}
+	var highest uint64
+	for _, entry := range entries {
+		fd, err := strconv.ParseUint(entry.Name(), 10, 64)
+		if err == nil && fd > highest {
+			highest = fd
+		}
+	}
+	return highest
+}
+
+func TestVerifyChecksumsFileFDLimit(t *testing.T) {
+	root := t.TempDir()
+	var manifest strings.Builder
+	for i := 0; i < 64; i++ {
+		name := filepath.Join(fmt.Sprintf("d%d", i%4), fmt.Sprintf("f%02d.txt", i))
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create fixture directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create fixture file: %v", err)
+		}
+		fmt.Fprintf(&manifest, "%s  %s\n", sha256Hex(name), filepath.ToSlash(name))
+	}
+	if err := os.WriteFile(filepath.Join(root, "SHA256SUMS"), []byte(manifest.String()), 0644); err != nil {
+		t.Fatalf("Failed to create manifest: %v", err)
+	}
+
+	var original syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &original); err != nil {
+		t.Skipf("cannot read RLIMIT_NOFILE: %v", err)
+	}
+	defer syscall.Setrlimit(syscall.RLIMIT_NOFILE, &original)
+	setLimit := func(soft uint64) {
+		limit := original
+		limit.Cur = soft
+		if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+			t.Skipf("cannot lower RLIMIT_NOFILE: %v", err)
+		}
+	}
+
+	t.Run("Walk stays within a small limit", func(t *testing.T) {
+		setLimit(highestOpenFD(t) + 8)
+		diffs, err := VerifyChecksumsFile(root, "SHA256SUMS")
+		setLimit(original.Cur)
+		if err != nil || diffs != nil {
+			t.Errorf("VerifyChecksumsFile() = %v, %v; want no differences", diffs, err)
+		}
+	})
+
+	t.Run("Exhausted descriptors are reported clearly", func(t *testing.T) {
+		setLimit(1)
+		_, err := VerifyChecksumsFile(root, "SHA256SUMS")
+		setLimit(original.Cur)
+		var fdErr *ErrCheckFDExhausted
+		if !errors.As(err, &fdErr) {
+			t.Errorf("VerifyChecksumsFile() error = %v, want ErrCheckFDExhausted", err)
+		}
+	})
+}
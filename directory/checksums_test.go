@@ -0,0 +1,97 @@
+package directory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerifyChecksumsFile(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app.tar.gz":      "release payload",
+		"docs/README.txt": "read me",
+		"tampered.bin":    "tampered contents",
+		"unlisted.txt":    "not in the manifest",
+	}
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create fixture directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create fixture file: %v", err)
+		}
+	}
+
+	var manifest strings.Builder
+	fmt.Fprintf(&manifest, "%s  app.tar.gz\n", sha256Hex("release payload"))
+	fmt.Fprintf(&manifest, "%s *docs/README.txt\n", sha256Hex("read me"))
+	fmt.Fprintf(&manifest, "%s  tampered.bin\n", sha256Hex("original contents"))
+	fmt.Fprintf(&manifest, "%s  deleted.txt\n", sha256Hex("gone"))
+	if err := os.WriteFile(filepath.Join(root, "SHA256SUMS"), []byte(manifest.String()), 0644); err != nil {
+		t.Fatalf("Failed to create manifest: %v", err)
+	}
+
+	diffs, err := VerifyChecksumsFile(root, "SHA256SUMS")
+	if err != nil {
+		t.Fatalf("VerifyChecksumsFile() error = %v", err)
+	}
+	want := map[string]DifferenceKind{
+		"tampered.bin": Mismatch,
+		"deleted.txt":  Missing,
+		"unlisted.txt": Extra,
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("VerifyChecksumsFile() returned %d differences, want %d: %v", len(diffs), len(want), diffs)
+	}
+	for _, d := range diffs {
+		if kind, ok := want[d.Path]; !ok || kind != d.Kind {
+			t.Errorf("unexpected difference %v", d)
+		}
+	}
+
+	t.Run("Clean tree", func(t *testing.T) {
+		clean := t.TempDir()
+		if err := os.WriteFile(filepath.Join(clean, "a.txt"), []byte("a"), 0644); err != nil {
+			t.Fatalf("Failed to create fixture file: %v", err)
+		}
+		sums := filepath.Join(t.TempDir(), "SHA256SUMS")
+		if err := os.WriteFile(sums, []byte(sha256Hex("a")+"  ./a.txt\n"), 0644); err != nil {
+			t.Fatalf("Failed to create manifest: %v", err)
+		}
+		diffs, err := VerifyChecksumsFile(clean, sums)
+		if err != nil || diffs != nil {
+			t.Errorf("VerifyChecksumsFile() = %v, %v; want no differences", diffs, err)
+		}
+	})
+
+	t.Run("Malformed manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte("not-a-digest\n"), 0644); err != nil {
+			t.Fatalf("Failed to create manifest: %v", err)
+		}
+		if _, err := VerifyChecksumsFile(dir, "SHA256SUMS"); err == nil {
+			t.Error("VerifyChecksumsFile() should fail on a malformed manifest")
+		}
+	})
+
+	t.Run("Entry escaping root", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte(sha256Hex("x")+"  ../escape.txt\n"), 0644); err != nil {
+			t.Fatalf("Failed to create manifest: %v", err)
+		}
+		if _, err := VerifyChecksumsFile(dir, "SHA256SUMS"); err == nil {
+			t.Error("VerifyChecksumsFile() should reject entries outside of root")
+		}
+	})
+}
@@ -1,9 +1,14 @@
 package directory
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -25,86 +30,667 @@ const (
 	// Create.Kind is IfExists then checkfs will delete the path first, then create a new directory at the path in
 	// Create.Path
 	IfExists CreateKind = iota
+
+	// EnsureValid CreateKind creates the directory like IfNotExists when it is missing and otherwise converges the
+	// existing directory on the Create in place, never removing it. See Create.Run for the rules.
+	EnsureValid CreateKind = iota
 )
 
 // Create defines a New Directory that is a CreateKind (default NoAction), options include:
 // - IfNotExists
 // - IfExists
+// - EnsureValid
 // Properties in the Create struct dictate the runtime of the Create.Run() method
 type Create struct {
-	Kind     CreateKind  // Kind requires either CreateFileIfNotExists or IfNotExists CreateKind
-	FileMode os.FileMode // FileMode allows you to set os.ModePerm etc.
-	Path     string      // Path stores where the resource will be created
+	Kind      CreateKind  // Kind requires either CreateFileIfNotExists or IfNotExists CreateKind
+	FileMode  os.FileMode // FileMode allows you to set os.ModePerm etc.
+	Path      string      // Path stores where the resource will be created
+	ForceMode bool        // ForceMode chmods the final directory to exactly FileMode, bypassing the umask; parents still obey it
+	DryRun    bool        // DryRun makes Run only check that the create would succeed, see Plan
+	BackupDir string      // BackupDir receives the existing directory on IfExists instead of it being removed, see RunWithBackup
+
+	RequireBaseDir          string // RequireBaseDir refuses to touch a Path that escapes this directory, lexically or through symlinks
+	RejectSymlinkComponents bool   // RejectSymlinkComponents refuses to create through a parent directory of Path that is a symlink
+
+	// Sync fsyncs the parent of every directory the create made. POSIX only makes a new directory entry durable once
+	// its parent directory is fsync'd, so without it a crash can lose a directory the create reported as made.
+	// Platforms and filesystems that cannot sync a directory skip it without an error.
+	Sync bool
 }
 
 // NewCreate allows you to stack the .Run() call. Using NewCreate outside of its
 // use case of checkfs.Directory(path, directory.Options{}) performs validation
-// checks against
+// checks against the Create.Kind before anything is created
 //
 // Example:
 //
-//	err := directory.NewCreate(directory.Create{
-//		Kind: directory.IfNotExists,
-//		Path: "/opt/test/path",
+//	err := directory.NewCreate(&directory.Create{
+//		Kind:     directory.IfNotExists,
+//		Path:     "/opt/test/path",
 //		FileMode: 0755,
 //	}).Run()
 func NewCreate(create *Create) *Create {
-	return &Create{}
+	if create == nil {
+		return &Create{}
+	}
+	c := *create
+	return &c
 }
 
-// directory will consume a pointer to Create and apply the policy against the host
+// Sentinel errors usable with errors.Is to tell apart why Directory failed, see the common package for details
+var (
+	ErrInvalidOptions     = common.ErrInvalidOptions
+	ErrDoesNotExist       = common.ErrDoesNotExist
+	ErrAlreadyExists      = common.ErrAlreadyExists
+	ErrNotDirectory       = common.ErrNotDirectory
+	ErrTimeMismatch       = common.ErrTimeMismatch
+	ErrSizeMismatch       = common.ErrSizeMismatch
+	ErrNameMismatch       = common.ErrNameMismatch
+	ErrBadBaseDir         = common.ErrBadBaseDir
+	ErrPermissionMismatch = common.ErrPermissionMismatch
+	ErrOwnerMismatch      = common.ErrOwnerMismatch
+	ErrGroupMismatch      = common.ErrGroupMismatch
+	ErrContentMismatch    = common.ErrContentMismatch
+	ErrSymlinkMismatch    = common.ErrSymlinkMismatch
+	ErrUnsupportedFS      = common.ErrUnsupportedFS
+)
+
+var (
+	// ErrUnknownCreateKind is returned by Create.Run() when Kind is not one of the CreateKind constants
+	ErrUnknownCreateKind = errors.New("create kind not supported")
+
+	// ErrHTMLUnavailable is returned by the ValidateIndexHTML check unless checkfs is built with -tags checkfs_html
+	ErrHTMLUnavailable = errors.New("html validation not compiled in, build with -tags checkfs_html")
+)
+
+// directory will consume a pointer to Create and apply the policy against the host. os.MkdirAll is subject to the
+// process umask, so with ForceMode the final directory is chmod'd to FileMode afterwards, whether it was just created
+// or already existed; intermediate parents keep their umask-filtered mode.
 func (create *Create) directory() error {
 	_, err := os.Stat(create.Path)
-	if err != nil && os.IsNotExist(err) && create.Kind == IfNotExists {
-		return os.MkdirAll(create.Path, create.FileMode)
+	if err != nil {
+		if !os.IsNotExist(err) || create.Kind != IfNotExists {
+			return nil
+		}
+		top := topMissing(create.Path)
+		if err := mkdirAll(create.Path, create.FileMode); err != nil {
+			return err
+		}
+		if create.Sync {
+			if err := syncCreated(create.Path, top); err != nil {
+				return err
+			}
+		}
+	}
+	if create.ForceMode {
+		if err := os.Chmod(create.Path, create.FileMode); err != nil {
+			return fmt.Errorf("could not chmod directory: %w", err)
+		}
 	}
 	return nil
 }
 
-// replaceDirectory  will consume a pointer to Create an apply the policy against the host
-func (create *Create) replaceDirectory() error {
-	_, err := os.Stat(create.Path)
-	if (err == nil || os.IsExist(err)) && create.Kind == IfNotExists {
-		err := os.RemoveAll(create.Path)
-		if err != nil {
-			return fmt.Errorf("could not remove directory: %w", err)
+// topMissing returns the outermost ancestor of path, or path itself, that does not exist yet and MkdirAll will create
+func topMissing(path string) string {
+	top := filepath.Clean(path)
+	for {
+		parent := filepath.Dir(top)
+		if parent == top {
+			return top
+		}
+		if _, err := os.Stat(parent); err == nil || !os.IsNotExist(err) {
+			return top
+		}
+		top = parent
+	}
+}
+
+// syncDir is common.SyncDir, tests replace it to record which directories are synced
+var syncDir = common.SyncDir
+
+// syncCreated fsyncs the parent of path and of every directory above it up to top, the outermost one MkdirAll made
+func syncCreated(path, top string) error {
+	for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+		if err := syncDir(filepath.Dir(dir)); err != nil {
+			return fmt.Errorf("could not sync directory: %w", err)
+		}
+		if dir == top || filepath.Dir(dir) == dir {
+			return nil
 		}
 	}
-	return create.directory()
+}
+
+// mkdirAll is os.MkdirAll, tests replace it to simulate a failed create
+var mkdirAll = os.MkdirAll
+
+// replaceDirectory  will consume a pointer to Create an apply the policy against the host: an existing directory at
+// create.Path is removed along with everything in it, or moved into BackupDir, then created again
+func (create *Create) replaceDirectory() (backup string, err error) {
+	_, err = os.Stat(create.Path)
+	if (err == nil || os.IsExist(err)) && create.Kind == IfExists {
+		if create.BackupDir != "" {
+			if backup, err = create.backup(); err != nil {
+				return "", err
+			}
+		} else if err := os.RemoveAll(create.Path); err != nil {
+			return "", fmt.Errorf("could not remove directory: %w", err)
+		}
+	}
+	create.Kind = IfNotExists
+	if err := create.directory(); err != nil {
+		if backup != "" {
+			return "", restore(backup, create.Path, err)
+		}
+		return "", err
+	}
+	return backup, nil
+}
+
+// backup moves the existing directory into BackupDir
+func (create *Create) backup() (string, error) {
+	if err := os.MkdirAll(create.BackupDir, 0700); err != nil {
+		return "", fmt.Errorf("could not create backup directory: %w", err)
+	}
+	backup := common.BackupPath(create.BackupDir, create.Path)
+	if err := common.MoveAll(create.Path, backup); err != nil {
+		return "", fmt.Errorf("could not back up directory: %w", err)
+	}
+	return backup, nil
+}
+
+// restore puts backup back at path after the create that replaced it failed with cause
+func restore(backup, path string, cause error) error {
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("%w (could not remove it to restore %s: %w)", cause, backup, err)
+	}
+	if err := common.MoveAll(backup, path); err != nil {
+		return fmt.Errorf("%w (could not restore %s: %w)", cause, backup, err)
+	}
+	return cause
 }
 
 // Run will read the Create.Kind and switch between IfExists and IfNotExists to run either createDirectory or
-// replaceDirectory internally.
+// replaceDirectory internally. With DryRun set it only runs the checks of Plan and changes nothing.
+//
+// EnsureValid converges instead: a missing directory is created as IfNotExists would, then the directory at Path, new
+// or not, is brought in line with the Create without ever being removed or moved aside:
+//   - a path that exists but is not a directory fails with ErrNotDirectory and is left alone
+//   - when its permission bits differ from those of FileMode, it is chmod'd to them as ForceMode would, so the umask
+//     does not apply; its entries are never touched
 func (create *Create) Run() error {
+	_, err := create.RunWithBackup()
+	return err
+}
+
+// RunWithBackup is Run that also returns where BackupDir received the directory IfExists replaced, or "" when nothing
+// was backed up. The backup is moved rather than removed, and moved back if creating the replacement fails; cleaning
+// it up afterwards is up to the caller.
+func (create *Create) RunWithBackup() (backup string, err error) {
+	if create.DryRun {
+		_, err := create.Plan()
+		return "", err
+	}
+	if err := create.checkBaseDir(); err != nil {
+		return "", err
+	}
+	if err := create.checkSymlinkComponents(); err != nil {
+		return "", err
+	}
 	switch create.Kind {
 	case IfExists:
 		return create.replaceDirectory()
 	case IfNotExists:
-		return create.directory()
+		return "", create.directory()
+	case EnsureValid:
+		return "", create.ensure()
+	default:
+		return "", fmt.Errorf("%w: %v", ErrUnknownCreateKind, create.Kind)
+	}
+}
+
+// ensure carries out EnsureValid: a missing directory is created as IfNotExists would, then converge applies the rest
+func (create *Create) ensure() error {
+	if _, err := os.Stat(create.Path); os.IsNotExist(err) {
+		create.Kind = IfNotExists
+		err := create.directory()
+		create.Kind = EnsureValid
+		if err != nil {
+			return err
+		}
+	}
+	_, err := create.converge(true)
+	return err
+}
+
+// converge returns the changes EnsureValid makes to the existing directory at Path, making them when apply is true
+func (create *Create) converge(apply bool) ([]string, error) {
+	info, err := os.Stat(create.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat directory %s: %w", create.Path, err)
+	}
+	if !info.IsDir() {
+		return nil, common.Errorf(ErrNotDirectory, "not a directory: %s", create.Path)
+	}
+	if info.Mode().Perm() == create.FileMode.Perm() {
+		return nil, nil
+	}
+	if apply {
+		if err := os.Chmod(create.Path, create.FileMode.Perm()); err != nil {
+			return nil, fmt.Errorf("could not chmod directory: %w", err)
+		}
+	}
+	return []string{fmt.Sprintf("chmod directory %s to %s", create.Path, create.FileMode.Perm())}, nil
+}
+
+// Plan returns the actions Run would take, in order, without changing anything. It fails where Run would fail before
+// changing anything: an unknown Kind, a path that exists but is not a directory, or a nearest existing ancestor that
+// is not a writable directory. For EnsureValid and an existing directory it lists the changes converging would make,
+// none when the directory already matches.
+func (create *Create) Plan() ([]string, error) {
+	switch create.Kind {
+	case IfExists, IfNotExists, EnsureValid:
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrUnknownCreateKind, create.Kind)
+	}
+	if err := create.checkBaseDir(); err != nil {
+		return nil, err
+	}
+	if err := create.checkSymlinkComponents(); err != nil {
+		return nil, err
+	}
+	if create.Kind == EnsureValid {
+		if _, err := os.Stat(create.Path); !os.IsNotExist(err) {
+			return create.converge(false)
+		}
+	}
+	var plan []string
+	info, err := os.Stat(create.Path)
+	switch {
+	case err == nil && !info.IsDir():
+		return nil, common.Errorf(ErrNotDirectory, "not a directory: %s", create.Path)
+	case err == nil && create.Kind == IfExists:
+		if err := writableDir(filepath.Dir(create.Path)); err != nil {
+			return nil, err
+		}
+		if create.BackupDir != "" {
+			plan = append(plan, fmt.Sprintf("move directory %s into backup directory %s", create.Path, create.BackupDir))
+		} else {
+			plan = append(plan, fmt.Sprintf("remove directory %s and everything in it", create.Path))
+		}
+		plan = append(plan, fmt.Sprintf("create directory %s with mode %s", create.Path, create.FileMode))
+	case err == nil:
+	case os.IsNotExist(err):
+		ancestor := filepath.Dir(create.Path)
+		for {
+			if _, err := os.Stat(ancestor); err == nil || !os.IsNotExist(err) || filepath.Dir(ancestor) == ancestor {
+				break
+			}
+			ancestor = filepath.Dir(ancestor)
+		}
+		if err := writableDir(ancestor); err != nil {
+			return nil, err
+		}
+		plan = append(plan, fmt.Sprintf("create directory %s and any missing parents with mode %s", create.Path, create.FileMode))
 	default:
-		return fmt.Errorf("create kind not supported: %v", create.Kind)
+		return nil, fmt.Errorf("failed to stat directory %s: %w", create.Path, err)
+	}
+	if create.ForceMode {
+		plan = append(plan, fmt.Sprintf("chmod directory %s to %s", create.Path, create.FileMode))
+	}
+	return plan, nil
+}
+
+// checkBaseDir fails with ErrCheckDirBadBaseDir when RequireBaseDir is set and Path escapes it
+func (create *Create) checkBaseDir() error {
+	if create.RequireBaseDir == "" {
+		return nil
+	}
+	inBase, err := common.IsPathInBaseResolved(create.Path, create.RequireBaseDir)
+	if err != nil {
+		return fmt.Errorf("failed to check base directory: %w", err)
+	}
+	if !inBase {
+		return &ErrCheckDirBadBaseDir{Path: create.Path, BaseDir: create.RequireBaseDir}
+	}
+	return nil
+}
+
+// checkSymlinkComponents fails with ErrCheckSymlinkInPath when RejectSymlinkComponents is set and a parent directory
+// of Path is a symlink
+func (create *Create) checkSymlinkComponents() error {
+	if !create.RejectSymlinkComponents {
+		return nil
+	}
+	found, link, err := common.HasSymlinkComponent(create.Path)
+	if err != nil {
+		return fmt.Errorf("failed to check %s for symlinks: %w", create.Path, err)
+	}
+	if found {
+		return &ErrCheckSymlinkInPath{Path: create.Path, Link: link}
+	}
+	return nil
+}
+
+// writableDir fails unless dir is a directory with the owner write bit set, the same test WillCreate applies
+func writableDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to access parent directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return common.Errorf(ErrNotDirectory, "parent path is not a directory: %s", dir)
 	}
+	if info.Mode().Perm()&0200 == 0 {
+		return common.Errorf(ErrPermissionMismatch, "parent directory not writable: %s", dir)
+	}
+	return nil
 }
 
 type Options struct {
-	CreatedBefore      time.Time   // Check directory creation time
-	ModifiedBefore     time.Time   // Check directory modified time
-	RequireOwner       string      // Check if the directory has a specific owner
-	RequireGroup       string      // Check if the directory has a specific group
-	RequireBaseDir     string      // Check if the directory is inside a specific base directory
-	RequireExt         string      // Check if the directory has an extension (unlikely, but included for parity)
-	RequirePrefix      string      // Check if the directory name begins with a prefix
-	MorePermissiveThan os.FileMode // Check if mode is at least this permissive (e.g., >= 0444)
-	LessPermissiveThan os.FileMode // Check if mode is less permissive than this (e.g., <= 0400)
-	ReadOnly           bool        // Check if the directory is read-only
-	RequireWrite       bool        // Check if the directory is writable
-	WillCreate         bool        // User intends to create the directory, so if true, verify that we can create a directory in the parent of the path
-	Create             Create      // user intends to create the directory
-	Exists             bool        // If true, require the directory to exist; combining with WillCreate means Exists requires the Create to be successful
+	CreatedBefore               time.Time     // Check directory creation time
+	ModifiedBefore              time.Time     // Check directory modified time
+	CreatedAfter                time.Time     // Check directory creation time is not before
+	ModifiedAfter               time.Time     // Check directory modified time is not before
+	RequireOwner                string        // Check if the directory has a specific owner
+	RequireGroup                string        // Check if the directory has a specific group
+	RequireOwnerName            string        // Check if the directory owner resolves to this user name (e.g. "deploy")
+	RequireGroupName            string        // Check if the directory group resolves to this group name
+	RequireOwnedByCurrentUser   bool          // Check if the directory owner is the user running the process (os.Getuid()), unsupported on Windows
+	RequireOwnedByCurrentGroup  bool          // Check if the directory group is the primary group of the process (os.Getgid()), unsupported on Windows
+	RecursiveOwner              bool          // Check RequireOwner and RequireGroup against everything below the directory too, symlinks are skipped
+	RequireBaseDir              string        // Check if the directory is inside a specific base directory
+	RequireBaseDirs             []string      // Check if the directory is inside any of these base directories, or RequireBaseDir
+	ForbidBaseDirs              []string      // Check if the directory is outside all of these directories (e.g. /etc, /proc), even through symlinks
+	RequireExt                  string        // Check if the directory has an extension (unlikely, but included for parity)
+	RequirePrefix               string        // Check if the directory name begins with a prefix
+	RequireSuffix               string        // Check if the directory name ends with a suffix (e.g. "-tmp"), independent of RequireExt
+	RequireNameMatch            string        // Check if the directory name matches this filepath.Match pattern (e.g. "release-v*")
+	RequireReadyMarker          string        // Check if the named marker file (e.g. ".ready") exists inside the directory
+	ReadyMarkerToken            string        // Check if the RequireReadyMarker file contains this token
+	RequireIndexFile            string        // Check if the named index file (e.g. "index.html") exists inside the directory and is non-empty
+	ValidateIndexHTML           bool          // Check if RequireIndexFile parses as HTML (needs -tags checkfs_html)
+	RequireEmpty                bool          // Check if the directory has no entries, hidden (dot) files count as entries
+	RequireNonEmpty             bool          // Check if the directory has at least one entry, hidden (dot) files count as entries
+	ContainsGlob                string        // Check if at least one entry matches this pattern (e.g. "*.pem"), relative to the directory
+	ContainsGlobCount           int           // Check ContainsGlob matches exactly this many entries, 0 is unset
+	MinEntries                  int           // Check if the directory has at least this many entries
+	MaxEntries                  int           // Check if the directory has at most this many entries, 0 is unset
+	MinTotalSize                int64         // Check if the regular files in the tree add up to at least this many bytes, 0 is unset
+	MaxTotalSize                int64         // Check if the regular files in the tree add up to at most this many bytes, 0 is unset
+	Recursive                   bool          // Check MinEntries, MaxEntries and RequireUniformModTime against the whole tree instead of the direct entries
+	RequireUniformModTime       time.Time     // Check if the directory and its entries were all modified at this time (e.g. SOURCE_DATE_EPOCH)
+	ModTimeTolerance            time.Duration // Check RequireUniformModTime allowing this much difference, 0 requires an exact match
+	MorePermissiveThan          os.FileMode   // Check if mode is at least this permissive (e.g., >= 0444)
+	LessPermissiveThan          os.FileMode   // Check if mode is less permissive than this (e.g., <= 0400)
+	RecursiveLessPermissiveThan os.FileMode   // Check every entry below the directory is no more permissive than this (e.g. 0775 forbids world-writable), symlinks are skipped
+	RecursiveMaxEntries         int           // Fail RecursiveOwner and RecursiveLessPermissiveThan once their walk passes this many entries, 0 is unset
+	MaxDepth                    int           // Limit every tree walk (Recursive, total size, RecursiveOwner, RecursiveLessPermissiveThan) to this depth, the directory is depth 0 so 1 is its direct entries, 0 is unlimited
+	SkipHidden                  bool          // Leave hidden (dot) entries and everything below them out of every tree walk
+	ReadOnly                    bool          // Check if the directory is read-only
+	RequireWrite                bool          // Check if the directory is writable
+	ForbidSetuid                bool          // Check the setuid bit is not set (never set on Windows)
+	ForbidSetgid                bool          // Check the setgid bit is not set, i.e. new entries keep their creator's group (never set on Windows)
+	RequireSticky               bool          // Check the sticky bit is set, e.g. on a shared /tmp-like directory (skipped on Windows)
+	WillCreate                  bool          // User intends to create the directory, so if true, verify that we can create a directory in the parent of the path
+	Create                      Create        // user intends to create the directory
+	Exists                      bool          // If true, require the directory to exist; combining with WillCreate means Exists requires the Create to be successful
+	MustNotExist                bool          // If true, fail with ErrAlreadyExists when the directory exists; left false an existing directory is fine either way
+	RejectBrokenSymlink         bool          // Check the path is not a symlink whose target is missing
+	Strict                      bool          // Fail if any component of the path, the directory itself included, is a symlink
+}
+
+// Validate rejects Options whose fields contradict each other or can never be satisfied, wrapping ErrInvalidOptions. It
+// only inspects opts, never the filesystem, and Directory and its variants call it before anything else.
+func (opts Options) Validate() error {
+	if !opts.CreatedBefore.IsZero() && opts.CreatedAfter.After(opts.CreatedBefore) {
+		return fmt.Errorf("%w: CreatedAfter %s is after CreatedBefore %s", ErrInvalidOptions,
+			opts.CreatedAfter.Format(time.RFC3339), opts.CreatedBefore.Format(time.RFC3339))
+	}
+	if !opts.ModifiedBefore.IsZero() && opts.ModifiedAfter.After(opts.ModifiedBefore) {
+		return fmt.Errorf("%w: ModifiedAfter %s is after ModifiedBefore %s", ErrInvalidOptions,
+			opts.ModifiedAfter.Format(time.RFC3339), opts.ModifiedBefore.Format(time.RFC3339))
+	}
+	if opts.Exists && opts.MustNotExist {
+		return fmt.Errorf("%w: Exists and MustNotExist are mutually exclusive", ErrInvalidOptions)
+	}
+	if opts.RecursiveMaxEntries < 0 {
+		return fmt.Errorf("%w: RecursiveMaxEntries cannot be negative", ErrInvalidOptions)
+	}
+	if opts.RecursiveOwner && opts.RequireOwner == "" && opts.RequireGroup == "" {
+		return fmt.Errorf("%w: RecursiveOwner requires RequireOwner or RequireGroup", ErrInvalidOptions)
+	}
+	if opts.ValidateIndexHTML && opts.RequireIndexFile == "" {
+		return fmt.Errorf("%w: ValidateIndexHTML requires RequireIndexFile", ErrInvalidOptions)
+	}
+	if opts.RequireEmpty && opts.RequireNonEmpty {
+		return fmt.Errorf("%w: RequireEmpty and RequireNonEmpty are mutually exclusive", ErrInvalidOptions)
+	}
+	if opts.ContainsGlob != "" {
+		if filepath.IsAbs(opts.ContainsGlob) || strings.HasPrefix(opts.ContainsGlob, "/") {
+			return fmt.Errorf("%w: ContainsGlob %q must be relative to the directory", ErrInvalidOptions, opts.ContainsGlob)
+		}
+		if _, err := filepath.Match(opts.ContainsGlob, ""); err != nil {
+			return fmt.Errorf("%w: ContainsGlob %q: %w", ErrInvalidOptions, opts.ContainsGlob, err)
+		}
+	}
+	if opts.ContainsGlobCount < 0 || (opts.ContainsGlobCount > 0 && opts.ContainsGlob == "") {
+		return fmt.Errorf("%w: ContainsGlobCount requires ContainsGlob and cannot be negative", ErrInvalidOptions)
+	}
+	if opts.RequireEmpty && (opts.MinEntries > 0 || opts.ContainsGlob != "") {
+		return fmt.Errorf("%w: RequireEmpty contradicts MinEntries and ContainsGlob", ErrInvalidOptions)
+	}
+	if err := common.ValidatePermissions(opts.ReadOnly, opts.RequireWrite, opts.MorePermissiveThan, opts.LessPermissiveThan); err != nil {
+		return err
+	}
+	if opts.MinTotalSize < 0 || opts.MaxTotalSize < 0 {
+		return fmt.Errorf("%w: MinTotalSize and MaxTotalSize cannot be negative", ErrInvalidOptions)
+	}
+	if opts.MaxTotalSize > 0 && opts.MinTotalSize > opts.MaxTotalSize {
+		return fmt.Errorf("%w: MinTotalSize %d is greater than MaxTotalSize %d", ErrInvalidOptions, opts.MinTotalSize, opts.MaxTotalSize)
+	}
+	if opts.MinEntries < 0 || opts.MaxEntries < 0 {
+		return fmt.Errorf("%w: MinEntries and MaxEntries cannot be negative", ErrInvalidOptions)
+	}
+	if opts.MaxEntries > 0 && opts.MinEntries > opts.MaxEntries {
+		return fmt.Errorf("%w: MinEntries %d is greater than MaxEntries %d", ErrInvalidOptions, opts.MinEntries, opts.MaxEntries)
+	}
+	if opts.Recursive && opts.MinEntries == 0 && opts.MaxEntries == 0 && opts.RequireUniformModTime.IsZero() {
+		return fmt.Errorf("%w: Recursive requires MinEntries, MaxEntries or RequireUniformModTime", ErrInvalidOptions)
+	}
+	if opts.MaxDepth < 0 {
+		return fmt.Errorf("%w: MaxDepth cannot be negative", ErrInvalidOptions)
+	}
+	if (opts.MaxDepth > 0 || opts.SkipHidden) && !opts.walksTree() {
+		return fmt.Errorf("%w: MaxDepth and SkipHidden require Recursive, MinTotalSize, MaxTotalSize, RecursiveOwner or "+
+			"RecursiveLessPermissiveThan", ErrInvalidOptions)
+	}
+	if opts.ModTimeTolerance < 0 {
+		return fmt.Errorf("%w: ModTimeTolerance cannot be negative", ErrInvalidOptions)
+	}
+	if opts.ModTimeTolerance != 0 && opts.RequireUniformModTime.IsZero() {
+		return fmt.Errorf("%w: ModTimeTolerance requires RequireUniformModTime", ErrInvalidOptions)
+	}
+	for _, base := range opts.RequireBaseDirs {
+		if base == "" {
+			return fmt.Errorf("%w: RequireBaseDirs cannot contain an empty path", ErrInvalidOptions)
+		}
+	}
+	for _, base := range opts.ForbidBaseDirs {
+		if base == "" {
+			return fmt.Errorf("%w: ForbidBaseDirs cannot contain an empty path", ErrInvalidOptions)
+		}
+	}
+	if opts.RequireNameMatch != "" {
+		if _, err := filepath.Match(opts.RequireNameMatch, ""); err != nil {
+			return fmt.Errorf("%w: RequireNameMatch %q: %w", ErrInvalidOptions, opts.RequireNameMatch, err)
+		}
+	}
+	return nil
+}
+
+// walksTree reports whether any enabled check walks the tree below the directory
+func (opts Options) walksTree() bool {
+	return opts.Recursive || opts.MinTotalSize > 0 || opts.MaxTotalSize > 0 || opts.RecursiveOwner ||
+		opts.RecursiveLessPermissiveThan != 0
+}
+
+// walkOptions are the common.WalkOptions of every tree walk
+func (opts Options) walkOptions() common.WalkOptions {
+	return common.WalkOptions{MaxDepth: opts.MaxDepth, SkipHidden: opts.SkipHidden}
 }
 
 // Directory performs the directory checks
 func Directory(path string, opts Options) error {
+	return DirectoryContext(context.Background(), path, opts)
+}
+
+// DirectoryContext performs the directory checks, returning ctx.Err() as soon as ctx is done. The context is consulted
+// before the directory is stat'd and between every check; a single stat that blocks (e.g. on a hung NFS mount) cannot
+// be interrupted, but nothing further runs once it returns.
+func DirectoryContext(ctx context.Context, path string, opts Options) error {
+	_, err := StatContext(ctx, path, opts)
+	return err
+}
+
+// Stat performs the directory checks like Directory and, when they pass, returns the os.FileInfo they ran against,
+// saving the caller another stat. The info is nil when there was no directory to check: it is missing and allowed to
+// be, or Create has just made it.
+func Stat(path string, opts Options) (os.FileInfo, error) {
+	return StatContext(context.Background(), path, opts)
+}
+
+// StatContext is Stat, returning ctx.Err() as soon as ctx is done
+func StatContext(ctx context.Context, path string, opts Options) (os.FileInfo, error) {
+	info, failures := runStat(ctx, nil, path, opts, false)
+	if len(failures) > 0 {
+		return nil, failures[0].Err
+	}
+	return info, nil
+}
+
+// DirectoryAll performs every directory check and returns each failure in the order Directory would have encountered
+// them, or nil when every check passes. Existence, creation and a failed os.Stat still stop the run immediately, since
+// no other check can run without them.
+func DirectoryAll(path string, opts Options) []error {
+	return common.Errors(run(context.Background(), nil, path, opts, true))
+}
+
+// DirectoryResult performs every directory check like DirectoryAll and returns the failures as a common.Result with a stable Code and the
+// failing Options field for each violation
+func DirectoryResult(path string, opts Options) common.Result {
+	return common.NewResult(path, run(context.Background(), nil, path, opts, true))
+}
+
+// state is shared by every check run against a single path
+type state struct {
+	ctx  context.Context
+	fsys fs.FS // fsys is nil when checking the OS filesystem
+	path string
+	info os.FileInfo
+	opts *Options
+
+	owner *common.OwnerCache // owner is shared by every owner/group check so the lookups run once
+
+	entries *int   // entries memoizes the count shared by MinEntries and MaxEntries, nil until counted
+	total   *int64 // total memoizes the tree size shared by MinTotalSize and MaxTotalSize, nil until summed
+}
+
+// entryCount counts the entries of the directory once per run, see countEntries
+func (s *state) entryCount() (int, error) {
+	if s.entries == nil {
+		count, err := countEntries(s.ctx, s.fsys, s.path, s.opts.Recursive, s.opts.walkOptions())
+		if err != nil {
+			return 0, err
+		}
+		s.entries = &count
+	}
+	return *s.entries, nil
+}
+
+// totalSize sums the tree once per run, see common.DirSizeWith
+func (s *state) totalSize() (int64, error) {
+	if s.total == nil {
+		total, err := common.DirSizeWith(s.ctx, s.path, s.opts.walkOptions())
+		if err != nil {
+			return 0, err
+		}
+		s.total = &total
+	}
+	return *s.total, nil
+}
+
+// check is a single validation step; enabled reports whether the Options ask for it
+type check struct {
+	name    string
+	enabled func(opts *Options) bool
+	run     func(s *state) error
+}
+
+// run resolves existence and creation for path (in fsys, or the OS filesystem when fsys is nil), then runs every
+// enabled check in order, stopping at the first failure unless all is true
+func run(ctx context.Context, fsys fs.FS, path string, opts Options, all bool) []common.Failure {
+	_, failures := runStat(ctx, fsys, path, opts, all)
+	return failures
+}
+
+// runStat is run, also returning the os.FileInfo the checks ran against; it is nil when no checks ran
+func runStat(ctx context.Context, fsys fs.FS, path string, opts Options, all bool) (os.FileInfo, []common.Failure) {
+	if err := opts.Validate(); err != nil {
+		return nil, []common.Failure{{Err: err}}
+	}
+	if fsys != nil {
+		if err := opts.validateFS(); err != nil {
+			return nil, []common.Failure{{Err: err}}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, []common.Failure{{Err: err}}
+	}
+	info, done, err := prepare(ctx, fsys, path, &opts)
+	if err != nil {
+		return nil, []common.Failure{{Err: err}}
+	}
+	if done {
+		return nil, nil
+	}
+
+	s := &state{
+		ctx:   ctx,
+		fsys:  fsys,
+		path:  path,
+		info:  info,
+		opts:  &opts,
+		owner: &common.OwnerCache{Path: path, Info: info},
+	}
+	var failures []common.Failure
+	for _, c := range checks {
+		if !c.enabled(s.opts) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return info, append(failures, common.Failure{Err: err})
+		}
+		if err := c.run(s); err != nil {
+			failures = append(failures, common.Failure{Field: c.name, Err: err})
+			if !all {
+				break
+			}
+		}
+	}
+	return info, failures
+}
+
+// prepare handles WillCreate, Exists and Create for path; done is true when nothing is left to check
+func prepare(ctx context.Context, fsys fs.FS, path string, opts *Options) (info os.FileInfo, done bool, err error) {
+
+	// Strict refuses the path before anything resolves a symlink in it, and stats the directory without following one
+	stat := common.StatContext
+	if opts.Strict {
+		if err := strictPath(path); err != nil {
+			return nil, true, err
+		}
+		stat = lstat
+	}
 
 	// Handle WillCreate logic first
 	if opts.WillCreate {
@@ -112,15 +698,15 @@ func Directory(path string, opts Options) error {
 			opts.Create.Kind = IfNotExists
 		}
 		parentDir := filepath.Dir(path)
-		parentInfo, err := os.Stat(parentDir)
+		parentInfo, err := common.StatContext(ctx, nil, parentDir)
 		if err != nil {
-			return fmt.Errorf("failed to access parent directory %s: %w", parentDir, err)
+			return nil, true, fmt.Errorf("failed to access parent directory %s: %w", parentDir, err)
 		}
 		if !parentInfo.IsDir() {
-			return fmt.Errorf("parent path is not a directory: %s", parentDir)
+			return nil, true, common.Errorf(ErrNotDirectory, "parent path is not a directory: %s", parentDir)
 		}
 		if parentInfo.Mode().Perm()&0200 == 0 {
-			return fmt.Errorf("parent directory not writable: %s", parentDir)
+			return nil, true, common.Errorf(ErrPermissionMismatch, "parent directory not writable: %s", parentDir)
 		}
 	}
 
@@ -130,145 +716,704 @@ func Directory(path string, opts Options) error {
 	}
 
 	// Get directory info
-	info, err := os.Stat(path)
+	info, err = stat(ctx, fsys, path)
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, fs.ErrNotExist) {
+			if opts.RejectBrokenSymlink {
+				if err := brokenSymlink(path); err != nil {
+					return nil, true, err
+				}
+			}
 			if !opts.Exists && opts.Create.Kind == NoAction {
-				return nil
+				return nil, true, nil
 			}
-			if opts.Create.Kind == IfNotExists {
-				return opts.Create.Run()
+			if opts.Create.Kind == IfNotExists || opts.Create.Kind == EnsureValid {
+				return nil, true, opts.Create.Run()
 			}
 			if opts.Exists && !opts.WillCreate {
-				return fmt.Errorf("directory does not exist: %s", path)
+				return nil, true, common.Errorf(ErrDoesNotExist, "directory does not exist: %s", path)
 			}
-			return nil
+			return nil, true, nil
 		}
-		return fmt.Errorf("failed to stat directory %s: %w", path, err)
+		return nil, true, fmt.Errorf("failed to stat directory %s: %w", path, err)
 	}
 
 	// Directory exists - check if we explicitly don't want it to
-	if !opts.Exists && !opts.WillCreate {
-		return fmt.Errorf("directory exists but was expected not to exist: %s", path)
+	if opts.MustNotExist {
+		return nil, true, common.Errorf(ErrAlreadyExists, "directory exists but was expected not to exist: %s", path)
 	}
 
 	// Check if path is a directory
 	if !info.IsDir() {
-		return fmt.Errorf("not a directory: %s", path)
+		return nil, true, common.Errorf(ErrNotDirectory, "not a directory: %s", path)
 	}
 
 	if opts.Exists && opts.Create.Kind == IfExists {
-		return opts.Create.Run()
+		return nil, true, opts.Create.Run()
+	}
+	if opts.Create.Kind == EnsureValid {
+		if err := opts.Create.Run(); err != nil {
+			return nil, true, err
+		}
+		if info, err = common.StatContext(ctx, fsys, path); err != nil {
+			return nil, true, fmt.Errorf("failed to stat directory %s: %w", path, err)
+		}
+	}
+	return info, false, nil
+}
+
+// lstat stats path itself for Strict, which validateFS keeps away from an fs.FS
+func lstat(_ context.Context, _ fs.FS, path string) (fs.FileInfo, error) {
+	return os.Lstat(path)
+}
+
+// strictPath returns ErrCheckSymlinkInPath when any existing component of path is a symlink
+func strictPath(path string) error {
+	link, err := common.FirstSymlink(path)
+	if err != nil {
+		return fmt.Errorf("failed to check %s for symlinks: %w", path, err)
+	}
+	if link != "" {
+		return &ErrCheckSymlinkInPath{Path: path, Link: link}
 	}
+	return nil
+}
+
+// brokenSymlink returns ErrCheckBrokenSymlink when path is a symlink whose target does not resolve
+func brokenSymlink(path string) error {
+	broken, err := common.IsBrokenSymlink(path)
+	if err != nil || !broken {
+		return err
+	}
+	target, err := common.SymlinkTarget(path)
+	if err != nil {
+		return err
+	}
+	return &ErrCheckBrokenSymlink{Path: path, Target: target}
+}
+
+// checks run in order against a path that exists and is a directory
+var checks = []check{
+	// Check the directory was fully provisioned
+	{"RequireReadyMarker", func(o *Options) bool { return o.RequireReadyMarker != "" }, func(s *state) error {
+		return checkReadyMarker(s.fsys, s.path, s.opts.RequireReadyMarker, s.opts.ReadyMarkerToken)
+	}},
+
+	// Check the index file exists and, optionally, parses as HTML
+	{"RequireIndexFile", func(o *Options) bool { return o.RequireIndexFile != "" }, func(s *state) error {
+		return checkIndexFile(s.fsys, s.path, s.opts.RequireIndexFile, s.opts.ValidateIndexHTML)
+	}},
+
+	// Check emptiness
+	{"RequireEmpty", func(o *Options) bool { return o.RequireEmpty }, func(s *state) error {
+		empty, err := isEmpty(s.fsys, s.path)
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return common.Errorf(ErrSizeMismatch, "directory is not empty: %s", s.path)
+		}
+		return nil
+	}},
+	{"RequireNonEmpty", func(o *Options) bool { return o.RequireNonEmpty }, func(s *state) error {
+		empty, err := isEmpty(s.fsys, s.path)
+		if err != nil {
+			return err
+		}
+		if empty {
+			return common.Errorf(ErrSizeMismatch, "directory is empty: %s", s.path)
+		}
+		return nil
+	}},
+
+	// Check entries matching a glob
+	{"ContainsGlob", func(o *Options) bool { return o.ContainsGlob != "" }, func(s *state) error {
+		return checkGlob(s.fsys, s.path, s.opts.ContainsGlob, s.opts.ContainsGlobCount)
+	}},
+
+	// Check the number of entries
+	{"MinEntries", func(o *Options) bool { return o.MinEntries > 0 }, func(s *state) error {
+		count, err := s.entryCount()
+		if err != nil {
+			return err
+		}
+		if count < s.opts.MinEntries {
+			return &ErrCheckTooFewEntries{Path: s.path, Min: s.opts.MinEntries, Actual: count}
+		}
+		return nil
+	}},
+	{"MaxEntries", func(o *Options) bool { return o.MaxEntries > 0 }, func(s *state) error {
+		count, err := s.entryCount()
+		if err != nil {
+			return err
+		}
+		if count > s.opts.MaxEntries {
+			return &ErrCheckTooManyEntries{Path: s.path, Max: s.opts.MaxEntries, Actual: count}
+		}
+		return nil
+	}},
+
+	// Check the total size of the regular files in the tree
+	{"MinTotalSize", func(o *Options) bool { return o.MinTotalSize > 0 }, func(s *state) error {
+		total, err := s.totalSize()
+		if err != nil {
+			return err
+		}
+		if total < s.opts.MinTotalSize {
+			return &ErrCheckDirTooSmall{Path: s.path, Total: total, Limit: s.opts.MinTotalSize}
+		}
+		return nil
+	}},
+	{"MaxTotalSize", func(o *Options) bool { return o.MaxTotalSize > 0 }, func(s *state) error {
+		total, err := s.totalSize()
+		if err != nil {
+			return err
+		}
+		if total > s.opts.MaxTotalSize {
+			return &ErrCheckDirTooLarge{Path: s.path, Total: total, Limit: s.opts.MaxTotalSize}
+		}
+		return nil
+	}},
 
 	// Check creation time
-	if !opts.CreatedBefore.IsZero() {
-		createTime, err := common.GetCreationTime(path)
+	{"CreatedBefore", func(o *Options) bool { return !o.CreatedBefore.IsZero() }, func(s *state) error {
+		createTime, err := common.GetCreationTimeInfo(s.info)
 		if err != nil {
-			return fmt.Errorf("failed to get creation time for %s: %w", path, err)
+			return fmt.Errorf("failed to get creation time for %s: %w", s.path, err)
 		}
-		if createTime.After(opts.CreatedBefore) {
-			return fmt.Errorf("directory created after specified time: %s", path)
+		if createTime.After(s.opts.CreatedBefore) {
+			return common.Errorf(ErrTimeMismatch, "directory created after specified time: %s", s.path)
 		}
-	}
+		return nil
+	}},
+	{"CreatedAfter", func(o *Options) bool { return !o.CreatedAfter.IsZero() }, func(s *state) error {
+		createTime, err := common.GetCreationTimeInfo(s.info)
+		if err != nil {
+			return fmt.Errorf("failed to get creation time for %s: %w", s.path, err)
+		}
+		if createTime.Before(s.opts.CreatedAfter) {
+			return common.Errorf(ErrTimeMismatch, "directory %s created at %s, before %s",
+				s.path, createTime.Format(time.RFC3339), s.opts.CreatedAfter.Format(time.RFC3339))
+		}
+		return nil
+	}},
 
 	// Check modification time
-	if !opts.ModifiedBefore.IsZero() && info.ModTime().After(opts.ModifiedBefore) {
-		return fmt.Errorf("directory modified after specified time: %s", path)
-	}
+	{"ModifiedBefore", func(o *Options) bool { return !o.ModifiedBefore.IsZero() }, func(s *state) error {
+		if s.info.ModTime().After(s.opts.ModifiedBefore) {
+			return common.Errorf(ErrTimeMismatch, "directory modified after specified time: %s", s.path)
+		}
+		return nil
+	}},
+	{"ModifiedAfter", func(o *Options) bool { return !o.ModifiedAfter.IsZero() }, func(s *state) error {
+		if modTime := s.info.ModTime(); modTime.Before(s.opts.ModifiedAfter) {
+			return common.Errorf(ErrTimeMismatch, "directory %s modified at %s, before %s",
+				s.path, modTime.Format(time.RFC3339), s.opts.ModifiedAfter.Format(time.RFC3339))
+		}
+		return nil
+	}},
 
-	// Check directory prefix
-	if opts.RequirePrefix != "" {
-		basename := filepath.Base(path)
-		if !strings.HasPrefix(basename, opts.RequirePrefix) {
-			return fmt.Errorf("incorrect directory prefix for %s: expected prefix %s",
-				path, opts.RequirePrefix)
+	// Check every entry has the same modification time
+	{"RequireUniformModTime", func(o *Options) bool { return !o.RequireUniformModTime.IsZero() }, func(s *state) error {
+		return checkUniformModTime(s.ctx, s.fsys, s.path, s.info, s.opts.RequireUniformModTime, s.opts.ModTimeTolerance,
+			s.opts.Recursive, s.opts.walkOptions())
+	}},
+
+	// Check directory prefix and suffix
+	{"RequirePrefix", func(o *Options) bool { return o.RequirePrefix != "" }, func(s *state) error {
+		basename := filepath.Base(s.path)
+		if !strings.HasPrefix(basename, s.opts.RequirePrefix) {
+			return common.Errorf(ErrNameMismatch, "incorrect directory prefix for %s: expected prefix %s",
+				s.path, s.opts.RequirePrefix)
 		}
-	}
+		return nil
+	}},
+	{"RequireSuffix", func(o *Options) bool { return o.RequireSuffix != "" }, func(s *state) error {
+		basename := filepath.Base(s.path)
+		if !strings.HasSuffix(basename, s.opts.RequireSuffix) {
+			return common.Errorf(ErrNameMismatch, "incorrect directory suffix for %s: expected suffix %s",
+				s.path, s.opts.RequireSuffix)
+		}
+		return nil
+	}},
+	{"RequireNameMatch", func(o *Options) bool { return o.RequireNameMatch != "" }, func(s *state) error {
+		basename := filepath.Base(s.path)
+		if matched, _ := filepath.Match(s.opts.RequireNameMatch, basename); !matched { // the pattern was checked by Validate
+			return common.Errorf(ErrNameMismatch, "directory name %s does not match pattern %s: %s",
+				basename, s.opts.RequireNameMatch, s.path)
+		}
+		return nil
+	}},
 
 	// Check if directory is inside the required base directory
-	if opts.RequireBaseDir != "" {
-		isInBase, err := common.IsPathInBase(path, opts.RequireBaseDir)
+	{"RequireBaseDir", func(o *Options) bool { return o.RequireBaseDir != "" && len(o.RequireBaseDirs) == 0 }, func(s *state) error {
+		isInBase, err := common.IsPathInBase(s.path, s.opts.RequireBaseDir)
 		if err != nil {
-			return fmt.Errorf("failed to check base directory for %s: %w", path, err)
+			return fmt.Errorf("failed to check base directory for %s: %w", s.path, err)
 		}
 		if !isInBase {
-			return &ErrCheckDirBadBaseDir{Path: path, BaseDir: opts.RequireBaseDir}
+			return &ErrCheckDirBadBaseDir{Path: s.path, BaseDir: s.opts.RequireBaseDir}
 		}
-	}
+		return nil
+	}},
+	// RequireBaseDirs takes over RequireBaseDir when both are set, passing a path inside either
+	{"RequireBaseDirs", func(o *Options) bool { return len(o.RequireBaseDirs) > 0 }, func(s *state) error {
+		bases := s.opts.RequireBaseDirs
+		if s.opts.RequireBaseDir != "" {
+			bases = append([]string{s.opts.RequireBaseDir}, bases...)
+		}
+		for _, base := range bases {
+			isInBase, err := common.IsPathInBase(s.path, base)
+			if err != nil {
+				return fmt.Errorf("failed to check base directory for %s: %w", s.path, err)
+			}
+			if isInBase {
+				return nil
+			}
+		}
+		return &ErrCheckDirBadBaseDirs{Path: s.path, BaseDirs: bases}
+	}},
 
-	// Get directory permissions
-	mode := info.Mode()
-	if opts.ReadOnly && mode.Perm()&0222 != 0 {
-		return &ErrCheckDirOpenPermissions{Path: path}
-	}
-	if opts.RequireWrite && mode.Perm()&0200 == 0 {
-		return &ErrCheckDirNoWritePermissions{Path: path}
-	}
+	// Check the directory is not inside a denied directory, as written or once symlinks are resolved
+	{"ForbidBaseDirs", func(o *Options) bool { return len(o.ForbidBaseDirs) > 0 }, func(s *state) error {
+		for _, base := range s.opts.ForbidBaseDirs {
+			inBase, err := common.ResolvesIntoBase(s.path, base)
+			if err != nil {
+				return fmt.Errorf("failed to check forbidden base directory for %s: %w", s.path, err)
+			}
+			if inBase {
+				return &ErrCheckDirForbiddenBaseDir{Path: s.path, BaseDir: base}
+			}
+		}
+		return nil
+	}},
 
-	// Check more permissive than
-	if opts.MorePermissiveThan != 0 {
-		isMorePermissive, err := common.IsMorePermissiveThan(path, opts.MorePermissiveThan)
-		if err != nil {
-			return fmt.Errorf("failed to check permissions for %s: %w", path, err)
+	// Check directory permissions
+	{"ReadOnly", func(o *Options) bool { return o.ReadOnly }, func(s *state) error {
+		if s.info.Mode().Perm()&0222 != 0 {
+			return &ErrCheckDirOpenPermissions{Path: s.path}
+		}
+		return nil
+	}},
+	{"RequireWrite", func(o *Options) bool { return o.RequireWrite }, func(s *state) error {
+		if s.info.Mode().Perm()&0200 == 0 {
+			return &ErrCheckDirNoWritePermissions{Path: s.path}
 		}
+		return nil
+	}},
+
+	// Check the special bits, which Perm() and so the permissive-than checks leave out
+	{"ForbidSetuid", func(o *Options) bool { return o.ForbidSetuid }, func(s *state) error {
+		if s.info.Mode()&os.ModeSetuid != 0 {
+			return &ErrCheckDirSetuidSet{Path: s.path}
+		}
+		return nil
+	}},
+	{"ForbidSetgid", func(o *Options) bool { return o.ForbidSetgid }, func(s *state) error {
+		if s.info.Mode()&os.ModeSetgid != 0 {
+			return &ErrCheckDirSetgidSet{Path: s.path}
+		}
+		return nil
+	}},
+	{"RequireSticky", func(o *Options) bool { return o.RequireSticky && runtime.GOOS != "windows" }, func(s *state) error {
+		if s.info.Mode()&os.ModeSticky == 0 {
+			return &ErrCheckDirStickyMissing{Path: s.path}
+		}
+		return nil
+	}},
+
+	// Check more permissive than
+	{"MorePermissiveThan", func(o *Options) bool { return o.MorePermissiveThan != 0 }, func(s *state) error {
+		isMorePermissive := common.IsMorePermissiveThanInfo(s.info, s.opts.MorePermissiveThan)
 		if !isMorePermissive {
-			return fmt.Errorf("directory mode for %s is less permissive than required: expected at least %o, got %o",
-				path, opts.MorePermissiveThan, mode.Perm())
+			missing, _ := common.ModeDiff(s.info.Mode(), s.opts.MorePermissiveThan)
+			return common.Errorf(ErrPermissionMismatch, "directory mode for %s is less permissive than required: expected at least %o, got %o, missing bits: %04o",
+				s.path, s.opts.MorePermissiveThan, s.info.Mode().Perm(), missing)
 		}
-	}
+		return nil
+	}},
 
 	// Check less permissive than
-	if opts.LessPermissiveThan != 0 {
-		isLessPermissive, err := common.IsLessPermissiveThan(path, opts.LessPermissiveThan)
-		if err != nil {
-			return fmt.Errorf("failed to check permissions for %s: %w", path, err)
-		}
+	{"LessPermissiveThan", func(o *Options) bool { return o.LessPermissiveThan != 0 }, func(s *state) error {
+		isLessPermissive := common.IsLessPermissiveThanInfo(s.info, s.opts.LessPermissiveThan)
 		if !isLessPermissive {
-			return fmt.Errorf("directory mode for %s is more permissive than allowed: expected at most %o, got %o",
-				path, opts.LessPermissiveThan, mode.Perm())
+			_, extra := common.ModeDiff(s.info.Mode(), s.opts.LessPermissiveThan)
+			return common.Errorf(ErrPermissionMismatch, "directory mode for %s is more permissive than allowed: expected at most %o, got %o, extra bits: %04o",
+				s.path, s.opts.LessPermissiveThan, s.info.Mode().Perm(), extra)
 		}
-	}
+		return nil
+	}},
+	{"RecursiveLessPermissiveThan", func(o *Options) bool { return o.RecursiveLessPermissiveThan != 0 }, func(s *state) error {
+		return checkModeTree(s.ctx, s.path, s.opts.RecursiveMaxEntries, s.opts.walkOptions(),
+			s.opts.RecursiveLessPermissiveThan)
+	}},
 
 	// Check owner and group
-	if opts.RequireOwner != "" || opts.RequireGroup != "" {
-		uid, gid, err := common.GetOwnerAndGroup(path)
+	{"RequireOwner", func(o *Options) bool { return o.RequireOwner != "" }, func(s *state) error {
+		uid, _, err := s.owner.IDs()
 		if err != nil {
-			return fmt.Errorf("failed to get owner/group for %s: %w", path, err)
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
 		}
-		if opts.RequireOwner != "" && uid != opts.RequireOwner {
-			return &ErrCheckDirBadOwner{Path: path, Expected: opts.RequireOwner, Actual: uid}
+		if uid != s.opts.RequireOwner {
+			return &ErrCheckDirBadOwner{Path: s.path, Expected: s.opts.RequireOwner, Actual: uid}
 		}
-		if opts.RequireGroup != "" && gid != opts.RequireGroup {
-			return &ErrCheckDirBadGroup{Path: path, Expected: opts.RequireGroup, Actual: gid}
+		return nil
+	}},
+	{"RequireGroup", func(o *Options) bool { return o.RequireGroup != "" }, func(s *state) error {
+		_, gid, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
 		}
-	}
-
-	return nil
+		if gid != s.opts.RequireGroup {
+			return &ErrCheckDirBadGroup{Path: s.path, Expected: s.opts.RequireGroup, Actual: gid}
+		}
+		return nil
+	}},
+	{"RequireOwnedByCurrentUser", func(o *Options) bool { return o.RequireOwnedByCurrentUser }, func(s *state) error {
+		uid, _, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
+		}
+		if current := strconv.Itoa(os.Getuid()); uid != current {
+			return &ErrCheckDirBadOwner{Path: s.path, Expected: current, Actual: uid}
+		}
+		return nil
+	}},
+	{"RequireOwnedByCurrentGroup", func(o *Options) bool { return o.RequireOwnedByCurrentGroup }, func(s *state) error {
+		_, gid, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
+		}
+		if current := strconv.Itoa(os.Getgid()); gid != current {
+			return &ErrCheckDirBadGroup{Path: s.path, Expected: current, Actual: gid}
+		}
+		return nil
+	}},
+	{"RequireOwnerName", func(o *Options) bool { return o.RequireOwnerName != "" }, func(s *state) error {
+		name, err := s.owner.OwnerName()
+		if err != nil {
+			return fmt.Errorf("failed to get owner name for %s: %w", s.path, err)
+		}
+		if name != s.opts.RequireOwnerName {
+			return &ErrCheckDirBadOwner{Path: s.path, Expected: s.opts.RequireOwnerName, Actual: name}
+		}
+		return nil
+	}},
+	{"RequireGroupName", func(o *Options) bool { return o.RequireGroupName != "" }, func(s *state) error {
+		name, err := s.owner.GroupName()
+		if err != nil {
+			return fmt.Errorf("failed to get group name for %s: %w", s.path, err)
+		}
+		if name != s.opts.RequireGroupName {
+			return &ErrCheckDirBadGroup{Path: s.path, Expected: s.opts.RequireGroupName, Actual: name}
+		}
+		return nil
+	}},
+	{"RecursiveOwner", func(o *Options) bool { return o.RecursiveOwner }, func(s *state) error {
+		return checkOwnerTree(s.ctx, s.path, s.opts.RecursiveMaxEntries, s.opts.walkOptions(), s.opts.RequireOwner,
+			s.opts.RequireGroup)
+	}},
 }
 
 type ErrCheckDirOpenPermissions struct{ Path string }
 type ErrCheckDirNoWritePermissions struct{ Path string }
+type ErrCheckDirSetuidSet struct{ Path string }
+type ErrCheckDirSetgidSet struct{ Path string }
+type ErrCheckDirStickyMissing struct{ Path string }
 type ErrCheckDirBadOwner struct{ Path, Expected, Actual string }
 type ErrCheckDirBadGroup struct{ Path, Expected, Actual string }
 type ErrCheckDirBadBaseDir struct{ Path, BaseDir string }
+type ErrCheckDirBadBaseDirs struct {
+	Path     string
+	BaseDirs []string
+}
+type ErrCheckDirForbiddenBaseDir struct{ Path, BaseDir string }
+type ErrCheckNotReady struct{ Path, Marker string }
+type ErrCheckBrokenSymlink struct{ Path, Target string }
+type ErrCheckSymlinkInPath struct{ Path, Link string }
+type ErrCheckMissingIndex struct{ Path, Index string }
+type ErrCheckMalformedIndex struct {
+	Path, Index string
+	Err         error
+}
+type ErrCheckUnstableSort struct{ Path, First, Second string }
+type ErrCheckNonUniformModTime struct {
+	Path      string
+	Expected  time.Time
+	Offending []string
+}
+type ErrCheckNoGlobMatch struct{ Path, Pattern string }
+type ErrCheckGlobCount struct {
+	Path, Pattern    string
+	Expected, Actual int
+}
+type ErrCheckTooFewEntries struct {
+	Path        string
+	Min, Actual int
+}
+type ErrCheckTooManyEntries struct {
+	Path        string
+	Max, Actual int
+}
+type ErrCheckDirTooLarge struct {
+	Path         string
+	Total, Limit int64
+}
+type ErrCheckDirTooSmall struct {
+	Path         string
+	Total, Limit int64
+}
+type ErrCheckFDExhausted struct {
+	Path string
+	Err  error
+}
+type ErrCheckDirTreePermissions struct {
+	Path      string
+	Mode, Max os.FileMode
+}
 
 func (e *ErrCheckDirOpenPermissions) Error() string {
 	return fmt.Sprintf("permissions too open: %s", e.Path)
 }
 
+func (e *ErrCheckDirOpenPermissions) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
 func (e *ErrCheckDirNoWritePermissions) Error() string {
 	return fmt.Sprintf("no write permission: %s", e.Path)
 }
 
+func (e *ErrCheckDirNoWritePermissions) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckDirSetuidSet) Error() string {
+	return fmt.Sprintf("setuid bit set: %s", e.Path)
+}
+
+func (e *ErrCheckDirSetuidSet) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckDirSetgidSet) Error() string {
+	return fmt.Sprintf("setgid bit set: %s", e.Path)
+}
+
+func (e *ErrCheckDirSetgidSet) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckDirStickyMissing) Error() string {
+	return fmt.Sprintf("sticky bit not set: %s", e.Path)
+}
+
+func (e *ErrCheckDirStickyMissing) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
 func (e *ErrCheckDirBadOwner) Error() string {
 	return fmt.Sprintf("bad owner for %s: expected %s, got %s", e.Path, e.Expected, e.Actual)
 }
 
+func (e *ErrCheckDirBadOwner) Is(target error) bool {
+	return target == ErrOwnerMismatch
+}
+
 func (e *ErrCheckDirBadGroup) Error() string {
 	return fmt.Sprintf("bad group for %s: expected %s, got %s", e.Path, e.Expected, e.Actual)
 }
 
+func (e *ErrCheckDirBadGroup) Is(target error) bool {
+	return target == ErrGroupMismatch
+}
+
 func (e *ErrCheckDirBadBaseDir) Error() string {
 	return fmt.Sprintf("directory %s is not in required base directory %s", e.Path, e.BaseDir)
 }
+
+func (e *ErrCheckDirBadBaseDir) Is(target error) bool {
+	return target == ErrBadBaseDir
+}
+
+func (e *ErrCheckDirBadBaseDirs) Error() string {
+	return fmt.Sprintf("directory %s is not in any of the required base directories %s", e.Path, strings.Join(e.BaseDirs, ", "))
+}
+
+func (e *ErrCheckDirBadBaseDirs) Is(target error) bool {
+	return target == ErrBadBaseDir
+}
+
+func (e *ErrCheckDirForbiddenBaseDir) Error() string {
+	return fmt.Sprintf("directory %s is inside forbidden base directory %s", e.Path, e.BaseDir)
+}
+
+func (e *ErrCheckDirForbiddenBaseDir) Is(target error) bool {
+	return target == ErrBadBaseDir
+}
+
+func (e *ErrCheckNotReady) Error() string {
+	return fmt.Sprintf("directory %s is not ready: marker %s missing or incomplete", e.Path, e.Marker)
+}
+
+func (e *ErrCheckNotReady) Is(target error) bool {
+	return target == ErrDoesNotExist
+}
+
+func (e *ErrCheckBrokenSymlink) Error() string {
+	return fmt.Sprintf("broken symlink %s: target %s does not exist", e.Path, e.Target)
+}
+
+func (e *ErrCheckBrokenSymlink) Is(target error) bool {
+	return target == ErrSymlinkMismatch
+}
+
+func (e *ErrCheckSymlinkInPath) Error() string {
+	return fmt.Sprintf("strict mode refuses %s: %s is a symlink", e.Path, e.Link)
+}
+
+func (e *ErrCheckSymlinkInPath) Is(target error) bool {
+	return target == ErrSymlinkMismatch
+}
+
+func (e *ErrCheckUnstableSort) Error() string {
+	return fmt.Sprintf("entries %q and %q in %s differ only by Unicode normalization", e.First, e.Second, e.Path)
+}
+
+func (e *ErrCheckUnstableSort) Is(target error) bool {
+	return target == ErrNameMismatch
+}
+
+func (e *ErrCheckNonUniformModTime) Error() string {
+	listed := e.Offending
+	more := ""
+	if len(listed) > 5 {
+		listed = listed[:5]
+		more = fmt.Sprintf(" and %d more", len(e.Offending)-5)
+	}
+	return fmt.Sprintf("entries in %s not modified at %s: %s%s",
+		e.Path, e.Expected.Format(time.RFC3339), strings.Join(listed, ", "), more)
+}
+
+func (e *ErrCheckNonUniformModTime) Is(target error) bool {
+	return target == ErrTimeMismatch
+}
+
+func (e *ErrCheckNoGlobMatch) Error() string {
+	return fmt.Sprintf("no entries in %s match %s", e.Path, e.Pattern)
+}
+
+func (e *ErrCheckNoGlobMatch) Is(target error) bool {
+	return target == ErrDoesNotExist
+}
+
+func (e *ErrCheckGlobCount) Error() string {
+	return fmt.Sprintf("entries in %s matching %s: expected %d, got %d", e.Path, e.Pattern, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckGlobCount) Is(target error) bool {
+	return target == ErrSizeMismatch
+}
+
+func (e *ErrCheckTooFewEntries) Error() string {
+	return fmt.Sprintf("too few entries in %s: expected at least %d, got %d", e.Path, e.Min, e.Actual)
+}
+
+func (e *ErrCheckTooFewEntries) Is(target error) bool {
+	return target == ErrSizeMismatch
+}
+
+func (e *ErrCheckTooManyEntries) Error() string {
+	return fmt.Sprintf("too many entries in %s: expected at most %d, got %d", e.Path, e.Max, e.Actual)
+}
+
+func (e *ErrCheckTooManyEntries) Is(target error) bool {
+	return target == ErrSizeMismatch
+}
+
+func (e *ErrCheckDirTooLarge) Error() string {
+	return fmt.Sprintf("directory %s is too large: expected at most %d bytes, got %d", e.Path, e.Limit, e.Total)
+}
+
+func (e *ErrCheckDirTooLarge) Is(target error) bool {
+	return target == ErrSizeMismatch
+}
+
+func (e *ErrCheckDirTooSmall) Error() string {
+	return fmt.Sprintf("directory %s is too small: expected at least %d bytes, got %d", e.Path, e.Limit, e.Total)
+}
+
+func (e *ErrCheckDirTooSmall) Is(target error) bool {
+	return target == ErrSizeMismatch
+}
+
+func (e *ErrCheckMissingIndex) Error() string {
+	return fmt.Sprintf("directory %s has no index file %s", e.Path, e.Index)
+}
+
+func (e *ErrCheckMissingIndex) Is(target error) bool {
+	return target == ErrDoesNotExist
+}
+
+func (e *ErrCheckMalformedIndex) Error() string {
+	return fmt.Sprintf("index file %s in %s is malformed: %v", e.Index, e.Path, e.Err)
+}
+
+func (e *ErrCheckMalformedIndex) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckMalformedIndex) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrCheckFDExhausted) Error() string {
+	return fmt.Sprintf("file descriptors exhausted while opening %s: %v", e.Path, e.Err)
+}
+
+func (e *ErrCheckFDExhausted) Unwrap() error {
+	return e.Err
+}
+
+func (e *ErrCheckDirTreePermissions) Error() string {
+	_, extra := common.ModeDiff(e.Mode, e.Max)
+	return fmt.Sprintf("permissions too open for %s: expected at most %o, got %o, extra bits: %04o", e.Path, e.Max, e.Mode, extra)
+}
+
+func (e *ErrCheckDirTreePermissions) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+// checkReadyMarker verifies the marker file inside path exists and, when token is set, that it contains token
+func checkReadyMarker(fsys fs.FS, path, marker, token string) error {
+	markerPath := common.JoinFS(fsys, path, marker)
+	info, err := common.StatFS(fsys, markerPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &ErrCheckNotReady{Path: path, Marker: marker}
+		}
+		return fmt.Errorf("failed to stat ready marker %s: %w", markerPath, err)
+	}
+	if !info.Mode().IsRegular() {
+		return &ErrCheckNotReady{Path: path, Marker: marker}
+	}
+	if token == "" {
+		return nil
+	}
+	contents, err := common.ReadFileFS(fsys, markerPath)
+	if err != nil {
+		return fmt.Errorf("failed to read ready marker %s: %w", markerPath, err)
+	}
+	if !strings.Contains(string(contents), token) {
+		return &ErrCheckNotReady{Path: path, Marker: marker}
+	}
+	return nil
+}
+
+// fdExhausted converts an EMFILE/ENFILE error raised while opening path into ErrCheckFDExhausted
+func fdExhausted(path string, err error) error {
+	if err != nil && common.IsFDExhausted(err) {
+		return &ErrCheckFDExhausted{Path: path, Err: err}
+	}
+	return err
+}
@@ -1,11 +1,18 @@
 package directory
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
 )
 
 func TestDirectory(t *testing.T) {
@@ -53,6 +60,9 @@ func TestDirectory(t *testing.T) {
 		// Basic existence tests
 		{"Valid existing directory", testDir, Options{Exists: true}, false},
 		{"Non-existent directory with Exists=false", nonExistentDir, Options{Exists: false}, false},
+		{"Existing directory with zero Options", testDir, Options{}, false},
+		{"Existing directory with MustNotExist", testDir, Options{MustNotExist: true}, true},
+		{"Non-existent directory with MustNotExist", nonExistentDir, Options{MustNotExist: true}, false},
 		{"Non-existent directory with Exists=true", nonExistentDir, Options{Exists: true}, true},
 		{"Non-directory path", testFile, Options{Exists: true}, true},
 
@@ -192,4 +202,748 @@ func BenchmarkDirectory(b *testing.B) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestNewCreate(t *testing.T) {
+	dir := t.TempDir()
+	original := &Create{
+		Kind:      IfNotExists,
+		FileMode:  0750,
+		Path:      filepath.Join(dir, "nested", "path"),
+		ForceMode: true,
+	}
+
+	t.Run("Round trips every field", func(t *testing.T) {
+		got := NewCreate(original)
+		if got == original {
+			t.Fatal("NewCreate() should return a copy, not the argument")
+		}
+		if *got != *original {
+			t.Errorf("NewCreate() = %+v, want %+v", *got, *original)
+		}
+		if empty := NewCreate(nil); *empty != (Create{}) {
+			t.Errorf("NewCreate(nil) = %+v, want zero value", *empty)
+		}
+	})
+
+	t.Run("Doc comment example creates the directory", func(t *testing.T) {
+		if err := NewCreate(original).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if err := Directory(original.Path, Options{Exists: true}); err != nil {
+			t.Errorf("Directory() error = %v", err)
+		}
+	})
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		err := NewCreate(&Create{Kind: CreateKind(42), Path: dir}).Run()
+		if !errors.Is(err, ErrUnknownCreateKind) {
+			t.Errorf("Run() error = %v, want ErrUnknownCreateKind", err)
+		}
+	})
+}
+
+func TestCreateDryRun(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "release")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	keep := filepath.Join(target, "keep.txt")
+	if err := os.WriteFile(keep, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	replace := &Create{Kind: IfExists, Path: target, FileMode: 0700, ForceMode: true, DryRun: true}
+	if err := replace.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Errorf("dry run removed %s: %v", keep, err)
+	}
+	if info, err := os.Stat(target); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("dry run changed %s: %v, %v", target, info, err)
+	}
+	plan, err := replace.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	want := []string{
+		"remove directory " + target + " and everything in it",
+		"create directory " + target + " with mode -rwx------",
+		"chmod directory " + target + " to -rwx------",
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("Plan() = %q, want %q", plan, want)
+	}
+
+	nested := filepath.Join(dir, "a", "b")
+	plan, err = (&Create{Kind: IfNotExists, Path: nested, FileMode: 0755, DryRun: true}).Plan()
+	if err != nil || len(plan) != 1 {
+		t.Errorf("Plan() = %q, %v, want a single create", plan, err)
+	}
+	if err := (&Create{Kind: IfNotExists, Path: nested, DryRun: true}).Run(); err != nil {
+		t.Errorf("Run() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a")); !os.IsNotExist(err) {
+		t.Errorf("dry run created %s", filepath.Join(dir, "a"))
+	}
+	if plan, err := (&Create{Kind: IfNotExists, Path: target}).Plan(); err != nil || len(plan) != 0 {
+		t.Errorf("Plan() for an existing directory = %q, %v, want nothing to do", plan, err)
+	}
+	if err := (&Create{Kind: IfExists, Path: keep, DryRun: true}).Run(); !errors.Is(err, ErrNotDirectory) {
+		t.Errorf("Run() over a file error = %v, want ErrNotDirectory", err)
+	}
+
+	t.Run("Without DryRun the directory is replaced", func(t *testing.T) {
+		replace.DryRun = false
+		if err := replace.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if _, err := os.Stat(keep); !os.IsNotExist(err) {
+			t.Errorf("Run() kept %s: %v", keep, err)
+		}
+		if info, err := os.Stat(target); err != nil || info.Mode().Perm() != 0700 {
+			t.Errorf("Run() left %s as %v, %v", target, info, err)
+		}
+	})
+}
+
+func TestCreateBackup(t *testing.T) {
+	dir := t.TempDir()
+	backups := filepath.Join(dir, "backups")
+	target := filepath.Join(dir, "release")
+	populate := func(t *testing.T) {
+		t.Helper()
+		if err := os.MkdirAll(target, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(target, "old.txt"), []byte("old"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	t.Run("Replace", func(t *testing.T) {
+		populate(t)
+		backup, err := (&Create{Kind: IfExists, Path: target, FileMode: 0755, BackupDir: backups}).RunWithBackup()
+		if err != nil {
+			t.Fatalf("RunWithBackup() error = %v", err)
+		}
+		if data, err := os.ReadFile(filepath.Join(backup, "old.txt")); err != nil || string(data) != "old" {
+			t.Errorf("backup %s holds %q, %v, want old.txt", backup, data, err)
+		}
+		if entries, err := os.ReadDir(target); err != nil || len(entries) != 0 {
+			t.Errorf("%s = %v, %v, want a new empty directory", target, entries, err)
+		}
+	})
+
+	t.Run("Failed create restores the backup", func(t *testing.T) {
+		if err := os.RemoveAll(target); err != nil {
+			t.Fatalf("RemoveAll() error = %v", err)
+		}
+		populate(t)
+		defer func(original func(string, os.FileMode) error) { mkdirAll = original }(mkdirAll)
+		mkdirAll = func(path string, perm os.FileMode) error {
+			if err := os.Mkdir(path, perm); err != nil {
+				return err
+			}
+			return errors.New("disk full")
+		}
+		before, _ := os.ReadDir(backups)
+		backup, err := (&Create{Kind: IfExists, Path: target, FileMode: 0755, BackupDir: backups}).RunWithBackup()
+		if err == nil || backup != "" {
+			t.Fatalf("RunWithBackup() = %q, %v, want an error and no backup", backup, err)
+		}
+		if data, err := os.ReadFile(filepath.Join(target, "old.txt")); err != nil || string(data) != "old" {
+			t.Errorf("%s holds %q, %v after the failed create, want the restored old.txt", target, data, err)
+		}
+		if after, _ := os.ReadDir(backups); len(after) != len(before) {
+			t.Errorf("backup directory holds %d entries, want %d", len(after), len(before))
+		}
+	})
+}
+
+func TestCreateRequireBaseDir(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "workspaces")
+	if err := os.Mkdir(base, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	outside := filepath.Join(root, "escape")
+	if err := os.MkdirAll(filepath.Join(outside, "data"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+
+	if err := (&Create{Kind: IfNotExists, Path: filepath.Join(base, "team", "project"), FileMode: 0755, RequireBaseDir: base}).Run(); err != nil {
+		t.Errorf("Run() on a nested path error = %v", err)
+	}
+	for _, kind := range []CreateKind{IfNotExists, IfExists} {
+		err := (&Create{Kind: kind, Path: filepath.Join(base, "..", "escape"), FileMode: 0755, RequireBaseDir: base}).Run()
+		var badBase *ErrCheckDirBadBaseDir
+		if !errors.As(err, &badBase) || !errors.Is(err, ErrBadBaseDir) {
+			t.Errorf("Run() with Kind %d error = %v, want *ErrCheckDirBadBaseDir", kind, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "data")); err != nil {
+		t.Errorf("IfExists outside the base removed %s: %v", outside, err)
+	}
+	if _, err := (&Create{Kind: IfExists, Path: filepath.Join(base, "..", "escape"), RequireBaseDir: base}).Plan(); !errors.Is(err, ErrBadBaseDir) {
+		t.Errorf("Plan() error = %v, want ErrBadBaseDir", err)
+	}
+
+	t.Run("Nested path below a symlink out of the base", func(t *testing.T) {
+		if err := os.Symlink(filepath.Join("..", "escape"), filepath.Join(base, "link")); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+		err := (&Create{Kind: IfNotExists, Path: filepath.Join(base, "link", "new", "deep"), FileMode: 0755, RequireBaseDir: base}).Run()
+		if !errors.Is(err, ErrBadBaseDir) {
+			t.Errorf("Run() error = %v, want ErrBadBaseDir", err)
+		}
+		if _, err := os.Stat(filepath.Join(outside, "new")); !os.IsNotExist(err) {
+			t.Errorf("Run() created %s outside the base: %v", filepath.Join(outside, "new"), err)
+		}
+	})
+}
+
+func TestCreateRejectSymlinkComponents(t *testing.T) {
+	// resolve the temp dir itself, which sits below a symlink on some systems (/var on macOS)
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	victim := filepath.Join(root, "etc")
+	if err := os.MkdirAll(filepath.Join(root, "srv"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.Mkdir(victim, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	link := filepath.Join(root, "srv", "www")
+	if err := os.Symlink(victim, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := (&Create{Kind: IfNotExists, Path: filepath.Join(root, "srv", "data", "cache"), FileMode: 0755, RejectSymlinkComponents: true}).Run(); err != nil {
+		t.Errorf("Run() with real parents error = %v", err)
+	}
+	for _, path := range []string{filepath.Join(link, "sudoers.d"), filepath.Join(link, "cron.d", "jobs")} {
+		for _, kind := range []CreateKind{IfNotExists, IfExists, EnsureValid} {
+			create := &Create{Kind: kind, Path: path, FileMode: 0755, RejectSymlinkComponents: true}
+			var linkErr *ErrCheckSymlinkInPath
+			if err := create.Run(); !errors.As(err, &linkErr) || linkErr.Link != link || !errors.Is(err, ErrSymlinkMismatch) {
+				t.Errorf("Run(%s) with Kind %d error = %v, want *ErrCheckSymlinkInPath naming %s", path, kind, err, link)
+			}
+			if _, err := create.Plan(); !errors.Is(err, ErrSymlinkMismatch) {
+				t.Errorf("Plan(%s) with Kind %d error = %v, want ErrSymlinkMismatch", path, kind, err)
+			}
+		}
+	}
+	if entries, err := os.ReadDir(victim); err != nil || len(entries) > 0 {
+		t.Errorf("Run() created directories through the symlink in %s: %v, %v", victim, entries, err)
+	}
+}
+
+func TestCreateSync(t *testing.T) {
+	dir := t.TempDir()
+	var synced []string
+	defer func(original func(string) error) { syncDir = original }(syncDir)
+	syncDir = func(dir string) error {
+		synced = append(synced, dir)
+		return common.SyncDir(dir)
+	}
+
+	path := filepath.Join(dir, "a", "b", "c")
+	if err := (&Create{Kind: IfNotExists, Path: path, FileMode: 0755, Sync: true}).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []string{filepath.Join(dir, "a", "b"), filepath.Join(dir, "a"), dir}
+	if !reflect.DeepEqual(synced, want) {
+		t.Errorf("synced %q, want the parent of every created directory %q", synced, want)
+	}
+
+	synced = nil
+	if err := (&Create{Kind: IfNotExists, Path: filepath.Join(dir, "a", "d"), FileMode: 0755, Sync: true}).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []string{filepath.Join(dir, "a")}; !reflect.DeepEqual(synced, want) {
+		t.Errorf("synced %q, want %q", synced, want)
+	}
+
+	synced = nil
+	if err := (&Create{Kind: IfNotExists, Path: filepath.Join(dir, "e"), FileMode: 0755}).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(synced) != 0 {
+		t.Errorf("synced %q without Sync", synced)
+	}
+}
+
+func TestDirectoryReadyMarker(t *testing.T) {
+	baseDir := t.TempDir()
+	readyDir := filepath.Join(baseDir, "ready")
+	pendingDir := filepath.Join(baseDir, "pending")
+	for _, dir := range []string{readyDir, pendingDir} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(readyDir, ".ready"), []byte("build-42\n"), 0644); err != nil {
+		t.Fatalf("Failed to create marker: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(pendingDir, ".ready"), 0755); err != nil {
+		t.Fatalf("Failed to create marker directory: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Ready directory", readyDir, Options{Exists: true, RequireReadyMarker: ".ready"}, false},
+		{"Ready directory with token", readyDir, Options{Exists: true, RequireReadyMarker: ".ready", ReadyMarkerToken: "build-42"}, false},
+		{"Ready directory with wrong token", readyDir, Options{Exists: true, RequireReadyMarker: ".ready", ReadyMarkerToken: "build-43"}, true},
+		{"Missing marker", readyDir, Options{Exists: true, RequireReadyMarker: ".done"}, true},
+		{"Marker is a directory", pendingDir, Options{Exists: true, RequireReadyMarker: ".ready"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Directory(tt.path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Directory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var notReady *ErrCheckNotReady
+			if tt.wantErr && !errors.As(err, &notReady) {
+				t.Errorf("Directory() error = %v, want ErrCheckNotReady", err)
+			}
+		})
+	}
+}
+
+func TestDirectoryAll(t *testing.T) {
+	baseDir := t.TempDir()
+	dir := filepath.Join(baseDir, "uploads")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+
+	t.Run("Collects every failure", func(t *testing.T) {
+		errs := DirectoryAll(dir, Options{
+			Exists:             true,
+			RequirePrefix:      "downloads", // fails
+			RequireBaseDir:     "/invalid",  // fails
+			ReadOnly:           true,        // fails, 0755 has write bits
+			MorePermissiveThan: 0500,        // passes
+			RequireReadyMarker: ".ready",    // fails
+		})
+		if len(errs) != 4 {
+			t.Fatalf("DirectoryAll() returned %d errors, want 4: %v", len(errs), errs)
+		}
+		var notReady *ErrCheckNotReady
+		if !errors.As(errs[0], &notReady) {
+			t.Errorf("errs[0] = %T, want *ErrCheckNotReady", errs[0])
+		}
+		var baseErr *ErrCheckDirBadBaseDir
+		if !errors.As(errs[2], &baseErr) {
+			t.Errorf("errs[2] = %T, want *ErrCheckDirBadBaseDir", errs[2])
+		}
+		var permErr *ErrCheckDirOpenPermissions
+		if !errors.As(errs[3], &permErr) {
+			t.Errorf("errs[3] = %T, want *ErrCheckDirOpenPermissions", errs[3])
+		}
+	})
+
+	t.Run("Passing directory", func(t *testing.T) {
+		if errs := DirectoryAll(dir, Options{Exists: true, RequirePrefix: "up"}); errs != nil {
+			t.Errorf("DirectoryAll() = %v, want nil", errs)
+		}
+	})
+
+	t.Run("Missing directory short-circuits", func(t *testing.T) {
+		errs := DirectoryAll(filepath.Join(baseDir, "missing"), Options{Exists: true, RequirePrefix: "x", ReadOnly: true})
+		if len(errs) != 1 {
+			t.Errorf("DirectoryAll() = %v, want exactly one error", errs)
+		}
+	})
+}
+
+func TestDirectoryContext(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("Cancelled before the filesystem is touched", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		target := filepath.Join(dir, "never-created")
+		err := DirectoryContext(ctx, target, Options{Create: Create{Kind: IfNotExists, FileMode: 0755}})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("DirectoryContext() error = %v, want context.Canceled", err)
+		}
+		if _, err := os.Stat(target); !os.IsNotExist(err) {
+			t.Errorf("DirectoryContext() created %s despite a cancelled context", target)
+		}
+	})
+
+	t.Run("1ns timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		if err := DirectoryContext(ctx, dir, Options{Exists: true}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("DirectoryContext() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("Live context", func(t *testing.T) {
+		if err := DirectoryContext(context.Background(), dir, Options{Exists: true}); err != nil {
+			t.Errorf("DirectoryContext() error = %v", err)
+		}
+	})
+}
+
+func TestDirectorySentinelErrors(t *testing.T) {
+	baseDir := t.TempDir()
+	dir := filepath.Join(baseDir, "uploads")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	regularFile := filepath.Join(baseDir, "file.txt")
+	if err := os.WriteFile(regularFile, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		opts Options
+		want error
+	}{
+		{"Missing directory", filepath.Join(baseDir, "missing"), Options{Exists: true}, ErrDoesNotExist},
+		{"Unexpected directory", dir, Options{MustNotExist: true}, ErrAlreadyExists},
+		{"Regular file", regularFile, Options{Exists: true}, ErrNotDirectory},
+		{"Time", dir, Options{Exists: true, ModifiedBefore: time.Now().Add(-time.Hour)}, ErrTimeMismatch},
+		{"Prefix", dir, Options{Exists: true, RequirePrefix: "downloads"}, ErrNameMismatch},
+		{"Suffix", dir, Options{Exists: true, RequireSuffix: "-tmp"}, ErrNameMismatch},
+		{"Base dir", dir, Options{Exists: true, RequireBaseDir: filepath.Join(baseDir, "other")}, ErrBadBaseDir},
+		{"Read only", dir, Options{Exists: true, ReadOnly: true}, ErrPermissionMismatch},
+		{"Owner", dir, Options{Exists: true, RequireOwner: "nobody-checkfs"}, ErrOwnerMismatch},
+		{"Group", dir, Options{Exists: true, RequireGroup: "nobody-checkfs"}, ErrGroupMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Directory(tt.path, tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Directory() error = %v, want errors.Is(err, %v)", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirectoryRejectBrokenSymlink(t *testing.T) {
+	dir := t.TempDir()
+	realDir := filepath.Join(dir, "real")
+	if err := os.Mkdir(realDir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.Symlink("real", filepath.Join(dir, "valid")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("gone", filepath.Join(dir, "broken")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	for _, path := range []string{realDir, filepath.Join(dir, "valid")} {
+		if err := Directory(path, Options{Exists: true, RejectBrokenSymlink: true}); err != nil {
+			t.Errorf("Directory(%s) error = %v", path, err)
+		}
+	}
+
+	var brokenErr *ErrCheckBrokenSymlink
+	err := Directory(filepath.Join(dir, "broken"), Options{Exists: true, RejectBrokenSymlink: true})
+	if !errors.As(err, &brokenErr) || brokenErr.Target != filepath.Join(dir, "gone") {
+		t.Errorf("Directory() error = %v, want *ErrCheckBrokenSymlink targeting %s", err, filepath.Join(dir, "gone"))
+	}
+	if !errors.Is(err, ErrSymlinkMismatch) {
+		t.Errorf("Directory() error = %v, want ErrSymlinkMismatch", err)
+	}
+}
+
+func TestDirectoryStrict(t *testing.T) {
+	// resolve the temp dir itself, which sits below a symlink on some systems (/var on macOS)
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	realDir := filepath.Join(dir, "real")
+	if err := os.MkdirAll(filepath.Join(realDir, "data"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	via := filepath.Join(dir, "via")
+	if err := os.Symlink("real", via); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	create := Create{Kind: IfNotExists, FileMode: 0755}
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     Options
+		wantLink string // wantLink is the component ErrCheckSymlinkInPath names, "" when Directory passes
+	}{
+		{"Real path", filepath.Join(realDir, "data"), Options{Exists: true, Strict: true}, ""},
+		{"Intermediate symlink without Strict", filepath.Join(via, "data"), Options{Exists: true}, ""},
+		{"Intermediate symlink", filepath.Join(via, "data"), Options{Exists: true, Strict: true}, via},
+		{"Leaf symlink", via, Options{Exists: true, Strict: true}, via},
+		{"Create through a symlink", filepath.Join(via, "new"), Options{Strict: true, Create: create}, via},
+		{"WillCreate through a symlink", filepath.Join(via, "new"), Options{Strict: true, WillCreate: true}, via},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Directory(tt.path, tt.opts)
+			if tt.wantLink == "" {
+				if err != nil {
+					t.Errorf("Directory() error = %v", err)
+				}
+				return
+			}
+			var linkErr *ErrCheckSymlinkInPath
+			if !errors.As(err, &linkErr) || linkErr.Link != tt.wantLink {
+				t.Fatalf("Directory() error = %v, want *ErrCheckSymlinkInPath naming %s", err, tt.wantLink)
+			}
+			if !errors.Is(err, ErrSymlinkMismatch) {
+				t.Errorf("Directory() error = %v, want ErrSymlinkMismatch", err)
+			}
+		})
+	}
+	if _, err := os.Lstat(filepath.Join(realDir, "new")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Create ran through the symlink: %v", err)
+	}
+
+	if err := Directory(filepath.Join(realDir, "new"), Options{Strict: true, Create: create}); err != nil {
+		t.Fatalf("Directory() error = %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(realDir, "new")); err != nil || !info.IsDir() {
+		t.Errorf("Create did not create %s: %v", filepath.Join(realDir, "new"), err)
+	}
+}
+
+func TestDirectoryRequireBaseDirs(t *testing.T) {
+	root := t.TempDir()
+	bases := []string{filepath.Join(root, "data"), filepath.Join(root, "uploads"), filepath.Join(root, "work")}
+	inSecond := filepath.Join(bases[1], "tenant")
+	outside := filepath.Join(root, "etc", "cron.d")
+	for _, dir := range append(bases, inSecond, outside) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+
+	if err := Directory(inSecond, Options{Exists: true, RequireBaseDirs: bases}); err != nil {
+		t.Errorf("Directory() inside the second of three bases error = %v", err)
+	}
+	var badBase *ErrCheckDirBadBaseDirs
+	err := Directory(outside, Options{Exists: true, RequireBaseDirs: bases})
+	if !errors.As(err, &badBase) || !errors.Is(err, ErrBadBaseDir) || len(badBase.BaseDirs) != len(bases) {
+		t.Fatalf("Directory() outside all three bases error = %v, want *ErrCheckDirBadBaseDirs listing %v", err, bases)
+	}
+	for _, base := range bases {
+		if !strings.Contains(err.Error(), base) {
+			t.Errorf("Directory() error = %q, want it to list %s", err, base)
+		}
+	}
+	if err := Directory(outside, Options{Exists: true, RequireBaseDir: filepath.Join(root, "etc"), RequireBaseDirs: bases}); err != nil {
+		t.Errorf("Directory() inside RequireBaseDir alongside RequireBaseDirs error = %v", err)
+	}
+	if err := Directory(inSecond, Options{RequireBaseDirs: []string{""}}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Directory() with an empty base error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestDirectoryForbidBaseDirs(t *testing.T) {
+	root := t.TempDir()
+	denied := filepath.Join(root, "etc")
+	for _, dir := range []string{filepath.Join(denied, "cron.d"), filepath.Join(root, "srv", "app")} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	link := filepath.Join(root, "srv", "jobs")
+	if err := os.Symlink(filepath.Join(denied, "cron.d"), link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	opts := Options{Exists: true, ForbidBaseDirs: []string{denied}}
+	if err := Directory(filepath.Join(root, "srv", "app"), opts); err != nil {
+		t.Errorf("Directory() outside the denied directory error = %v", err)
+	}
+	for _, path := range []string{filepath.Join(denied, "cron.d"), link} {
+		var forbidden *ErrCheckDirForbiddenBaseDir
+		if err := Directory(path, opts); !errors.As(err, &forbidden) || forbidden.BaseDir != denied || !errors.Is(err, ErrBadBaseDir) {
+			t.Errorf("Directory(%s) error = %v, want *ErrCheckDirForbiddenBaseDir naming %s", path, err, denied)
+		}
+	}
+}
+
+func TestDirectoryRequireSuffix(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "build-tmp")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireSuffix: "-tmp"}); err != nil {
+		t.Errorf("Directory() error = %v", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireSuffix: ""}); err != nil {
+		t.Errorf("Directory() with empty suffix error = %v", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireSuffix: "-cache"}); !errors.Is(err, ErrNameMismatch) {
+		t.Errorf("Directory() error = %v, want ErrNameMismatch", err)
+	}
+}
+
+func TestDirectoryRequireNameMatch(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "release-v1.4.2")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireNameMatch: "release-v[0-9]*"}); err != nil {
+		t.Errorf("Directory() error = %v", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireNameMatch: "build-*"}); !errors.Is(err, ErrNameMismatch) {
+		t.Errorf("Directory() error = %v, want ErrNameMismatch", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireNameMatch: "["}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Directory() with an invalid pattern error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Zero options", Options{}, false},
+		{"Consistent permissions", Options{MorePermissiveThan: 0700, LessPermissiveThan: 0755, RequireWrite: true}, false},
+		{"Consistent entries", Options{RequireNonEmpty: true, MinEntries: 1, MaxEntries: 5}, false},
+		{"ReadOnly and RequireWrite", Options{ReadOnly: true, RequireWrite: true}, true},
+		{"MorePermissiveThan and LessPermissiveThan", Options{MorePermissiveThan: 0755, LessPermissiveThan: 0700}, true},
+		{"ReadOnly and MorePermissiveThan", Options{ReadOnly: true, MorePermissiveThan: 0700}, true},
+		{"RequireWrite and LessPermissiveThan", Options{RequireWrite: true, LessPermissiveThan: 0555}, true},
+		{"RequireEmpty and RequireNonEmpty", Options{RequireEmpty: true, RequireNonEmpty: true}, true},
+		{"RequireEmpty and MinEntries", Options{RequireEmpty: true, MinEntries: 1}, true},
+		{"RequireEmpty and ContainsGlob", Options{RequireEmpty: true, ContainsGlob: "*.txt"}, true},
+		{"MinEntries and MaxEntries", Options{MinEntries: 5, MaxEntries: 2}, true},
+		{"Consistent time window", Options{ModifiedAfter: time.Unix(100, 0), ModifiedBefore: time.Unix(200, 0)}, false},
+		{"ModifiedAfter without ModifiedBefore", Options{ModifiedAfter: time.Unix(100, 0)}, false},
+		{"ModifiedAfter after ModifiedBefore", Options{ModifiedAfter: time.Unix(200, 0), ModifiedBefore: time.Unix(100, 0)}, true},
+		{"CreatedAfter after CreatedBefore", Options{CreatedAfter: time.Unix(200, 0), CreatedBefore: time.Unix(100, 0)}, true},
+		{"Exists and MustNotExist", Options{Exists: true, MustNotExist: true}, true},
+		{"MaxDepth and SkipHidden with a tree walk", Options{MaxTotalSize: 1 << 20, MaxDepth: 2, SkipHidden: true}, false},
+		{"Negative MaxDepth", Options{Recursive: true, MaxEntries: 5, MaxDepth: -1}, true},
+		{"MaxDepth without a tree walk", Options{MaxEntries: 5, MaxDepth: 1}, true},
+		{"SkipHidden without a tree walk", Options{SkipHidden: true}, true},
+	}
+	path := filepath.Join(t.TempDir(), "missing")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Validate() error = %v, want ErrInvalidOptions", err)
+			}
+			if err := Directory(path, tt.opts); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Directory() error = %v, want ErrInvalidOptions before the missing directory is noticed", err)
+			}
+		})
+	}
+}
+
+func TestDirectoryTimeWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stamped")
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	stamp := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, stamp, stamp); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"ModifiedAfter earlier", Options{Exists: true, ModifiedAfter: stamp.Add(-time.Hour)}, false},
+		{"ModifiedAfter exact", Options{Exists: true, ModifiedAfter: stamp}, false},
+		{"ModifiedAfter later", Options{Exists: true, ModifiedAfter: stamp.Add(time.Hour)}, true},
+		{"In window", Options{Exists: true, ModifiedAfter: stamp.Add(-time.Hour), ModifiedBefore: stamp.Add(time.Hour)}, false},
+		{"Too old for window", Options{Exists: true, ModifiedAfter: stamp.Add(time.Hour), ModifiedBefore: stamp.Add(2 * time.Hour)}, true},
+		{"Too new for window", Options{Exists: true, ModifiedAfter: stamp.Add(-2 * time.Hour), ModifiedBefore: stamp.Add(-time.Hour)}, true},
+		{"CreatedAfter earlier", Options{Exists: true, CreatedAfter: stamp.Add(-time.Hour)}, false},
+		{"CreatedAfter future", Options{Exists: true, CreatedAfter: time.Now().Add(time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Directory(path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Directory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrTimeMismatch) {
+				t.Errorf("Directory() error = %v, want ErrTimeMismatch", err)
+			}
+		})
+	}
+}
+
+func TestDirectoryTotalSize(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "sub", "deeper"), 0755); err != nil {
+		t.Fatalf("Failed to create nested directories: %v", err)
+	}
+	for name, size := range map[string]int{"a.txt": 100, "sub/b.txt": 400, "sub/deeper/c.txt": 524} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(name)), make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{"Under budget", Options{Exists: true, MaxTotalSize: 2048}, nil},
+		{"Exact budget", Options{Exists: true, MaxTotalSize: 1024}, nil},
+		{"Over budget", Options{Exists: true, MaxTotalSize: 1023}, &ErrCheckDirTooLarge{Path: root, Total: 1024, Limit: 1023}},
+		{"Over minimum", Options{Exists: true, MinTotalSize: 1024}, nil},
+		{"Under minimum", Options{Exists: true, MinTotalSize: 1025}, &ErrCheckDirTooSmall{Path: root, Total: 1024, Limit: 1025}},
+		{"Window", Options{Exists: true, MinTotalSize: 1000, MaxTotalSize: 1100}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Directory(root, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Directory() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr.Error() || !errors.Is(err, ErrSizeMismatch) {
+				t.Errorf("Directory() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := DirectoryContext(ctx, root, Options{Exists: true, MaxTotalSize: 1}); !errors.Is(err, context.Canceled) {
+		t.Errorf("DirectoryContext() with a canceled context error = %v, want context.Canceled", err)
+	}
+	if err := (Options{MinTotalSize: 10, MaxTotalSize: 5}).Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Validate() with MinTotalSize above MaxTotalSize error = %v, want ErrInvalidOptions", err)
+	}
+}
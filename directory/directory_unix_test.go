@@ -0,0 +1,355 @@
+//go:build unix
+
+package directory
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestDirectoryOwnerNames(t *testing.T) {
+	dir := t.TempDir()
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot resolve current user: %v", err)
+	}
+	group, err := user.LookupGroupId(strconv.Itoa(os.Getgid()))
+	if err != nil {
+		t.Skipf("cannot resolve current group: %v", err)
+	}
+
+	if err := Directory(dir, Options{Exists: true, RequireOwner: current.Uid, RequireOwnerName: current.Username}); err != nil {
+		t.Errorf("Directory() error = %v, want nil", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireGroup: group.Gid, RequireGroupName: group.Name}); err != nil {
+		t.Errorf("Directory() error = %v, want nil", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireOwnerName: "not-" + current.Username}); !errors.Is(err, ErrOwnerMismatch) {
+		t.Errorf("Directory() error = %v, want ErrOwnerMismatch", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireGroupName: "not-" + group.Name}); !errors.Is(err, ErrGroupMismatch) {
+		t.Errorf("Directory() error = %v, want ErrGroupMismatch", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireOwnedByCurrentUser: true, RequireOwnedByCurrentGroup: true}); err != nil {
+		t.Errorf("Directory() owned by the current user error = %v, want nil", err)
+	}
+}
+
+func TestCreateForceMode(t *testing.T) {
+	dir := t.TempDir()
+	defer syscall.Umask(syscall.Umask(0077))
+
+	// mode returns the permission bits of path
+	mode := func(t *testing.T, path string) os.FileMode {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	t.Run("Umask strips bits without ForceMode", func(t *testing.T) {
+		path := filepath.Join(dir, "plain")
+		if err := NewCreate(&Create{Kind: IfNotExists, Path: path, FileMode: 0775}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := mode(t, path); got != 0700 {
+			t.Errorf("mode = %o, want %o", got, 0700)
+		}
+	})
+
+	t.Run("ForceMode sets the exact mode", func(t *testing.T) {
+		parent := filepath.Join(dir, "tenant")
+		path := filepath.Join(parent, "shared")
+		if err := NewCreate(&Create{Kind: IfNotExists, Path: path, FileMode: 0775, ForceMode: true}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := mode(t, path); got != 0775 {
+			t.Errorf("mode = %o, want %o", got, 0775)
+		}
+		if got := mode(t, parent); got != 0700 {
+			t.Errorf("parent mode = %o, want the umask-filtered %o", got, 0700)
+		}
+	})
+
+	t.Run("Existing directory is left alone without ForceMode", func(t *testing.T) {
+		path := filepath.Join(dir, "existing")
+		if err := os.Mkdir(path, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewCreate(&Create{Kind: IfNotExists, Path: path, FileMode: 0755}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := mode(t, path); got != 0700 {
+			t.Errorf("mode = %o, want %o", got, 0700)
+		}
+	})
+
+	t.Run("Existing directory is chmod'd with ForceMode", func(t *testing.T) {
+		path := filepath.Join(dir, "forced")
+		if err := os.Mkdir(path, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewCreate(&Create{Kind: IfNotExists, Path: path, FileMode: 0755, ForceMode: true}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := mode(t, path); got != 0755 {
+			t.Errorf("mode = %o, want %o", got, 0755)
+		}
+	})
+}
+
+func TestDirectoryRecursiveOwner(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create nested directories: %v", err)
+	}
+	child := filepath.Join(nested, "data.txt")
+	if err := os.WriteFile(child, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create nested file: %v", err)
+	}
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+
+	opts := Options{Exists: true, RequireOwner: uid, RequireGroup: gid, RecursiveOwner: true}
+	if err := Directory(root, opts); err != nil {
+		t.Fatalf("Directory() on a uniformly owned tree error = %v, want nil", err)
+	}
+	if err := (Options{RecursiveOwner: true}).Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Validate() without RequireOwner or RequireGroup error = %v, want ErrInvalidOptions", err)
+	}
+
+	if os.Geteuid() != 0 {
+		t.Skip("changing ownership needs root")
+	}
+	const other = 4242
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	if err := os.WriteFile(outside, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create outside file: %v", err)
+	}
+	if err := os.Chown(outside, other, other); err != nil {
+		t.Fatalf("Failed to chown outside file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := Directory(root, opts); err != nil {
+		t.Fatalf("Directory() with a symlink out of the tree error = %v, want nil", err)
+	}
+
+	tests := []struct {
+		name     string
+		uid, gid int
+		want     error
+	}{
+		{"Owner differs", other, os.Getgid(), &ErrCheckDirBadOwner{Path: child, Expected: uid, Actual: strconv.Itoa(other)}},
+		{"Group differs", os.Getuid(), other, &ErrCheckDirBadGroup{Path: child, Expected: gid, Actual: strconv.Itoa(other)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.Chown(child, tt.uid, tt.gid); err != nil {
+				t.Fatalf("Failed to chown nested file: %v", err)
+			}
+			defer os.Chown(child, os.Getuid(), os.Getgid())
+			err := Directory(root, opts)
+			if err == nil || err.Error() != tt.want.Error() {
+				t.Errorf("Directory() error = %v, want %v", err, tt.want)
+			}
+			if err := Directory(root, Options{Exists: true, RequireOwner: uid, RequireGroup: gid}); err != nil {
+				t.Errorf("Directory() without RecursiveOwner error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestDirectoryRecursivePermissions(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create nested directories: %v", err)
+	}
+	child := filepath.Join(nested, "data.txt")
+	if err := os.WriteFile(child, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create nested file: %v", err)
+	}
+	outside := filepath.Join(t.TempDir(), "open.txt")
+	if err := os.WriteFile(outside, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create outside file: %v", err)
+	}
+	if err := os.Chmod(outside, 0666); err != nil {
+		t.Fatalf("Failed to chmod outside file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	opts := Options{Exists: true, RecursiveLessPermissiveThan: 0775}
+	if err := Directory(root, opts); err != nil {
+		t.Fatalf("Directory() with a symlink to a world-writable file error = %v, want nil", err)
+	}
+
+	if err := os.Chmod(child, 0666); err != nil {
+		t.Fatalf("Failed to chmod nested file: %v", err)
+	}
+	err := Directory(root, opts)
+	var open *ErrCheckDirTreePermissions
+	if !errors.As(err, &open) || !errors.Is(err, ErrPermissionMismatch) {
+		t.Fatalf("Directory() error = %v, want ErrCheckDirTreePermissions", err)
+	}
+	if open.Path != child || open.Mode != 0666 || open.Max != 0775 {
+		t.Errorf("Directory() error = %+v, want %s with mode 0666", open, child)
+	}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		wantErr    error
+	}{
+		{"Bounded walk reaches the file", 3, ErrPermissionMismatch},
+		{"Bounded walk stops first", 2, ErrSizeMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := opts
+			opts.RecursiveMaxEntries = tt.maxEntries
+			if err := Directory(root, opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Directory() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := (Options{RecursiveMaxEntries: -1}).Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Validate() with a negative RecursiveMaxEntries error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestDirectorySpecialBits(t *testing.T) {
+	root := t.TempDir()
+	withMode := func(name string, mode os.FileMode) string {
+		path := filepath.Join(root, name)
+		if err := os.Mkdir(path, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Skipf("cannot chmod %s to %s: %v", name, mode, err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode() != os.ModeDir|mode {
+			t.Skipf("the filesystem did not keep %s on %s", mode, name)
+		}
+		return path
+	}
+	plain := withMode("plain", 0755)
+	shared := withMode("shared", 0777|os.ModeSticky)
+	setgid := withMode("setgid", 0775|os.ModeSetgid)
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Plain directory", plain, Options{Exists: true, ForbidSetuid: true, ForbidSetgid: true}, false},
+		{"Sticky", shared, Options{Exists: true, RequireSticky: true, LessPermissiveThan: 0777}, false},
+		{"Sticky missing", plain, Options{Exists: true, RequireSticky: true}, true},
+		{"Setgid", setgid, Options{Exists: true, ForbidSetgid: true}, true},
+		{"Setgid allowed", setgid, Options{Exists: true, ForbidSetuid: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Directory(tt.path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Directory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrPermissionMismatch) {
+				t.Errorf("Directory() error = %v, want ErrPermissionMismatch", err)
+			}
+		})
+	}
+
+	var missing *ErrCheckDirStickyMissing
+	if err := Directory(plain, Options{Exists: true, RequireSticky: true}); !errors.As(err, &missing) || missing.Path != plain {
+		t.Errorf("Directory() error = %v, want ErrCheckDirStickyMissing for %s", err, plain)
+	}
+	var set *ErrCheckDirSetgidSet
+	if err := Directory(setgid, Options{Exists: true, ForbidSetgid: true}); !errors.As(err, &set) {
+		t.Errorf("Directory() error = %v, want ErrCheckDirSetgidSet", err)
+	}
+}
+
+func TestCreateEnsureValid(t *testing.T) {
+	dir := t.TempDir()
+	defer syscall.Umask(syscall.Umask(0077))
+
+	// mode returns the permission bits of path
+	mode := func(t *testing.T, path string) os.FileMode {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Mode().Perm()
+	}
+
+	t.Run("Missing directory is created with the exact mode", func(t *testing.T) {
+		path := filepath.Join(dir, "missing")
+		if err := (&Create{Kind: EnsureValid, Path: path, FileMode: 0755}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := mode(t, path); got != 0755 {
+			t.Errorf("mode = %o, want %o", got, 0755)
+		}
+	})
+
+	t.Run("Present and correct is left alone", func(t *testing.T) {
+		path := filepath.Join(dir, "correct")
+		if err := os.Mkdir(path, 0700); err != nil {
+			t.Fatal(err)
+		}
+		create := &Create{Kind: EnsureValid, Path: path, FileMode: 0700}
+		if plan, err := create.Plan(); err != nil || len(plan) != 0 {
+			t.Errorf("Plan() = %q, %v, want nothing to do", plan, err)
+		}
+		if err := create.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := mode(t, path); got != 0700 {
+			t.Errorf("mode = %o, want %o", got, 0700)
+		}
+	})
+
+	t.Run("Present with the wrong mode is chmod'd and keeps its entries", func(t *testing.T) {
+		path := filepath.Join(dir, "shared")
+		if err := os.Mkdir(path, 0700); err != nil {
+			t.Fatal(err)
+		}
+		keep := filepath.Join(path, "keep.txt")
+		if err := os.WriteFile(keep, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		opts := Options{Exists: true, MorePermissiveThan: 0750, Create: Create{Kind: EnsureValid, FileMode: 0750}}
+		if err := Directory(path, opts); err != nil {
+			t.Fatalf("Directory() error = %v, want the mode converged before it is checked", err)
+		}
+		if got := mode(t, path); got != 0750 {
+			t.Errorf("mode = %o, want %o", got, 0750)
+		}
+		if _, err := os.Stat(keep); err != nil {
+			t.Errorf("EnsureValid removed %s: %v", keep, err)
+		}
+	})
+
+	t.Run("A file is refused", func(t *testing.T) {
+		path := filepath.Join(dir, "file.txt")
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := (&Create{Kind: EnsureValid, Path: path, FileMode: 0755}).Run(); !errors.Is(err, ErrNotDirectory) {
+			t.Errorf("Run() over a file error = %v, want ErrNotDirectory", err)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// countEntries returns the number of entries directly inside path or, when recursive, the number of non-directory
+// entries in the tree below it that walk reaches. Symlinks are counted as entries but never followed, so a link back up the tree
+// cannot loop.
+func countEntries(ctx context.Context, fsys fs.FS, path string, recursive bool, walk common.WalkOptions) (int, error) {
+	if !recursive {
+		entries, err := common.ReadDirFS(fsys, path)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read directory %s: %w", path, err)
+		}
+		return len(entries), nil
+	}
+	count := 0
+	err := common.WalkDirFSWith(fsys, path, walk, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to walk directory %s: %w", path, err)
+	}
+	return count, nil
+}
+
+// isEmpty reports whether the directory at path has no entries at all, hidden ones included, reading at most one entry
+func isEmpty(fsys fs.FS, path string) (bool, error) {
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open directory %s: %w", path, err)
+	}
+	defer f.Close()
+	dir, ok := f.(fs.ReadDirFile)
+	if !ok {
+		return false, common.Errorf(ErrNotDirectory, "cannot list directory: %s", path)
+	}
+	entries, err := dir.ReadDir(1)
+	if len(entries) > 0 {
+		return false, nil
+	}
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("failed to read directory %s: %w", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,248 @@
+package directory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDirectoryEntries(t *testing.T) {
+	dir := t.TempDir()
+	// dir holds one.txt, a/ and empty/ directly, and three files across the tree
+	for _, sub := range []string{"a", filepath.Join("a", "b"), "empty"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	for _, name := range []string{"one.txt", filepath.Join("a", "two.txt"), filepath.Join("a", "b", "three.txt")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{"Direct entries within bounds", Options{MinEntries: 1, MaxEntries: 3}, nil},
+		{"Direct entries too few", Options{MinEntries: 4}, &ErrCheckTooFewEntries{}},
+		{"Direct entries too many", Options{MaxEntries: 2}, &ErrCheckTooManyEntries{}},
+		{"Recursive counts files only", Options{MinEntries: 3, MaxEntries: 3, Recursive: true}, nil},
+		{"Recursive too few", Options{MinEntries: 10, Recursive: true}, &ErrCheckTooFewEntries{}},
+		{"Recursive too many", Options{MaxEntries: 1, Recursive: true}, &ErrCheckTooManyEntries{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Exists = true
+			err := Directory(dir, tt.opts)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("Directory() error = %v", err)
+				}
+			case *ErrCheckTooFewEntries:
+				if !errors.As(err, &want) || want.Min != tt.opts.MinEntries {
+					t.Errorf("Directory() error = %v, want *ErrCheckTooFewEntries", err)
+				}
+			case *ErrCheckTooManyEntries:
+				if !errors.As(err, &want) || want.Max != tt.opts.MaxEntries {
+					t.Errorf("Directory() error = %v, want *ErrCheckTooManyEntries", err)
+				}
+			}
+			if err != nil && !errors.Is(err, ErrSizeMismatch) {
+				t.Errorf("Directory() error = %v, want it to match ErrSizeMismatch", err)
+			}
+		})
+	}
+
+	t.Run("Symlink loop is counted, not followed", func(t *testing.T) {
+		if err := os.Symlink(dir, filepath.Join(dir, "a", "b", "loop")); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+		err := Directory(dir, Options{Exists: true, MinEntries: 4, MaxEntries: 4, Recursive: true})
+		if err != nil {
+			t.Errorf("Directory() error = %v", err)
+		}
+	})
+
+	t.Run("Empty directory", func(t *testing.T) {
+		var tooFew *ErrCheckTooFewEntries
+		err := Directory(filepath.Join(dir, "empty"), Options{Exists: true, MinEntries: 1})
+		if !errors.As(err, &tooFew) || tooFew.Actual != 0 {
+			t.Errorf("Directory() error = %v, want *ErrCheckTooFewEntries with Actual 0", err)
+		}
+	})
+
+	t.Run("Both bounds reported", func(t *testing.T) {
+		errs := DirectoryAll(dir, Options{Exists: true, MinEntries: 1, MaxEntries: 1})
+		if len(errs) != 1 {
+			t.Errorf("DirectoryAll() = %v, want only the MaxEntries failure", errs)
+		}
+	})
+
+	t.Run("Through fs.FS", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"uploads/a.jpg":        {Data: []byte("a")},
+			"uploads/nested/b.jpg": {Data: []byte("b")},
+		}
+		if err := DirectoryFS(fsys, "uploads", Options{Exists: true, MinEntries: 2, MaxEntries: 2, Recursive: true}); err != nil {
+			t.Errorf("DirectoryFS() error = %v", err)
+		}
+	})
+
+	t.Run("Invalid bounds", func(t *testing.T) {
+		for _, opts := range []Options{
+			{MinEntries: -1},
+			{MinEntries: 5, MaxEntries: 2},
+			{Recursive: true},
+		} {
+			if err := Directory(dir, opts); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Directory(%+v) error = %v, want ErrInvalidOptions", opts, err)
+			}
+		}
+	})
+}
+
+func TestDirectoryEmptiness(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty")
+	hidden := filepath.Join(dir, "hidden")
+	populated := filepath.Join(dir, "populated")
+	for _, d := range []string{empty, hidden, populated} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	for _, name := range []string{filepath.Join(hidden, ".keep"), filepath.Join(populated, "a.txt"), filepath.Join(populated, "b.txt")} {
+		if err := os.WriteFile(name, nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"Empty with RequireEmpty", empty, Options{RequireEmpty: true}, nil},
+		{"Empty with RequireNonEmpty", empty, Options{RequireNonEmpty: true}, ErrSizeMismatch},
+		{"Hidden file with RequireEmpty", hidden, Options{RequireEmpty: true}, ErrSizeMismatch},
+		{"Hidden file with RequireNonEmpty", hidden, Options{RequireNonEmpty: true}, nil},
+		{"Populated with RequireEmpty", populated, Options{RequireEmpty: true}, ErrSizeMismatch},
+		{"Populated with RequireNonEmpty", populated, Options{RequireNonEmpty: true}, nil},
+		{"Both set", populated, Options{RequireEmpty: true, RequireNonEmpty: true}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Exists = true
+			err := Directory(tt.path, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Directory() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Directory() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Through fs.FS", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"spool":       {Mode: os.ModeDir | 0755},
+			"outbox/.msg": {Data: []byte("x")},
+		}
+		if err := DirectoryFS(fsys, "spool", Options{Exists: true, RequireEmpty: true}); err != nil {
+			t.Errorf("DirectoryFS() error = %v", err)
+		}
+		if err := DirectoryFS(fsys, "outbox", Options{Exists: true, RequireEmpty: true}); !errors.Is(err, ErrSizeMismatch) {
+			t.Errorf("DirectoryFS() error = %v, want ErrSizeMismatch", err)
+		}
+	})
+}
+
+func TestDirectoryWalkLimits(t *testing.T) {
+	dir := t.TempDir()
+	// Three levels with a hidden entry at each: the file sizes are powers of two so every total names its files
+	for _, sub := range []string{".git", "a", filepath.Join("a", "b")} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	files := []struct {
+		name string
+		size int
+	}{
+		{"top.txt", 1},
+		{".env", 2},
+		{filepath.Join("a", "mid.txt"), 4},
+		{filepath.Join("a", ".mid"), 8},
+		{filepath.Join("a", "b", "deep.txt"), 16},
+		{filepath.Join(".git", "HEAD"), 32},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.name), make([]byte, f.size), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		maxDepth   int
+		skipHidden bool
+		files      int
+		size       int64
+	}{
+		{"Unlimited", 0, false, 6, 63},
+		{"Direct entries", 1, false, 2, 3},
+		{"Two levels", 2, false, 5, 47},
+		{"Skip hidden", 0, true, 3, 21},
+		{"Two levels without hidden", 2, true, 2, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{
+				Exists:       true,
+				Recursive:    true,
+				MinEntries:   tt.files,
+				MaxEntries:   tt.files,
+				MinTotalSize: tt.size,
+				MaxTotalSize: tt.size,
+				MaxDepth:     tt.maxDepth,
+				SkipHidden:   tt.skipHidden,
+			}
+			if errs := DirectoryAll(dir, opts); len(errs) > 0 {
+				t.Errorf("DirectoryAll() = %v", errs)
+			}
+			fsOpts := Options{Exists: true, Recursive: true, MinEntries: tt.files, MaxEntries: tt.files,
+				MaxDepth: tt.maxDepth, SkipHidden: tt.skipHidden}
+			if err := DirectoryFS(os.DirFS(dir), ".", fsOpts); err != nil {
+				t.Errorf("DirectoryFS() error = %v", err)
+			}
+		})
+	}
+
+	t.Run("Bounds the permission walk", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("permission bits are not enforced on Windows")
+		}
+		if err := os.Chmod(filepath.Join(dir, "a", "b", "deep.txt"), 0666); err != nil {
+			t.Fatalf("Failed to chmod test file: %v", err)
+		}
+		t.Cleanup(func() { os.Chmod(filepath.Join(dir, "a", "b", "deep.txt"), 0644) })
+		opts := Options{Exists: true, RecursiveLessPermissiveThan: 0755}
+		if err := Directory(dir, opts); !errors.Is(err, ErrPermissionMismatch) {
+			t.Errorf("Directory() error = %v, want ErrPermissionMismatch", err)
+		}
+		opts.MaxDepth = 2
+		if err := Directory(dir, opts); err != nil {
+			t.Errorf("Directory() with MaxDepth 2 error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// osOnlyChecks need ownership, timestamps or a real OS path that an fs.FS cannot provide
+var osOnlyChecks = map[string]bool{
+	"CreatedBefore":               true,
+	"CreatedAfter":                true,
+	"RequireBaseDir":              true,
+	"RequireBaseDirs":             true,
+	"ForbidBaseDirs":              true,
+	"RequireOwner":                true,
+	"RequireGroup":                true,
+	"RequireOwnerName":            true,
+	"RequireGroupName":            true,
+	"RequireOwnedByCurrentUser":   true,
+	"RequireOwnedByCurrentGroup":  true,
+	"RecursiveOwner":              true,
+	"RecursiveLessPermissiveThan": true,
+	"MinTotalSize":                true,
+	"MaxTotalSize":                true,
+}
+
+// DirectoryFS performs the directory checks against path inside fsys (embed.FS, fstest.MapFS, os.DirFS, ...) instead
+// of the OS filesystem. path must be a valid fs.FS path such as "static/css" ("." is the root of fsys). Existence,
+// name, mode and ready marker checks work; Options that need ownership, creation time or a real path, as well as
+// WillCreate and Create, fail with ErrUnsupportedFS before fsys is touched.
+func DirectoryFS(fsys fs.FS, path string, opts Options) error {
+	if fsys == nil {
+		return fmt.Errorf("%w: DirectoryFS requires a non-nil fs.FS", ErrInvalidOptions)
+	}
+	if failures := run(context.Background(), fsys, path, opts, false); len(failures) > 0 {
+		return failures[0].Err
+	}
+	return nil
+}
+
+// validateFS rejects Options that cannot be checked through an fs.FS, naming every offending field
+func (opts Options) validateFS() error {
+	var unsupported []string
+	for _, c := range checks {
+		if osOnlyChecks[c.name] && c.enabled(&opts) {
+			unsupported = append(unsupported, c.name)
+		}
+	}
+	if opts.RejectBrokenSymlink {
+		unsupported = append(unsupported, "RejectBrokenSymlink")
+	}
+	if opts.Strict {
+		unsupported = append(unsupported, "Strict")
+	}
+	if opts.WillCreate {
+		unsupported = append(unsupported, "WillCreate")
+	}
+	if opts.Create.Kind != NoAction {
+		unsupported = append(unsupported, "Create")
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFS, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
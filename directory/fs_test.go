@@ -0,0 +1,83 @@
+package directory
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDirectoryFS(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "static", "css"), 0755); err != nil {
+		t.Fatalf("Failed to create test directories: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "static", ".ready"), []byte("build 42"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "static", "index.html"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	filesystems := map[string]fs.FS{
+		"MapFS": fstest.MapFS{
+			"static/css":        {Mode: fs.ModeDir | 0755},
+			"static/.ready":     {Data: []byte("build 42"), Mode: 0644},
+			"static/index.html": {Mode: 0644},
+		},
+		"DirFS": os.DirFS(dir),
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"Exists", "static/css", Options{Exists: true}, nil},
+		{"Root", ".", Options{Exists: true}, nil},
+		{"Missing", "static/js", Options{Exists: true}, ErrDoesNotExist},
+		{"Unexpected", "static/css", Options{MustNotExist: true}, ErrAlreadyExists},
+		{"Regular file", "static/index.html", Options{Exists: true}, ErrNotDirectory},
+		{"Prefix", "static/css", Options{Exists: true, RequirePrefix: "cs"}, nil},
+		{"Wrong prefix", "static/css", Options{Exists: true, RequirePrefix: "js"}, ErrNameMismatch},
+		{"Permissions", "static/css", Options{Exists: true, RequireWrite: true, MorePermissiveThan: 0555}, nil},
+		{"Read only", "static/css", Options{Exists: true, ReadOnly: true}, ErrPermissionMismatch},
+		{"Ready marker", "static", Options{Exists: true, RequireReadyMarker: ".ready", ReadyMarkerToken: "42"}, nil},
+		{"Modified in the future", "static/css", Options{Exists: true, ModifiedBefore: time.Now().Add(time.Hour)}, nil},
+		{"Base dir is unsupported", "static/css", Options{Exists: true, RequireBaseDir: "static"}, ErrUnsupportedFS},
+		{"Base dirs are unsupported", "static/css", Options{Exists: true, RequireBaseDirs: []string{"static"}}, ErrUnsupportedFS},
+		{"Forbidden base dirs are unsupported", "static/css", Options{Exists: true, ForbidBaseDirs: []string{"etc"}}, ErrUnsupportedFS},
+		{"WillCreate is unsupported", "static/js", Options{WillCreate: true}, ErrUnsupportedFS},
+		{"Strict is unsupported", "static/css", Options{Exists: true, Strict: true}, ErrUnsupportedFS},
+		{"Current group is unsupported", "static/css", Options{Exists: true, RequireOwnedByCurrentGroup: true}, ErrUnsupportedFS},
+	}
+
+	for fsName, fsys := range filesystems {
+		for _, tt := range tests {
+			t.Run(fsName+"/"+tt.name, func(t *testing.T) {
+				err := DirectoryFS(fsys, tt.path, tt.opts)
+				if tt.wantErr == nil {
+					if err != nil {
+						t.Errorf("DirectoryFS() error = %v, want nil", err)
+					}
+					return
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("DirectoryFS() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+
+	t.Run("Missing ready marker", func(t *testing.T) {
+		var notReady *ErrCheckNotReady
+		err := DirectoryFS(filesystems["MapFS"], "static/css", Options{Exists: true, RequireReadyMarker: ".ready"})
+		if !errors.As(err, &notReady) {
+			t.Errorf("DirectoryFS() error = %v, want *ErrCheckNotReady", err)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package directory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrGitUnavailable is returned by GitClean when no git executable can be found in PATH
+var ErrGitUnavailable = errors.New("git executable not found in PATH")
+
+// GitClean reports whether the git working tree at root has no modified, staged, deleted or untracked files, returning
+// the dirty paths (relative to the repository root) when it does not. Reading the index format directly is out of
+// scope for checkfs, so this shells out to `git status --porcelain` and returns ErrGitUnavailable when git is not
+// installed. Ignored files are never reported.
+func GitClean(root string) (bool, []string, error) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return false, nil, ErrGitUnavailable
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(gitPath, "-C", root, "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return false, nil, fmt.Errorf("git status failed for %s: %w: %s", root, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var dirty []string
+	records := strings.Split(stdout.String(), "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+		status, path := record[:2], record[3:]
+		dirty = append(dirty, path)
+		// renames and copies are followed by a second record holding the original path
+		if status[0] == 'R' || status[0] == 'C' {
+			i++
+		}
+	}
+	return len(dirty) == 0, dirty, nil
+}
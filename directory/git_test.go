@@ -0,0 +1,63 @@
+package directory
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitClean(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		args = append([]string{"-C", root, "-c", "user.name=checkfs", "-c", "user.email=checkfs@example.com",
+			"-c", "commit.gpgsign=false"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, data string) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create fixture directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create fixture file: %v", err)
+		}
+	}
+
+	git("init", "-q")
+	write("tracked.txt", "v1")
+	write("ignored.log", "noise")
+	write(".gitignore", "*.log\n")
+	git("add", "tracked.txt", ".gitignore")
+	git("commit", "-q", "-m", "initial")
+
+	clean, dirty, err := GitClean(root)
+	if err != nil || !clean || len(dirty) != 0 {
+		t.Fatalf("GitClean() = %v, %v, %v; want clean tree", clean, dirty, err)
+	}
+
+	write("tracked.txt", "v2")
+	write("nested/untracked.txt", "new")
+	clean, dirty, err = GitClean(root)
+	if err != nil || clean {
+		t.Fatalf("GitClean() = %v, %v, %v; want dirty tree", clean, dirty, err)
+	}
+	want := map[string]bool{"tracked.txt": true, "nested/untracked.txt": true}
+	if len(dirty) != len(want) {
+		t.Fatalf("GitClean() dirty = %v, want %v", dirty, want)
+	}
+	for _, path := range dirty {
+		if !want[path] {
+			t.Errorf("GitClean() reported unexpected path %q", path)
+		}
+	}
+
+	if _, _, err := GitClean(t.TempDir()); err == nil {
+		t.Error("GitClean() should fail outside of a git repository")
+	}
+}
@@ -0,0 +1,33 @@
+package directory
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// checkGlob matches pattern against the entries of path, never the process working directory, and fails when nothing
+// matches or, when count is positive, when the number of matches is not exactly count
+func checkGlob(fsys fs.FS, path, pattern string, count int) error {
+	var root fs.FS
+	if fsys == nil {
+		root = os.DirFS(path)
+	} else {
+		sub, err := fs.Sub(fsys, path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		root = sub
+	}
+	matches, err := fs.Glob(root, pattern)
+	if err != nil {
+		return fmt.Errorf("failed to match %s in %s: %w", pattern, path, err)
+	}
+	if len(matches) == 0 {
+		return &ErrCheckNoGlobMatch{Path: path, Pattern: pattern}
+	}
+	if count > 0 && len(matches) != count {
+		return &ErrCheckGlobCount{Path: path, Pattern: pattern, Expected: count, Actual: len(matches)}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package directory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDirectoryContainsGlob(t *testing.T) {
+	dir := t.TempDir()
+	certs := filepath.Join(dir, "certs")
+	if err := os.MkdirAll(filepath.Join(certs, "old"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	for _, name := range []string{"ca.pem", "server.pem", "server.key", filepath.Join("old", "legacy.pem")} {
+		if err := os.WriteFile(filepath.Join(certs, name), []byte("x"), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	// a match in the working directory must not count for the checked directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(certs); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"Multiple matches", certs, Options{ContainsGlob: "*.pem"}, nil},
+		{"Exact count", certs, Options{ContainsGlob: "*.pem", ContainsGlobCount: 2}, nil},
+		{"Wrong count", certs, Options{ContainsGlob: "*.pem", ContainsGlobCount: 1}, &ErrCheckGlobCount{}},
+		{"Nested pattern", certs, Options{ContainsGlob: "old/*.pem", ContainsGlobCount: 1}, nil},
+		{"Zero matches", certs, Options{ContainsGlob: "*.crt"}, &ErrCheckNoGlobMatch{}},
+		{"Relative to the checked directory", dir, Options{ContainsGlob: "*.pem"}, &ErrCheckNoGlobMatch{}},
+		{"Malformed pattern", certs, Options{ContainsGlob: "[*.pem"}, ErrInvalidOptions},
+		{"Absolute pattern", certs, Options{ContainsGlob: filepath.Join(certs, "*.pem")}, ErrInvalidOptions},
+		{"Count without pattern", certs, Options{ContainsGlobCount: 1}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Exists = true
+			err := Directory(tt.path, tt.opts)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("Directory() error = %v", err)
+				}
+			case *ErrCheckNoGlobMatch:
+				if !errors.As(err, &want) || !errors.Is(err, ErrDoesNotExist) {
+					t.Errorf("Directory() error = %v, want *ErrCheckNoGlobMatch", err)
+				}
+			case *ErrCheckGlobCount:
+				if !errors.As(err, &want) || want.Actual != 2 {
+					t.Errorf("Directory() error = %v, want *ErrCheckGlobCount with 2 matches", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Errorf("Directory() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+
+	t.Run("Through fs.FS", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"etc/tls/a.pem": {Data: []byte("a")},
+			"etc/tls/b.pem": {Data: []byte("b")},
+			"etc/c.pem":     {Data: []byte("c")},
+		}
+		if err := DirectoryFS(fsys, "etc/tls", Options{Exists: true, ContainsGlob: "*.pem", ContainsGlobCount: 2}); err != nil {
+			t.Errorf("DirectoryFS() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+//go:build checkfs_html
+
+package directory
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// voidElements never have an end tag, so they are not pushed onto the open element stack
+var voidElements = map[atom.Atom]bool{
+	atom.Area: true, atom.Base: true, atom.Br: true, atom.Col: true, atom.Embed: true, atom.Hr: true, atom.Img: true,
+	atom.Input: true, atom.Link: true, atom.Meta: true, atom.Source: true, atom.Track: true, atom.Wbr: true,
+}
+
+// validateHTML tokenizes data and fails when it holds no elements at all or when an end tag closes an element that
+// was never opened. Omitted end tags (<p>, <li>, </body>) are allowed, as they are in HTML itself.
+func validateHTML(data []byte) error {
+	z := html.NewTokenizer(bytes.NewReader(data))
+	var open []string
+	elements := 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return err
+			}
+			if elements == 0 {
+				return errors.New("no HTML elements found")
+			}
+			return nil
+		case html.StartTagToken:
+			elements++
+			name, _ := z.TagName()
+			if !voidElements[atom.Lookup(name)] {
+				open = append(open, string(name))
+			}
+		case html.SelfClosingTagToken:
+			elements++
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			i := len(open) - 1
+			for i >= 0 && open[i] != string(name) {
+				i--
+			}
+			if i < 0 {
+				line := bytes.Count(data[:len(data)-len(z.Buffered())], []byte("\n")) + 1
+				return fmt.Errorf("unexpected end tag </%s> near line %d", name, line)
+			}
+			open = open[:i]
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !checkfs_html
+
+package directory
+
+// validateHTML always fails with ErrHTMLUnavailable so golang.org/x/net is only linked into builds that opt in with
+// -tags checkfs_html
+func validateHTML(_ []byte) error {
+	return ErrHTMLUnavailable
+}
@@ -0,0 +1,21 @@
+//go:build !checkfs_html
+
+package directory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirectoryValidateIndexHTMLUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<p>hi</p>"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	err := Directory(dir, Options{Exists: true, RequireIndexFile: "index.html", ValidateIndexHTML: true})
+	if !errors.Is(err, ErrHTMLUnavailable) {
+		t.Errorf("Directory() error = %v, want ErrHTMLUnavailable", err)
+	}
+}
@@ -0,0 +1,52 @@
+//go:build checkfs_html
+
+package directory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirectoryValidateIndexHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		index   string
+		wantErr error
+	}{
+		{"Valid document", "<!DOCTYPE html>\n<html><head><title>x</title><meta charset=utf-8></head>\n<body><p>one<p>two<br><img src=a.png/></body></html>", nil},
+		{"Fragment", "<main><h1>Hello</h1></main>", nil},
+		{"Mismatched end tag", "<html><body>\n<div><span>x</div></span>\n</body></html>", ErrContentMismatch},
+		{"Stray end tag", "<html><body></section></body></html>", ErrContentMismatch},
+		{"Plain text", "just some text, no markup", ErrContentMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(tt.index), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			err := Directory(dir, Options{Exists: true, RequireIndexFile: "index.html", ValidateIndexHTML: true})
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Directory() error = %v, want nil", err)
+				}
+				return
+			}
+			var malformed *ErrCheckMalformedIndex
+			if !errors.Is(err, tt.wantErr) || !errors.As(err, &malformed) {
+				t.Errorf("Directory() error = %v, want *ErrCheckMalformedIndex", err)
+			}
+		})
+	}
+
+	t.Run("Missing index", func(t *testing.T) {
+		err := Directory(t.TempDir(), Options{Exists: true, RequireIndexFile: "index.html", ValidateIndexHTML: true})
+		var missing *ErrCheckMissingIndex
+		if !errors.As(err, &missing) {
+			t.Errorf("Directory() error = %v, want *ErrCheckMissingIndex", err)
+		}
+	})
+}
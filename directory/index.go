@@ -0,0 +1,42 @@
+package directory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// checkIndexFile verifies the index file inside path exists, is a non-empty regular file and, when validate is set,
+// is well-formed enough HTML for validateHTML
+func checkIndexFile(fsys fs.FS, path, index string, validate bool) error {
+	indexPath := common.JoinFS(fsys, path, index)
+	info, err := common.StatFS(fsys, indexPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &ErrCheckMissingIndex{Path: path, Index: index}
+		}
+		return fmt.Errorf("failed to stat index file %s: %w", indexPath, err)
+	}
+	if !info.Mode().IsRegular() {
+		return &ErrCheckMissingIndex{Path: path, Index: index}
+	}
+	if info.Size() == 0 {
+		return common.Errorf(ErrSizeMismatch, "index file %s in %s is empty", index, path)
+	}
+	if !validate {
+		return nil
+	}
+	data, err := common.ReadFileFS(fsys, indexPath)
+	if err != nil {
+		return fmt.Errorf("failed to read index file %s: %w", indexPath, err)
+	}
+	if err := validateHTML(data); err != nil {
+		if errors.Is(err, ErrHTMLUnavailable) {
+			return err
+		}
+		return &ErrCheckMalformedIndex{Path: path, Index: index, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package directory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirectoryRequireIndexFile(t *testing.T) {
+	baseDir := t.TempDir()
+	site := filepath.Join(baseDir, "site")
+	empty := filepath.Join(baseDir, "empty")
+	bare := filepath.Join(baseDir, "bare")
+	for _, dir := range []string{site, empty, bare} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(site, "index.html"), []byte("<html><body>hi</body></html>"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(empty, "index.html"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"Index present", site, Options{Exists: true, RequireIndexFile: "index.html"}, nil},
+		{"Index missing", bare, Options{Exists: true, RequireIndexFile: "index.html"}, ErrDoesNotExist},
+		{"Index empty", empty, Options{Exists: true, RequireIndexFile: "index.html"}, ErrSizeMismatch},
+		{"Validate without index", site, Options{Exists: true, ValidateIndexHTML: true}, ErrInvalidOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Directory(tt.path, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Directory() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Directory() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
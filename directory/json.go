@@ -0,0 +1,122 @@
+package directory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// String returns the name of the CreateKind constant, such as "IfNotExists"
+func (k CreateKind) String() string {
+	switch k {
+	case NoAction:
+		return "NoAction"
+	case IfNotExists:
+		return "IfNotExists"
+	case IfExists:
+		return "IfExists"
+	case EnsureValid:
+		return "EnsureValid"
+	}
+	return fmt.Sprintf("CreateKind(%d)", int8(k))
+}
+
+// MarshalText encodes k as its constant name
+func (k CreateKind) MarshalText() ([]byte, error) {
+	switch k {
+	case NoAction, IfNotExists, IfExists, EnsureValid:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("%w: unknown CreateKind %d", ErrInvalidOptions, int8(k))
+}
+
+// UnmarshalText decodes a constant name such as "IfNotExists"; an empty string is NoAction
+func (k *CreateKind) UnmarshalText(text []byte) error {
+	for _, kind := range []CreateKind{NoAction, IfNotExists, IfExists, EnsureValid} {
+		if string(text) == kind.String() {
+			*k = kind
+			return nil
+		}
+	}
+	if len(text) == 0 {
+		*k = NoAction
+		return nil
+	}
+	return fmt.Errorf("%w: unknown CreateKind %q, want NoAction, IfNotExists, IfExists or EnsureValid", ErrInvalidOptions, text)
+}
+
+// createJSON and optionsJSON have the fields of Create and Options without their JSON methods, so those methods can
+// embed them and override the fields that need another encoding
+type (
+	createJSON  Create
+	optionsJSON Options
+)
+
+// MarshalJSON encodes create with FileMode as an octal string such as "0755" and Kind as a name such as
+// "IfNotExists"
+func (create Create) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		createJSON
+		FileMode common.OctalMode
+	}{createJSON(create), common.OctalMode(create.FileMode)})
+}
+
+// UnmarshalJSON decodes what MarshalJSON encodes, leaving fields missing from data untouched
+func (create *Create) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*createJSON
+		FileMode common.OctalMode
+	}{(*createJSON)(create), common.OctalMode(create.FileMode)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	create.FileMode = os.FileMode(aux.FileMode)
+	return nil
+}
+
+// MarshalJSON encodes opts for policy files: modes as octal strings such as "0755", ModTimeTolerance as a string such
+// as "2s", times as RFC 3339 and Create.Kind by name
+func (opts Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		optionsJSON
+		MorePermissiveThan          common.OctalMode
+		LessPermissiveThan          common.OctalMode
+		ModTimeTolerance            common.Duration
+		RecursiveLessPermissiveThan common.OctalMode
+	}{
+		optionsJSON(opts),
+		common.OctalMode(opts.MorePermissiveThan),
+		common.OctalMode(opts.LessPermissiveThan),
+		common.Duration(opts.ModTimeTolerance),
+		common.OctalMode(opts.RecursiveLessPermissiveThan),
+	})
+}
+
+// UnmarshalJSON decodes what MarshalJSON encodes, leaving fields missing from data untouched. A malformed mode,
+// duration or CreateKind fails with an error wrapping ErrInvalidOptions that names the bad value.
+func (opts *Options) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*optionsJSON
+		MorePermissiveThan          common.OctalMode
+		LessPermissiveThan          common.OctalMode
+		ModTimeTolerance            common.Duration
+		RecursiveLessPermissiveThan common.OctalMode
+	}{
+		(*optionsJSON)(opts),
+		common.OctalMode(opts.MorePermissiveThan),
+		common.OctalMode(opts.LessPermissiveThan),
+		common.Duration(opts.ModTimeTolerance),
+		common.OctalMode(opts.RecursiveLessPermissiveThan),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	opts.MorePermissiveThan = os.FileMode(aux.MorePermissiveThan)
+	opts.LessPermissiveThan = os.FileMode(aux.LessPermissiveThan)
+	opts.ModTimeTolerance = time.Duration(aux.ModTimeTolerance)
+	opts.RecursiveLessPermissiveThan = os.FileMode(aux.RecursiveLessPermissiveThan)
+	return nil
+}
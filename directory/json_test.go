@@ -0,0 +1,108 @@
+package directory
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptionsJSON(t *testing.T) {
+	stamp := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	opts := Options{
+		CreatedBefore:               stamp,
+		ModifiedBefore:              stamp.Add(time.Hour),
+		CreatedAfter:                stamp.Add(-time.Hour),
+		ModifiedAfter:               stamp.Add(-2 * time.Hour),
+		RequireOwner:                "0",
+		RequireGroup:                "0",
+		RequireOwnerName:            "root",
+		RequireGroupName:            "wheel",
+		RecursiveOwner:              true,
+		RequireBaseDir:              "/srv",
+		RequireExt:                  ".d",
+		RequirePrefix:               "www",
+		RequireSuffix:               "-tmp",
+		RequireReadyMarker:          ".ready",
+		ReadyMarkerToken:            "ok",
+		RequireIndexFile:            "index.html",
+		ValidateIndexHTML:           true,
+		RequireEmpty:                true,
+		RequireNonEmpty:             true,
+		ContainsGlob:                "*.pem",
+		ContainsGlobCount:           2,
+		MinEntries:                  1,
+		MaxEntries:                  10,
+		MinTotalSize:                1,
+		MaxTotalSize:                1 << 30,
+		Recursive:                   true,
+		RequireUniformModTime:       stamp,
+		ModTimeTolerance:            2 * time.Second,
+		MorePermissiveThan:          0700,
+		LessPermissiveThan:          0755,
+		RecursiveLessPermissiveThan: 0775,
+		RecursiveMaxEntries:         1000,
+		ReadOnly:                    true,
+		RequireWrite:                true,
+		ForbidSetuid:                true,
+		ForbidSetgid:                true,
+		RequireSticky:               true,
+		WillCreate:                  true,
+		Exists:                      true,
+		RejectBrokenSymlink:         true,
+		Create: Create{
+			Kind:           IfExists,
+			FileMode:       0750,
+			Path:           "/srv/www-tmp",
+			ForceMode:      true,
+			DryRun:         true,
+			BackupDir:      "/var/backups",
+			RequireBaseDir: "/srv",
+			Sync:           true,
+		},
+	}
+
+	data, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, want := range []string{
+		`"MorePermissiveThan":"0700"`, `"RecursiveLessPermissiveThan":"0775"`, `"ModTimeTolerance":"2s"`, `"RequireUniformModTime":"2024-06-01T12:30:00Z"`,
+		`"Kind":"IfExists"`, `"FileMode":"0750"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("json.Marshal() = %s, want it to contain %s", data, want)
+		}
+	}
+
+	var got Options
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, opts) {
+		t.Errorf("round trip = %+v, want %+v", got, opts)
+	}
+}
+
+func TestOptionsJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"Mode not octal", `{"LessPermissiveThan":"0x1ed"}`, `"0x1ed"`},
+		{"Bad duration", `{"ModTimeTolerance":"2 seconds"}`, `"2 seconds"`},
+		{"Bad CreateKind", `{"Create":{"Kind":"ifnotexists"}}`, `"ifnotexists"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts Options
+			err := json.Unmarshal([]byte(tt.data), &opts)
+			if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("json.Unmarshal() error = %v, want ErrInvalidOptions mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
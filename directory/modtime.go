@@ -0,0 +1,94 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// checkUniformModTime fails with ErrCheckNonUniformModTime when the modification time of path, or of any entry below
+// it, is further than tolerance from expected. Only the direct entries are checked unless recursive is set, and then
+// only those walk reaches. Symlinks are skipped since their own mtime cannot be set portably.
+func checkUniformModTime(ctx context.Context, fsys fs.FS, path string, info fs.FileInfo, expected time.Time,
+	tolerance time.Duration, recursive bool, walk common.WalkOptions) error {
+	var offending []string
+	visit := func(name string, d fs.DirEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		diff := info.ModTime().Sub(expected)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > tolerance {
+			offending = append(offending, name)
+		}
+		return nil
+	}
+
+	var err error
+	if recursive {
+		err = common.WalkDirFSWith(fsys, path, walk, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			return visit(name, d)
+		})
+	} else {
+		err = visitDirect(fsys, path, info, visit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read modification times in %s: %w", path, err)
+	}
+	if len(offending) > 0 {
+		return &ErrCheckNonUniformModTime{Path: path, Expected: expected, Offending: offending}
+	}
+	return nil
+}
+
+// visitDirect calls visit for path itself, described by info, and then for each of its direct entries
+func visitDirect(fsys fs.FS, path string, info fs.FileInfo, visit func(name string, d fs.DirEntry) error) error {
+	if err := visit(path, fs.FileInfoToDirEntry(info)); err != nil {
+		return err
+	}
+	entries, err := common.ReadDirFS(fsys, path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := visit(common.JoinFS(fsys, path, entry.Name()), entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NormalizeModTimes sets the access and modification time of root and everything below it to t, for example the
+// SOURCE_DATE_EPOCH of a reproducible build, so RequireUniformModTime passes afterwards. Symlinks are left alone:
+// os.Chtimes would follow them and change the target instead.
+func NormalizeModTimes(root string, t time.Time) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk %s: %w", path, err)
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if err := os.Chtimes(path, t, t); err != nil {
+			return fmt.Errorf("failed to set times on %s: %w", path, err)
+		}
+		return nil
+	})
+}
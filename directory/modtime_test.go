@@ -0,0 +1,94 @@
+package directory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestDirectoryUniformModTime(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "nested")
+	if err := os.Mkdir(nested, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	for _, name := range []string{filepath.Join(dir, "top.txt"), filepath.Join(nested, "deep.txt")} {
+		if err := os.WriteFile(name, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	epoch := time.Unix(1700000000, 0)
+
+	var nonUniform *ErrCheckNonUniformModTime
+	err := Directory(dir, Options{Exists: true, RequireUniformModTime: epoch, Recursive: true})
+	if !errors.As(err, &nonUniform) || len(nonUniform.Offending) != 4 {
+		t.Fatalf("Directory() before normalizing error = %v, want all 4 entries offending", err)
+	}
+
+	if err := NormalizeModTimes(dir, epoch); err != nil {
+		t.Fatalf("NormalizeModTimes() error = %v", err)
+	}
+	if err := Directory(dir, Options{Exists: true, RequireUniformModTime: epoch, Recursive: true}); err != nil {
+		t.Errorf("Directory() after normalizing error = %v", err)
+	}
+
+	drifted := filepath.Join(nested, "deep.txt")
+	if err := os.Chtimes(drifted, epoch, epoch.Add(2*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		opts          Options
+		wantOffending []string
+	}{
+		{"Direct entries ignore nested drift", Options{RequireUniformModTime: epoch}, nil},
+		{"Recursive finds nested drift", Options{RequireUniformModTime: epoch, Recursive: true}, []string{drifted}},
+		{"Tolerance absorbs drift", Options{RequireUniformModTime: epoch, ModTimeTolerance: 2 * time.Second, Recursive: true}, nil},
+		{"Tolerance too small", Options{RequireUniformModTime: epoch, ModTimeTolerance: time.Second, Recursive: true}, []string{drifted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Exists = true
+			err := Directory(dir, tt.opts)
+			if tt.wantOffending == nil {
+				if err != nil {
+					t.Errorf("Directory() error = %v", err)
+				}
+				return
+			}
+			var nonUniform *ErrCheckNonUniformModTime
+			if !errors.As(err, &nonUniform) {
+				t.Fatalf("Directory() error = %v, want *ErrCheckNonUniformModTime", err)
+			}
+			if len(nonUniform.Offending) != 1 || nonUniform.Offending[0] != tt.wantOffending[0] {
+				t.Errorf("Offending = %v, want %v", nonUniform.Offending, tt.wantOffending)
+			}
+			if !errors.Is(err, ErrTimeMismatch) {
+				t.Errorf("Directory() error = %v, want it to match ErrTimeMismatch", err)
+			}
+		})
+	}
+
+	t.Run("Through fs.FS", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"site":            {Mode: os.ModeDir | 0755, ModTime: epoch},
+			"site/index.html": {Data: []byte("<p>"), ModTime: epoch},
+			"site/app.js":     {Data: []byte("x"), ModTime: epoch.Add(time.Hour)},
+		}
+		err := DirectoryFS(fsys, "site", Options{Exists: true, RequireUniformModTime: epoch, Recursive: true})
+		var nonUniform *ErrCheckNonUniformModTime
+		if !errors.As(err, &nonUniform) || len(nonUniform.Offending) != 1 || nonUniform.Offending[0] != "site/app.js" {
+			t.Errorf("DirectoryFS() error = %v, want site/app.js offending", err)
+		}
+	})
+
+	t.Run("Tolerance without RequireUniformModTime", func(t *testing.T) {
+		if err := Directory(dir, Options{ModTimeTolerance: time.Second}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Directory() error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
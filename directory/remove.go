@@ -0,0 +1,81 @@
+package directory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// RemoveOptions guards Remove
+type RemoveOptions struct {
+	RequireBaseDir string // RequireBaseDir refuses to remove a directory outside this one, or this one itself, lexically or through symlinks
+	MustExist      bool   // MustExist fails with ErrDoesNotExist when path is already gone instead of succeeding
+	MaxEntries     int    // MaxEntries refuses to remove a tree holding more than this many entries unless Force is set, 0 is unset
+	Force          bool   // Force removes the tree even when it holds more than MaxEntries entries
+}
+
+// errTooManyEntries stops the entry count of Remove as soon as it passes MaxEntries
+var errTooManyEntries = errors.New("too many entries")
+
+// Remove removes the directory at path and everything below it, like os.RemoveAll, once opts allow it. It refuses the
+// root of a filesystem, anything that is not a directory (a symlink to one included) and, with MaxEntries, any tree
+// larger than expected, counting no further than MaxEntries+1 entries.
+func Remove(path string, opts RemoveOptions) error {
+	if path == "" || common.IsFilesystemRoot(path) {
+		return fmt.Errorf("%w: refusing to remove %q", ErrInvalidOptions, path)
+	}
+	if opts.MaxEntries < 0 {
+		return fmt.Errorf("%w: MaxEntries cannot be negative", ErrInvalidOptions)
+	}
+	if opts.RequireBaseDir != "" {
+		inBase, err := common.IsStrictlyInBaseResolved(path, opts.RequireBaseDir)
+		if err != nil {
+			return fmt.Errorf("failed to check base directory: %w", err)
+		}
+		if !inBase {
+			return &ErrCheckDirBadBaseDir{Path: path, BaseDir: opts.RequireBaseDir}
+		}
+	}
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if opts.MustExist {
+			return common.Errorf(ErrDoesNotExist, "cannot remove missing directory: %s", path)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to stat directory %s: %w", path, err)
+	case !info.IsDir():
+		return common.Errorf(ErrNotDirectory, "cannot remove %s: not a directory", path)
+	}
+	if opts.MaxEntries > 0 && !opts.Force {
+		seen := 0
+		err := filepath.WalkDir(path, func(name string, _ fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if name == path {
+				return nil
+			}
+			if seen++; seen > opts.MaxEntries {
+				return errTooManyEntries
+			}
+			return nil
+		})
+		if errors.Is(err, errTooManyEntries) {
+			return common.Errorf(ErrSizeMismatch, "refusing to remove %s: it holds more than %d entries, set Force to remove it anyway",
+				path, opts.MaxEntries)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to count entries in %s: %w", path, err)
+		}
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("could not remove directory: %w", err)
+	}
+	return nil
+}
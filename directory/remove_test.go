@@ -0,0 +1,65 @@
+package directory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemove(t *testing.T) {
+	base := t.TempDir()
+	tree := func(t *testing.T, name string, files int) string {
+		dir := filepath.Join(base, name)
+		if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+			t.Fatalf("Failed to create tree: %v", err)
+		}
+		for i := 0; i < files; i++ {
+			if err := os.WriteFile(filepath.Join(dir, "sub", string(rune('a'+i))+".txt"), []byte("x"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+		}
+		return dir
+	}
+	outside := t.TempDir()
+
+	tests := []struct {
+		name        string
+		path        func(t *testing.T) string
+		opts        RemoveOptions
+		wantErr     error
+		wantRemoved bool
+	}{
+		{"Inside base", func(t *testing.T) string { return tree(t, "inside", 2) }, RemoveOptions{RequireBaseDir: base}, nil, true},
+		{"Outside base", func(t *testing.T) string { return outside }, RemoveOptions{RequireBaseDir: base}, ErrBadBaseDir, false},
+		{"Base itself", func(t *testing.T) string { return base }, RemoveOptions{RequireBaseDir: base}, ErrBadBaseDir, false},
+		{"Escaping with ..", func(t *testing.T) string { return filepath.Join(base, "..", filepath.Base(outside)) }, RemoveOptions{RequireBaseDir: base}, ErrBadBaseDir, false},
+		{"Within MaxEntries", func(t *testing.T) string { return tree(t, "small", 2) }, RemoveOptions{MaxEntries: 3}, nil, true},
+		{"Over MaxEntries", func(t *testing.T) string { return tree(t, "large", 3) }, RemoveOptions{MaxEntries: 3}, ErrSizeMismatch, false},
+		{"Over MaxEntries with Force", func(t *testing.T) string { return tree(t, "forced", 3) }, RemoveOptions{MaxEntries: 3, Force: true}, nil, true},
+		{"Missing", func(t *testing.T) string { return filepath.Join(base, "missing") }, RemoveOptions{}, nil, true},
+		{"Missing with MustExist", func(t *testing.T) string { return filepath.Join(base, "missing") }, RemoveOptions{MustExist: true}, ErrDoesNotExist, true},
+		{"Not a directory", func(t *testing.T) string {
+			path := filepath.Join(base, "file.txt")
+			if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+				t.Fatalf("Failed to create file: %v", err)
+			}
+			return path
+		}, RemoveOptions{}, ErrNotDirectory, false},
+		{"Filesystem root", func(t *testing.T) string { return string(filepath.Separator) }, RemoveOptions{}, ErrInvalidOptions, false},
+		{"Negative MaxEntries", func(t *testing.T) string { return tree(t, "negative", 1) }, RemoveOptions{MaxEntries: -1}, ErrInvalidOptions, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path(t)
+			err := Remove(path, tt.opts)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Remove() error = %v, want %v", err, tt.wantErr)
+			}
+			_, statErr := os.Lstat(path)
+			if removed := errors.Is(statErr, os.ErrNotExist); removed != tt.wantRemoved {
+				t.Errorf("Remove() left %s removed = %v, want %v", path, removed, tt.wantRemoved)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package directory
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// AssertStableSort walks root and, for every directory in the tree, sorts the entry names by raw bytes and verifies
+// that no two siblings are locale-ambiguous: names that only differ by Unicode normalization form, such as "café"
+// written precomposed (NFC, U+00E9) and decomposed (NFD, "e" + U+0301). Such pairs collide on macOS filesystems and
+// collate differently depending on locale, so archives built from the tree are not reproducible. The first conflicting
+// pair is returned as ErrCheckUnstableSort.
+func AssertStableSort(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to walk %s: %w", path, err)
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		entries, err := readDirNames(path)
+		if err != nil {
+			return fdExhausted(path, err)
+		}
+		sort.Strings(entries)
+		seen := make(map[string]string, len(entries))
+		for _, name := range entries {
+			key := norm.NFC.String(name)
+			if first, ok := seen[key]; ok {
+				return &ErrCheckUnstableSort{Path: path, First: first, Second: name}
+			}
+			seen[key] = name
+		}
+		return nil
+	})
+}
+
+// readDirNames returns the entry names of the directory at path in the order the filesystem reports them
+func readDirNames(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdirnames(-1)
+}
@@ -0,0 +1,66 @@
+package directory
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssertStableSort(t *testing.T) {
+	nfc := "caf\u00e9.txt"  // precomposed é
+	nfd := "cafe\u0301.txt" // e followed by a combining acute accent
+
+	mkfiles := func(t *testing.T, dir string, names ...string) {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("Failed to create fixture directory: %v", err)
+			}
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Skipf("filesystem does not keep %q distinct: %v", name, err)
+			}
+			f.Close()
+		}
+		if names, _ := readDirNames(filepath.Join(dir, filepath.Dir(names[len(names)-1]))); len(names) < 2 {
+			t.Skip("filesystem normalizes file names")
+		}
+	}
+
+	t.Run("Distinct names", func(t *testing.T) {
+		dir := t.TempDir()
+		mkfiles(t, dir, "a.txt", "B.txt", nfc, "sub/"+nfd, "sub/other.txt")
+		if err := AssertStableSort(dir); err != nil {
+			t.Errorf("AssertStableSort() error = %v", err)
+		}
+	})
+
+	t.Run("NFC and NFD duplicates", func(t *testing.T) {
+		dir := t.TempDir()
+		mkfiles(t, dir, nfc, nfd)
+		err := AssertStableSort(dir)
+		var sortErr *ErrCheckUnstableSort
+		if !errors.As(err, &sortErr) {
+			t.Fatalf("AssertStableSort() error = %v, want ErrCheckUnstableSort", err)
+		}
+		if sortErr.First != nfd || sortErr.Second != nfc {
+			t.Errorf("AssertStableSort() conflict = %q, %q; want %q, %q", sortErr.First, sortErr.Second, nfd, nfc)
+		}
+	})
+
+	t.Run("Nested duplicates", func(t *testing.T) {
+		dir := t.TempDir()
+		mkfiles(t, dir, "top.txt", "nested/deeper/"+nfc, "nested/deeper/"+nfd)
+		var sortErr *ErrCheckUnstableSort
+		if err := AssertStableSort(dir); !errors.As(err, &sortErr) || sortErr.Path != filepath.Join(dir, "nested", "deeper") {
+			t.Errorf("AssertStableSort() error = %v, want conflict in nested/deeper", err)
+		}
+	})
+
+	t.Run("Missing root", func(t *testing.T) {
+		if err := AssertStableSort(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("AssertStableSort() should fail on a missing root")
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package directory
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// walkTree calls visit for every entry below root that walk reaches, never root itself. It builds on common.WalkSafe: symlinks are
+// neither followed nor visited, so the walk never leaves root. When maxEntries is not 0, the walk fails with
+// ErrSizeMismatch once it has seen more than maxEntries entries rather than wander an unexpectedly large tree such as /.
+func walkTree(ctx context.Context, root string, maxEntries int, walk common.WalkOptions,
+	visit func(path string, info os.FileInfo) error) error {
+	seen := 0
+	return common.WalkSafeContext(ctx, root, walk, func(path string, d fs.DirEntry) error {
+		if path == root || d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		seen++
+		if maxEntries > 0 && seen > maxEntries {
+			return common.Errorf(ErrSizeMismatch, "directory %s has more than RecursiveMaxEntries (%d) entries, walk stopped",
+				root, maxEntries)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		return visit(path, info)
+	})
+}
+
+// checkOwnerTree fails on the first entry below root whose owner is not uid or whose group is not gid, with
+// ErrCheckDirBadOwner or ErrCheckDirBadGroup naming that entry. An empty uid or gid is not checked. Root itself is left
+// to RequireOwner/RequireGroup.
+func checkOwnerTree(ctx context.Context, root string, maxEntries int, walk common.WalkOptions, uid, gid string) error {
+	return walkTree(ctx, root, maxEntries, walk, func(path string, info os.FileInfo) error {
+		actualUID, actualGID, err := common.GetOwnerAndGroupInfo(info)
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", path, err)
+		}
+		if uid != "" && actualUID != uid {
+			return &ErrCheckDirBadOwner{Path: path, Expected: uid, Actual: actualUID}
+		}
+		if gid != "" && actualGID != gid {
+			return &ErrCheckDirBadGroup{Path: path, Expected: gid, Actual: actualGID}
+		}
+		return nil
+	})
+}
+
+// checkModeTree fails with ErrCheckDirTreePermissions on the first entry below root with permission bits outside
+// maxPerms, e.g. a world-writable file under a maxPerms of 0775
+func checkModeTree(ctx context.Context, root string, maxEntries int, walk common.WalkOptions, maxPerms os.FileMode) error {
+	return walkTree(ctx, root, maxEntries, walk, func(path string, info os.FileInfo) error {
+		if !common.IsLessPermissiveThanInfo(info, maxPerms) {
+			return &ErrCheckDirTreePermissions{Path: path, Mode: info.Mode().Perm(), Max: maxPerms}
+		}
+		return nil
+	})
+}
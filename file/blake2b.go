@@ -0,0 +1,35 @@
+//go:build checkfs_blake2b
+
+package file
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/andreimerlescu/checkfs/common"
+	"golang.org/x/crypto/blake2b"
+)
+
+// blake2bFS streams name from fsys (the OS filesystem when nil) through an unkeyed Blake2b hash with a size-byte
+// digest and returns it hex-encoded
+func blake2bFS(ctx context.Context, fsys fs.FS, name string, size int) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	h, err := blake2b.New(size, nil)
+	if err != nil {
+		return "", err
+	}
+	f, err := common.OpenFS(fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, common.ContextReader(ctx, f)); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,14 @@
+//go:build !checkfs_blake2b
+
+package file
+
+import (
+	"context"
+	"io/fs"
+)
+
+// blake2bFS always fails with ErrBlake2bUnavailable so golang.org/x/crypto is only linked into builds that opt in
+// with -tags checkfs_blake2b
+func blake2bFS(_ context.Context, _ fs.FS, _ string, _ int) (string, error) {
+	return "", ErrBlake2bUnavailable
+}
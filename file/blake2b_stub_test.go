@@ -0,0 +1,20 @@
+//go:build !checkfs_blake2b
+
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileExpectedBlake2bUnavailable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.txt")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := File(path, Options{ExpectedBlake2b: "00"}); !errors.Is(err, ErrBlake2bUnavailable) {
+		t.Errorf("File() error = %v, want ErrBlake2bUnavailable", err)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build checkfs_blake2b
+
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileExpectedBlake2b(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.txt")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	const (
+		abc512 = "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"
+		abc256 = "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"
+	)
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Blake2b-512 match", Options{ExpectedBlake2b: abc512}, false},
+		{"Blake2b-512 uppercase match", Options{ExpectedBlake2b: "BA80A53F981C4D0D6A2797B69F12F6E94C212F14685AC4B74B12BB6FDBFFA2D17D87C5392AAB792DC252D5DE4533CC9518D38AA8DBF1925AB92386EDD4009923"}, false},
+		{"Blake2b-256 match", Options{ExpectedBlake2b: abc256, Blake2bSize: 32}, false},
+		{"Blake2b-256 digest with default size", Options{ExpectedBlake2b: abc256}, true},
+		{"Mismatch", Options{ExpectedBlake2b: abc512[:len(abc512)-1] + "0"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrContentMismatch) {
+				t.Errorf("File() error = %v, want ErrContentMismatch", err)
+			}
+		})
+	}
+}
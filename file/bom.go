@@ -0,0 +1,42 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// byteOrderMarks are the BOMs ForbidBOM and RequireBOM recognize, named by the encoding they announce
+var byteOrderMarks = []struct {
+	encoding string
+	mark     []byte
+}{
+	{"UTF-8", []byte{0xEF, 0xBB, 0xBF}},
+	{"UTF-16LE", []byte{0xFF, 0xFE}},
+	{"UTF-16BE", []byte{0xFE, 0xFF}},
+}
+
+// byteOrderMark returns the encoding named by the byte order mark the file at path starts with, or "" when it has
+// none, reading at most the first 3 bytes
+func byteOrderMark(ctx context.Context, fsys fs.FS, path string) (string, error) {
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	head := make([]byte, 3)
+	n, err := io.ReadFull(common.ContextReader(ctx, f), head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	for _, bom := range byteOrderMarks {
+		if bytes.HasPrefix(head[:n], bom.mark) {
+			return bom.encoding, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,74 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileBOM(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"bom.csv":       "\xEF\xBB\xBFid,name\n",
+		"clean.csv":     "id,name\n",
+		"utf16le.txt":   "\xFF\xFEh\x00i\x00",
+		"utf16be.txt":   "\xFE\xFF\x00h\x00i",
+		"short.txt":     "ab",
+		"truncated.txt": "\xEF\xBB",
+		"bom-only.txt":  "\xEF\xBB\xBF",
+		"empty.txt":     "",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		opts    Options
+		wantErr error
+	}{
+		{"UTF-8 BOM forbidden", "bom.csv", Options{ForbidBOM: true}, &ErrCheckBOM{Encoding: "UTF-8"}},
+		{"UTF-8 BOM required", "bom.csv", Options{RequireBOM: true}, nil},
+		{"Clean file", "clean.csv", Options{ForbidBOM: true}, nil},
+		{"Clean file without the required BOM", "clean.csv", Options{RequireBOM: true}, &ErrCheckBOM{}},
+		{"UTF-16LE BOM forbidden", "utf16le.txt", Options{ForbidBOM: true}, &ErrCheckBOM{Encoding: "UTF-16LE"}},
+		{"UTF-16BE BOM forbidden", "utf16be.txt", Options{ForbidBOM: true}, &ErrCheckBOM{Encoding: "UTF-16BE"}},
+		{"UTF-16BE BOM required", "utf16be.txt", Options{RequireBOM: true}, nil},
+		{"Shorter than a BOM", "short.txt", Options{ForbidBOM: true}, nil},
+		{"Shorter than a BOM without the required BOM", "short.txt", Options{RequireBOM: true}, &ErrCheckBOM{}},
+		{"Truncated BOM is no BOM", "truncated.txt", Options{ForbidBOM: true}, nil},
+		{"Nothing but a BOM", "bom-only.txt", Options{RequireBOM: true}, nil},
+		{"Empty file", "empty.txt", Options{ForbidBOM: true}, nil},
+		{"Empty file without the required BOM", "empty.txt", Options{RequireBOM: true}, &ErrCheckBOM{}},
+		{"Forbid and Require", "clean.csv", Options{ForbidBOM: true, RequireBOM: true}, ErrInvalidOptions},
+		{"Require and MustBeEmpty", "empty.txt", Options{RequireBOM: true, MustBeEmpty: true}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			err := File(path, tt.opts)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+			case *ErrCheckBOM:
+				var got *ErrCheckBOM
+				if !errors.As(err, &got) || !errors.Is(err, ErrContentMismatch) {
+					t.Fatalf("File() error = %v, want *ErrCheckBOM", err)
+				}
+				if got.Path != path || got.Encoding != want.Encoding {
+					t.Errorf("ErrCheckBOM = %+v, want Encoding %q", got, want.Encoding)
+				}
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("File() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
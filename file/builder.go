@@ -0,0 +1,192 @@
+package file
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// Builder assembles Options one chained call at a time, as sugar over an Options literal:
+//
+//	err := file.Check(path).Exists().Ext(".txt").MaxSize(1 << 20).Owner("deploy").Run()
+//
+// Every method sets the Options field its comment names and returns the Builder; With reaches any field without one.
+type Builder struct {
+	path string
+	opts Options
+}
+
+// Check starts a Builder for the file at path
+func Check(path string) *Builder {
+	return &Builder{path: path}
+}
+
+// Exists sets Exists
+func (b *Builder) Exists() *Builder {
+	return b.With(Exists())
+}
+
+// Ext sets RequireExt, e.g. ".txt"
+func (b *Builder) Ext(ext string) *Builder {
+	return b.With(WithExt(ext))
+}
+
+// Exts sets RequireExts
+func (b *Builder) Exts(exts ...string) *Builder {
+	return b.With(WithExts(exts...))
+}
+
+// Prefix sets RequirePrefix
+func (b *Builder) Prefix(prefix string) *Builder {
+	return b.With(WithPrefix(prefix))
+}
+
+// Suffix sets RequireSuffix
+func (b *Builder) Suffix(suffix string) *Builder {
+	return b.With(WithSuffix(suffix))
+}
+
+// Size sets IsSize, see WithSize; use Empty to require an empty file
+func (b *Builder) Size(n int64) *Builder {
+	return b.With(WithSize(n))
+}
+
+// MaxSize sets MaxSize, see WithMaxSize
+func (b *Builder) MaxSize(n int64) *Builder {
+	return b.With(WithMaxSize(n))
+}
+
+// MinSize sets MinSize, see WithMinSize
+func (b *Builder) MinSize(n int64) *Builder {
+	return b.With(WithMinSize(n))
+}
+
+// NonEmpty sets NonEmpty
+func (b *Builder) NonEmpty() *Builder {
+	return b.With(NonEmpty())
+}
+
+// Empty sets MustBeEmpty
+func (b *Builder) Empty() *Builder {
+	return b.With(MustBeEmpty())
+}
+
+// Owner sets RequireOwnerName, e.g. "deploy"
+func (b *Builder) Owner(name string) *Builder {
+	return b.With(WithOwner(name))
+}
+
+// Group sets RequireGroupName
+func (b *Builder) Group(name string) *Builder {
+	return b.With(WithGroup(name))
+}
+
+// OwnerUID sets RequireOwner, the numeric UID as a string
+func (b *Builder) OwnerUID(uid string) *Builder {
+	return b.With(WithOwnerUID(uid))
+}
+
+// GroupGID sets RequireGroup, the numeric GID as a string
+func (b *Builder) GroupGID(gid string) *Builder {
+	return b.With(WithGroupGID(gid))
+}
+
+// BaseDir sets RequireBaseDir
+func (b *Builder) BaseDir(dir string) *Builder {
+	return b.With(WithBaseDir(dir))
+}
+
+// Mode sets IsFileMode
+func (b *Builder) Mode(mode os.FileMode) *Builder {
+	return b.With(WithMode(mode))
+}
+
+// MorePermissiveThan sets MorePermissiveThan
+func (b *Builder) MorePermissiveThan(mode os.FileMode) *Builder {
+	return b.With(WithMorePermissiveThan(mode))
+}
+
+// LessPermissiveThan sets LessPermissiveThan
+func (b *Builder) LessPermissiveThan(mode os.FileMode) *Builder {
+	return b.With(WithLessPermissiveThan(mode))
+}
+
+// ReadOnly sets ReadOnly
+func (b *Builder) ReadOnly() *Builder {
+	return b.With(ReadOnly())
+}
+
+// Writable sets RequireWrite
+func (b *Builder) Writable() *Builder {
+	return b.With(Writable())
+}
+
+// Executable sets RequireExecutable
+func (b *Builder) Executable() *Builder {
+	return b.With(Executable())
+}
+
+// ModifiedBefore sets ModifiedBefore
+func (b *Builder) ModifiedBefore(t time.Time) *Builder {
+	return b.With(WithModifiedBefore(t))
+}
+
+// ModifiedAfter sets ModifiedAfter
+func (b *Builder) ModifiedAfter(t time.Time) *Builder {
+	return b.With(WithModifiedAfter(t))
+}
+
+// MaxAge sets MaxAge
+func (b *Builder) MaxAge(d time.Duration) *Builder {
+	return b.With(WithMaxAge(d))
+}
+
+// MinAge sets MinAge
+func (b *Builder) MinAge(d time.Duration) *Builder {
+	return b.With(WithMinAge(d))
+}
+
+// SHA256 sets RequireSHA256, a hex-encoded digest
+func (b *Builder) SHA256(digest string) *Builder {
+	return b.With(WithSHA256(digest))
+}
+
+// Content sets RequireContent
+func (b *Builder) Content(content []byte) *Builder {
+	return b.With(WithContent(content))
+}
+
+// ContentType sets RequireContentType, e.g. "image/png"
+func (b *Builder) ContentType(contentType string) *Builder {
+	return b.With(WithContentType(contentType))
+}
+
+// Create sets Create
+func (b *Builder) Create(create Create) *Builder {
+	return b.With(WithCreate(create))
+}
+
+// With applies opts, in order, to the Options being built, for fields that have no method of their own
+func (b *Builder) With(opts ...Option) *Builder {
+	for _, opt := range opts {
+		opt(&b.opts)
+	}
+	return b
+}
+
+// Options returns the Options built so far
+func (b *Builder) Options() Options { return b.opts }
+
+// Run performs the file checks with the built Options, exactly as File does
+func (b *Builder) Run() error { return File(b.path, b.opts) }
+
+// RunContext is Run with FileContext
+func (b *Builder) RunContext(ctx context.Context) error { return FileContext(ctx, b.path, b.opts) }
+
+// All performs every check with the built Options like FileAll
+func (b *Builder) All() []error { return FileAll(b.path, b.opts) }
+
+// Result performs every check with the built Options like FileResult
+func (b *Builder) Result() common.Result { return FileResult(b.path, b.opts) }
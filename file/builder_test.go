@@ -0,0 +1,69 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	stamp := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    Options
+	}{
+		{"Request example", Check("a.txt").Exists().Ext(".txt").MaxSize(1 << 20).Owner("deploy"),
+			Options{Exists: true, RequireExt: ".txt", MaxSize: 1 << 20, RequireOwnerName: "deploy"}},
+		{"Sizes", Check("a.txt").MinSize(10).Size(20), Options{MinSize: 10, IsSize: 20}},
+		{"Size 0 is unset", Check("a.txt").MinSize(1).Size(0), Options{MinSize: 1}},
+		{"Later size wins", Check("a.txt").Size(5).Size(0).MaxSize(0).MaxSize(10), Options{MaxSize: 10}},
+		{"Names", Check("a.txt").Exts(".yml", ".yaml").Prefix("app").Suffix("_final").BaseDir("/etc"),
+			Options{RequireExts: []string{".yml", ".yaml"}, RequirePrefix: "app", RequireSuffix: "_final", RequireBaseDir: "/etc"}},
+		{"Ownership", Check("a.txt").OwnerUID("0").GroupGID("0").Group("wheel"),
+			Options{RequireOwner: "0", RequireGroup: "0", RequireGroupName: "wheel"}},
+		{"Permissions", Check("a.sh").Mode(0755).MorePermissiveThan(0500).LessPermissiveThan(0755).Writable().Executable(),
+			Options{IsFileMode: 0755, MorePermissiveThan: 0500, LessPermissiveThan: 0755, RequireWrite: true, RequireExecutable: true}},
+		{"Times", Check("a.txt").ModifiedBefore(stamp).ModifiedAfter(stamp.Add(-time.Hour)).MaxAge(time.Hour).MinAge(time.Minute),
+			Options{ModifiedBefore: stamp, ModifiedAfter: stamp.Add(-time.Hour), MaxAge: time.Hour, MinAge: time.Minute}},
+		{"Content", Check("a.txt").NonEmpty().SHA256("ab").Content([]byte("x")).ContentType("text/plain"),
+			Options{NonEmpty: true, RequireSHA256: "ab", RequireContent: []byte("x"), RequireContentType: "text/plain"}},
+		{"With and Create", Check("a.txt").ReadOnly().Empty().Create(Create{Kind: IfNotExists}).With(func(o *Options) { o.MaxLines = 3 }),
+			Options{ReadOnly: true, MustBeEmpty: true, Create: Create{Kind: IfNotExists}, MaxLines: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.builder.Options(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Options() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := Check(path).Exists().Ext(".txt").MaxSize(5).Run(); err != nil {
+		t.Errorf("Run() error = %v, want nil", err)
+	}
+
+	built := Check(path).Exists().Ext(".md").MaxSize(4)
+	want := FileAll(path, Options{Exists: true, RequireExt: ".md", MaxSize: 4})
+	if got := built.All(); len(got) != 2 || !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+	err := built.Run()
+	if !errors.Is(err, ErrNameMismatch) || err.Error() != File(path, built.Options()).Error() {
+		t.Errorf("Run() error = %v, want the ErrNameMismatch File returns", err)
+	}
+	if result := built.Result(); result.Passed || len(result.Violations) != 2 {
+		t.Errorf("Result() = %+v, want 2 violations", result)
+	}
+}
@@ -0,0 +1,84 @@
+package file
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// VerifyChecksumFile checks the SHA-256 digest of the file at path against its sidecar checksumPath, path+".sha256"
+// when empty, as written by sha256sum or shasum -a 256: a "<hex>  <filename>" or "<hex> *<filename>" line naming
+// path's base name, or a bare digest on its own. Each way it can fail has its own error:
+//   - *ErrCheckMissingSidecar (ErrDoesNotExist) when the sidecar does not exist
+//   - *ErrCheckMalformedSidecar (ErrContentMismatch) when it cannot be parsed or has no entry for path
+//   - *ErrCheckBadChecksum (ErrContentMismatch) when the digests differ
+func VerifyChecksumFile(path, checksumPath string) error {
+	if checksumPath == "" {
+		checksumPath = path + ".sha256"
+	}
+	data, err := os.ReadFile(checksumPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &ErrCheckMissingSidecar{Path: path, Sidecar: checksumPath}
+		}
+		return fmt.Errorf("failed to read checksum sidecar %s: %w", checksumPath, err)
+	}
+	expected, err := sidecarDigest(data, filepath.Base(path))
+	if err != nil {
+		return &ErrCheckMalformedSidecar{Path: path, Sidecar: checksumPath, Reason: err.Error()}
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return common.Errorf(ErrDoesNotExist, "file does not exist: %s", path)
+	case err != nil:
+		return fmt.Errorf("failed to stat file %s: %w", path, err)
+	case !info.Mode().IsRegular():
+		return common.Errorf(ErrNotRegularFile, "not a regular file: %s", path)
+	}
+	actual, err := common.SHA256File(path)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	if actual != expected {
+		return &ErrCheckBadChecksum{Path: path, Expected: expected, Actual: actual}
+	}
+	return nil
+}
+
+// sidecarDigest returns the lowercase SHA-256 digest a checksum sidecar records for the file named name
+func sidecarDigest(data []byte, name string) (string, error) {
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return "", errors.New("it is empty")
+	}
+	if !strings.ContainsAny(text, " \t\n") {
+		return sha256Digest(text)
+	}
+	sums, err := common.ParseChecksums(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	for _, sum := range sums {
+		if filepath.Base(filepath.FromSlash(sum.Path)) == name {
+			return sha256Digest(sum.Digest)
+		}
+	}
+	return "", fmt.Errorf("no entry for %s", name)
+}
+
+// sha256Digest returns digest in lowercase when it is 64 hex digits, the length of a SHA-256 digest
+func sha256Digest(digest string) (string, error) {
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != 64 {
+		return "", fmt.Errorf("%q is not a hex-encoded SHA-256 digest", digest)
+	}
+	return strings.ToLower(digest), nil
+}
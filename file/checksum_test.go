@@ -0,0 +1,92 @@
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyChecksumFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "release.tar.gz")
+	if err := os.WriteFile(path, []byte("release contents"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	sum := sha256.Sum256([]byte("release contents"))
+	digest := hex.EncodeToString(sum[:])
+	other := strings.Repeat("0", 64)
+
+	tests := []struct {
+		name    string
+		sidecar string // sidecar contents; the sidecar is not written when empty
+		custom  bool   // write the sidecar to a path other than path+".sha256"
+		err     error
+	}{
+		{"Coreutils format", digest + "  release.tar.gz\n", false, nil},
+		{"Binary marker", digest + " *release.tar.gz\n", false, nil},
+		{"Bare digest", digest + "\n", false, nil},
+		{"Uppercase digest", strings.ToUpper(digest) + "  release.tar.gz\n", false, nil},
+		{"Several entries", other + "  notes.txt\n" + digest + "  ./release.tar.gz\n", true, nil},
+		{"Digest mismatch", other + "  release.tar.gz\n", false, &ErrCheckBadChecksum{}},
+		{"Malformed line", "not a checksum file\n", false, &ErrCheckMalformedSidecar{}},
+		{"Short digest", "abc123  release.tar.gz\n", false, &ErrCheckMalformedSidecar{}},
+		{"No entry for file", digest + "  other.tar.gz\n", false, &ErrCheckMalformedSidecar{}},
+		{"Blank sidecar", "\n", false, &ErrCheckMalformedSidecar{}},
+		{"Missing sidecar", "", false, &ErrCheckMissingSidecar{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sidecar := path + ".sha256"
+			if tt.custom {
+				sidecar = filepath.Join(dir, "SHA256SUMS")
+			}
+			_ = os.Remove(sidecar)
+			if tt.sidecar != "" {
+				if err := os.WriteFile(sidecar, []byte(tt.sidecar), 0644); err != nil {
+					t.Fatalf("Failed to create sidecar: %v", err)
+				}
+			}
+			checksumPath := ""
+			if tt.custom {
+				checksumPath = sidecar
+			}
+
+			err := VerifyChecksumFile(path, checksumPath)
+			switch want := tt.err.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("VerifyChecksumFile() error = %v", err)
+				}
+			case *ErrCheckBadChecksum:
+				if !errors.As(err, &want) || !errors.Is(err, ErrContentMismatch) {
+					t.Fatalf("VerifyChecksumFile() error = %v, want *ErrCheckBadChecksum", err)
+				}
+				if want.Expected != other || want.Actual != digest {
+					t.Errorf("ErrCheckBadChecksum = %+v, want Expected %s and Actual %s", want, other, digest)
+				}
+			case *ErrCheckMalformedSidecar:
+				if !errors.As(err, &want) || !errors.Is(err, ErrContentMismatch) || want.Sidecar != sidecar {
+					t.Errorf("VerifyChecksumFile() error = %v, want *ErrCheckMalformedSidecar for %s", err, sidecar)
+				}
+			case *ErrCheckMissingSidecar:
+				if !errors.As(err, &want) || !errors.Is(err, ErrDoesNotExist) || want.Sidecar != sidecar {
+					t.Errorf("VerifyChecksumFile() error = %v, want *ErrCheckMissingSidecar for %s", err, sidecar)
+				}
+			}
+		})
+	}
+
+	t.Run("Missing file", func(t *testing.T) {
+		missing := filepath.Join(dir, "missing.tar.gz")
+		if err := os.WriteFile(missing+".sha256", []byte(digest+"  missing.tar.gz\n"), 0644); err != nil {
+			t.Fatalf("Failed to create sidecar: %v", err)
+		}
+		if err := VerifyChecksumFile(missing, ""); !errors.Is(err, ErrDoesNotExist) || errors.As(err, new(*ErrCheckMissingSidecar)) {
+			t.Errorf("VerifyChecksumFile() error = %v, want ErrDoesNotExist for the file", err)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// Codec decodes a file's contents and encodes the decoded value back into bytes. A file is in canonical form for a
+// Codec when Encode(Decode(contents)) reproduces contents byte for byte.
+type Codec interface {
+	Decode(r io.Reader) (any, error)
+	Encode(v any) ([]byte, error)
+}
+
+// checkCanonical decodes the file at path with codec, re-encodes the result and fails when the bytes differ
+func checkCanonical(ctx context.Context, fsys fs.FS, path string, codec Codec) error {
+	original, err := readFile(ctx, fsys, path)
+	if err != nil {
+		return err
+	}
+	decoded, err := codec.Decode(bytes.NewReader(original))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	encoded, err := codec.Encode(decoded)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if bytes.Equal(original, encoded) {
+		return nil
+	}
+	offset := firstDifference(original, encoded)
+	return &ErrCheckNotCanonical{
+		Path:     path,
+		Offset:   offset,
+		Expected: excerpt(encoded, offset),
+		Actual:   excerpt(original, offset),
+	}
+}
+
+// checkContent fails when the file at path does not hold exactly expected. With trimmed set, trailing whitespace
+// (spaces, tabs, carriage returns and newlines) at the very end of both the file and expected is dropped before they
+// are compared; whitespace anywhere else, including at the end of inner lines, must still match.
+func checkContent(ctx context.Context, fsys fs.FS, path string, expected []byte, trimmed bool) error {
+	actual, err := readFile(ctx, fsys, path)
+	if err != nil {
+		return err
+	}
+	if trimmed {
+		actual = bytes.TrimRight(actual, trailingWhitespace)
+		expected = bytes.TrimRight(expected, trailingWhitespace)
+	}
+	if bytes.Equal(actual, expected) {
+		return nil
+	}
+	offset := firstDifference(actual, expected)
+	return &ErrCheckUnexpectedContent{
+		Path:     path,
+		Offset:   offset,
+		Expected: excerpt(expected, offset),
+		Actual:   excerpt(actual, offset),
+	}
+}
+
+// maxContentLine caps how long a single line may be for RequireContentRegex and ForbidContentRegex
+const maxContentLine = 1 << 20
+
+// matchLines scans the file at path line by line (without the line ending) and returns the 1-based number of the first
+// line re matches, or 0 when none does. A line longer than maxContentLine is an error rather than silently skipped.
+func matchLines(ctx context.Context, fsys fs.FS, path string, re *regexp.Regexp) (int, error) {
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(common.ContextReader(ctx, f))
+	scanner.Buffer(make([]byte, 0, 64*KB), maxContentLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if re.MatchString(strings.TrimSuffix(scanner.Text(), "\r")) {
+			return line, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			return 0, fmt.Errorf("line %d of %s is longer than %d bytes: %w", line+1, path, maxContentLine, err)
+		}
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return 0, nil
+}
+
+// utf8Chunk is how much of the file checkUTF8 validates at a time; tests shrink it to exercise chunk boundaries
+var utf8Chunk = 64 * KB
+
+// checkUTF8 streams the file at path through utf8.Valid a chunk at a time and fails with ErrCheckInvalidUTF8 at the
+// first invalid sequence. A multibyte sequence cut off by the end of a chunk is carried over to the next one, and is
+// only invalid if the file ends before it is complete.
+func checkUTF8(ctx context.Context, fsys fs.FS, path string) error {
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	r := common.ContextReader(ctx, f)
+	buf := make([]byte, utf8Chunk+utf8.UTFMax)
+	var offset int64
+	carry := 0
+	for {
+		n, readErr := r.Read(buf[carry : carry+utf8Chunk])
+		eof := readErr == io.EOF
+		if readErr != nil && !eof {
+			return fmt.Errorf("failed to read %s: %w", path, readErr)
+		}
+		data := buf[:carry+n]
+		keep := 0
+		if !eof {
+			keep = incompleteRune(data)
+		}
+		body := data[:len(data)-keep]
+		if !utf8.Valid(body) {
+			return &ErrCheckInvalidUTF8{Path: path, Offset: offset + int64(firstInvalidRune(body))}
+		}
+		if eof {
+			return nil
+		}
+		offset += int64(len(body))
+		carry = copy(buf, data[len(body):])
+	}
+}
+
+// incompleteRune returns the length of the truncated multibyte sequence at the end of data, or 0 when data ends on a
+// rune boundary
+func incompleteRune(data []byte) int {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax+1; i-- {
+		if utf8.RuneStart(data[i]) {
+			if utf8.FullRune(data[i:]) {
+				return 0
+			}
+			return len(data) - i
+		}
+	}
+	return 0
+}
+
+// firstInvalidRune returns the index of the first byte in data that does not start a valid UTF-8 sequence
+func firstInvalidRune(data []byte) int {
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return len(data)
+}
+
+// trailingWhitespace is the cutset CompareTrimmed removes from the end of both sides
+const trailingWhitespace = " \t\r\n"
+
+// firstDifference returns the index of the first byte where a and b differ, or the length of the shorter one
+func firstDifference(a, b []byte) int {
+	offset := 0
+	for offset < len(a) && offset < len(b) && a[offset] == b[offset] {
+		offset++
+	}
+	return offset
+}
+
+// readFile reads the whole file at path in fsys (the OS filesystem when nil), giving up with ctx.Err() once ctx is done
+func readFile(ctx context.Context, fsys fs.FS, path string) ([]byte, error) {
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(common.ContextReader(ctx, f))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// excerpt returns up to 16 bytes of data starting at offset, for use in error messages
+func excerpt(data []byte, offset int) string {
+	if offset >= len(data) {
+		return ""
+	}
+	end := offset + 16
+	if end > len(data) {
+		end = len(data)
+	}
+	return string(data[offset:end])
+}
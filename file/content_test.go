@@ -0,0 +1,206 @@
+package file
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// jsonCodec is a minimal Codec whose canonical form is compact JSON with sorted object keys
+type jsonCodec struct{}
+
+func (jsonCodec) Decode(r io.Reader) (any, error) {
+	var v any
+	err := json.NewDecoder(r).Decode(&v)
+	return v, err
+}
+
+func (jsonCodec) Encode(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func TestFileCanonicalCodec(t *testing.T) {
+	dir := t.TempDir()
+	canonical := filepath.Join(dir, "canonical.json")
+	pretty := filepath.Join(dir, "pretty.json")
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(canonical, []byte(`{"a":1,"b":[true,null]}`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(pretty, []byte(`{ "b": [true, null], "a": 1 }`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(invalid, []byte(`{"a":`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"Canonical JSON", canonical, false},
+		{"Non-canonical JSON", pretty, true},
+		{"Undecodable JSON", invalid, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, Options{CanonicalCodec: jsonCodec{}})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var canonicalErr *ErrCheckNotCanonical
+	if err := File(pretty, Options{CanonicalCodec: jsonCodec{}}); !errors.As(err, &canonicalErr) || canonicalErr.Offset != 1 {
+		t.Errorf("File() error = %v, want ErrCheckNotCanonical at offset 1", err)
+	}
+}
+
+func TestFileRequireContent(t *testing.T) {
+	dir := t.TempDir()
+	generated := filepath.Join(dir, "generated.txt")
+	if err := os.WriteFile(generated, []byte("line one\nline two\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+		trimmed  bool
+		wantErr  bool
+	}{
+		{"Exact match", "line one\nline two\n", false, false},
+		{"Missing trailing newline", "line one\nline two", false, true},
+		{"Missing trailing newline trimmed", "line one\nline two", true, false},
+		{"Extra trailing whitespace trimmed", "line one\nline two \t\r\n\n", true, false},
+		{"Inner trailing space is not trimmed", "line one \nline two", true, true},
+		{"Different content trimmed", "line one\nline 2", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(generated, Options{RequireContent: []byte(tt.expected), CompareTrimmed: tt.trimmed})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Reports the first differing byte", func(t *testing.T) {
+		var contentErr *ErrCheckUnexpectedContent
+		err := File(generated, Options{RequireContent: []byte("line one\nline 2")})
+		if !errors.As(err, &contentErr) || contentErr.Offset != 14 || !errors.Is(err, ErrContentMismatch) {
+			t.Errorf("File() error = %v, want ErrCheckUnexpectedContent at offset 14", err)
+		}
+	})
+
+	t.Run("CompareTrimmed without RequireContent", func(t *testing.T) {
+		if err := File(generated, Options{CompareTrimmed: true}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("File() error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
+
+func TestFileContentRegex(t *testing.T) {
+	dir := t.TempDir()
+	generated := filepath.Join(dir, "generated.go")
+	if err := os.WriteFile(generated, []byte("// Code generated by protoc. DO NOT EDIT.\r\n\npackage api\nconst token = \"abc\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	oversized := filepath.Join(dir, "minified.js")
+	if err := os.WriteFile(oversized, []byte("ok\n"+strings.Repeat("x", maxContentLine+1)+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"Marker line matches", generated, Options{RequireContentRegex: `^// Code generated .* DO NOT EDIT\.$`}, nil},
+		{"Later line matches", generated, Options{RequireContentRegex: `^package \w+$`}, nil},
+		{"No line matches", generated, Options{RequireContentRegex: `^package main$`}, &ErrCheckContentNoMatch{}},
+		{"Pattern does not span lines", generated, Options{RequireContentRegex: `EDIT\.\s+package`}, &ErrCheckContentNoMatch{}},
+		{"Multi-line flags are per line", generated, Options{RequireContentRegex: `(?m)^const token`}, nil},
+		{"Forbidden pattern absent", generated, Options{ForbidContentRegex: `AKIA[0-9A-Z]{16}`}, nil},
+		{"Forbidden pattern present", generated, Options{ForbidContentRegex: `token = "`}, &ErrCheckContentForbidden{}},
+		{"Bad pattern", generated, Options{RequireContentRegex: `(unclosed`}, ErrInvalidOptions},
+		{"Oversized line", oversized, Options{ForbidContentRegex: `secret`}, bufio.ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+			case *ErrCheckContentNoMatch:
+				if !errors.As(err, &want) || want.Pattern != tt.opts.RequireContentRegex || !errors.Is(err, ErrContentMismatch) {
+					t.Errorf("File() error = %v, want *ErrCheckContentNoMatch", err)
+				}
+			case *ErrCheckContentForbidden:
+				if !errors.As(err, &want) || want.Line != 4 || !errors.Is(err, ErrContentMismatch) {
+					t.Errorf("File() error = %v, want *ErrCheckContentForbidden on line 4", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Errorf("File() error = %v, want %v", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileRequireValidUTF8(t *testing.T) {
+	defer func(original int) { utf8Chunk = original }(utf8Chunk)
+	utf8Chunk = 8
+
+	dir := t.TempDir()
+	tests := []struct {
+		name       string
+		contents   string
+		wantOffset int64 // wantOffset is the offset of the first invalid byte, -1 when the file is valid
+	}{
+		{"Empty", "", -1},
+		{"ASCII", "hello, world\n", -1},
+		{"Multibyte", "naïve café ☕ 𝄞\n", -1},
+		{"Split across the chunk boundary", "1234567€€€", -1},
+		{"Four byte rune split across the boundary", "123456𝄞𝄞", -1},
+		{"Invalid byte mid-file", "Latin-1 caf\xe9 au lait", 11},
+		{"Invalid byte in a later chunk", "abcdefghijklmnop\xff", 16},
+		{"Truncated at end of file", "abcdefg\xe2\x82", 7},
+		{"Stray continuation byte", "ab\x80cd", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "utf8.txt")
+			if err := os.WriteFile(path, []byte(tt.contents), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+			err := File(path, Options{RequireValidUTF8: true})
+			if tt.wantOffset < 0 {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			var invalid *ErrCheckInvalidUTF8
+			if !errors.As(err, &invalid) || invalid.Offset != tt.wantOffset {
+				t.Fatalf("File() error = %v, want *ErrCheckInvalidUTF8 at offset %d", err, tt.wantOffset)
+			}
+			if !errors.Is(err, ErrContentMismatch) {
+				t.Errorf("File() error = %v, want it to match ErrContentMismatch", err)
+			}
+		})
+	}
+}
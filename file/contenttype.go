@@ -0,0 +1,55 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// sniffLen is how much of a file http.DetectContentType considers
+const sniffLen = 512
+
+// sniffContentType runs http.DetectContentType over the first sniffLen bytes of the file at path, never reading more
+func sniffContentType(ctx context.Context, fsys fs.FS, path string) (string, error) {
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(common.ContextReader(ctx, f), head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// contentTypeMatches reports whether actual, as returned by http.DetectContentType, is expected; parameters such as
+// "; charset=utf-8" are only compared when expected has some
+func contentTypeMatches(actual, expected string) bool {
+	if actual == expected {
+		return true
+	}
+	if strings.Contains(expected, ";") {
+		return false
+	}
+	mediaType, _, _ := strings.Cut(actual, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), strings.TrimSpace(expected))
+}
+
+// contentType returns the sniffed content type of the file, detecting it once however many checks ask
+func (s *state) contentType() (string, error) {
+	if s.sniffed == "" {
+		sniffed, err := sniffContentType(s.ctx, s.fsys, s.path)
+		if err != nil {
+			return "", err
+		}
+		s.sniffed = sniffed
+	}
+	return s.sniffed, nil
+}
@@ -0,0 +1,75 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileRequireContentType(t *testing.T) {
+	dir := t.TempDir()
+	png := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), make([]byte, 1024)...)
+	files := map[string][]byte{
+		"photo.png":    png,
+		"photo.txt":    png, // the extension does not matter
+		"notes.png":    []byte("just some notes\n"),
+		"empty.png":    nil,
+		"long.txt":     []byte(strings.Repeat("a", 511) + "\x00"), // the NUL byte at 512 is inside the sniff window
+		"longtail.txt": []byte(strings.Repeat("a", 512) + "\x00"), // and here it is just past it
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), contents, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		opts       Options
+		wantActual string // wantActual is the sniffed type on failure, empty when the check passes
+	}{
+		{"PNG header", "photo.png", Options{RequireContentType: "image/png"}, ""},
+		{"PNG with a misleading extension", "photo.txt", Options{RequireContentType: "image/png"}, ""},
+		{"PNG prefix", "photo.png", Options{RequireContentTypePrefix: "image/"}, ""},
+		{"Text is not PNG", "notes.png", Options{RequireContentType: "image/png"}, "text/plain; charset=utf-8"},
+		{"Text without charset", "notes.png", Options{RequireContentType: "text/plain"}, ""},
+		{"Text with charset", "notes.png", Options{RequireContentType: "text/plain; charset=utf-8"}, ""},
+		{"Text is not an image", "notes.png", Options{RequireContentTypePrefix: "image/"}, "text/plain; charset=utf-8"},
+		{"Empty file", "empty.png", Options{RequireContentType: "image/png"}, "text/plain; charset=utf-8"},
+		{"Binary inside the sniff window", "long.txt", Options{RequireContentType: "text/plain"}, "application/octet-stream"},
+		{"Binary past the sniff window", "longtail.txt", Options{RequireContentType: "text/plain"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			err := File(path, tt.opts)
+			if tt.wantActual == "" {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			var bad *ErrCheckBadContentType
+			if !errors.As(err, &bad) {
+				t.Fatalf("File() error = %v, want *ErrCheckBadContentType", err)
+			}
+			if bad.Path != path || bad.Actual != tt.wantActual {
+				t.Errorf("ErrCheckBadContentType = %+v, want Path %s and Actual %s", bad, path, tt.wantActual)
+			}
+			if !errors.Is(err, ErrContentMismatch) {
+				t.Errorf("File() error = %v, want it to match ErrContentMismatch", err)
+			}
+		})
+	}
+
+	t.Run("Through fs.FS", func(t *testing.T) {
+		fsys := fstest.MapFS{"uploads/avatar": {Data: png}}
+		if err := FileFS(fsys, "uploads/avatar", Options{RequireContentType: "image/png", RequireContentTypePrefix: "image/"}); err != nil {
+			t.Errorf("FileFS() error = %v", err)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// CopyOptions controls Copy
+type CopyOptions struct {
+	Overwrite     bool // Overwrite replaces an existing dst, otherwise Copy fails with ErrAlreadyExists
+	PreserveMode  bool // PreserveMode gives dst exactly the permission bits of src, bypassing the umask
+	PreserveTimes bool // PreserveTimes gives dst the modification time of src, also used as its access time
+	Sync          bool // Sync fsyncs dst and its parent directory before Copy returns
+}
+
+// copyBufferSize is the size of the buffer Copy streams the contents through
+const copyBufferSize = 32 * KB
+
+// copyBuffer streams src into dst for Copy, tests replace it to simulate a failure part way through
+var copyBuffer = io.CopyBuffer
+
+// Copy copies the regular file src to dst. A new dst gets the permission bits of src minus the umask, like cp; an
+// existing dst, replaced only with Overwrite, keeps its own mode. PreserveMode and PreserveTimes carry over the exact
+// mode and modification time of src instead. A dst that Copy created is removed on any error, and an existing dst is
+// replaced through a temp file renamed over it, so a failed copy never leaves a partial dst behind. src and dst must
+// not be the same file.
+func Copy(src, dst string, opts CopyOptions) error {
+	srcInfo, err := os.Stat(src)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return common.Errorf(ErrDoesNotExist, "copy source does not exist: %s", src)
+	case err != nil:
+		return fmt.Errorf("failed to stat file %s: %w", src, err)
+	case !srcInfo.Mode().IsRegular():
+		return common.Errorf(ErrNotRegularFile, "cannot copy %s: not a regular file", src)
+	}
+	dstInfo, err := os.Stat(dst)
+	exists := err == nil
+	switch {
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to stat file %s: %w", dst, err)
+	case exists && !opts.Overwrite:
+		return common.Errorf(ErrAlreadyExists, "cannot copy %s to %s: destination exists", src, dst)
+	case exists && !dstInfo.Mode().IsRegular():
+		return common.Errorf(ErrNotRegularFile, "cannot copy %s to %s: destination is not a regular file", src, dst)
+	case exists && os.SameFile(srcInfo, dstInfo):
+		return fmt.Errorf("%w: cannot copy %s onto itself", ErrInvalidOptions, src)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	var out *os.File
+	if exists {
+		out, err = os.CreateTemp(filepath.Dir(dst), ".tmp-*")
+		if err == nil {
+			err = out.Chmod(dstInfo.Mode().Perm())
+		}
+	} else {
+		out, err = os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, srcInfo.Mode().Perm())
+	}
+	if err != nil {
+		if out != nil {
+			_ = out.Close()
+			_ = os.Remove(out.Name())
+		}
+		return fmt.Errorf("could not create %s: %w", dst, err)
+	}
+	if err := copyTo(out, in, srcInfo, opts); err != nil {
+		_ = out.Close()
+		_ = os.Remove(out.Name())
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dst, err)
+	}
+	if exists {
+		if err := os.Rename(out.Name(), dst); err != nil {
+			_ = os.Remove(out.Name())
+			return fmt.Errorf("could not rename temp file: %w", err)
+		}
+	}
+	if opts.PreserveTimes {
+		if err := os.Chtimes(dst, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
+			return fmt.Errorf("could not set times on %s: %w", dst, err)
+		}
+	}
+	if opts.Sync {
+		if err := common.SyncDir(filepath.Dir(dst)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyTo streams in to out, applies the CopyOptions that act on the open file and closes out
+func copyTo(out, in *os.File, srcInfo os.FileInfo, opts CopyOptions) error {
+	if _, err := copyBuffer(out, in, make([]byte, copyBufferSize)); err != nil {
+		return err
+	}
+	if opts.PreserveMode {
+		if err := out.Chmod(srcInfo.Mode().Perm()); err != nil {
+			return fmt.Errorf("could not chmod: %w", err)
+		}
+	}
+	if opts.Sync {
+		if err := out.Sync(); err != nil {
+			return fmt.Errorf("could not sync: %w", err)
+		}
+	}
+	return out.Close()
+}
@@ -0,0 +1,162 @@
+package file
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCopy(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("source contents"), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	if err := os.Chmod(src, 0640); err != nil {
+		t.Fatalf("Failed to chmod source: %v", err)
+	}
+	stamp := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(src, stamp, stamp); err != nil {
+		t.Fatalf("Failed to set source times: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		existing []byte // existing is written to dst first when non-nil
+		opts     CopyOptions
+		wantErr  error
+		want     string
+	}{
+		{"New destination", nil, CopyOptions{}, nil, "source contents"},
+		{"Existing without Overwrite", []byte("keep me"), CopyOptions{}, ErrAlreadyExists, "keep me"},
+		{"Existing with Overwrite", []byte("replace me"), CopyOptions{Overwrite: true}, nil, "source contents"},
+		{"Preserve and sync", nil, CopyOptions{PreserveMode: true, PreserveTimes: true, Sync: true}, nil, "source contents"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(dir, "dst"+string(rune('a'+i))+".txt")
+			if tt.existing != nil {
+				if err := os.WriteFile(dst, tt.existing, 0600); err != nil {
+					t.Fatalf("Failed to create destination: %v", err)
+				}
+			}
+			err := Copy(src, dst, tt.opts)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Copy() error = %v, want %v", err, tt.wantErr)
+			}
+			if got, err := os.ReadFile(dst); err != nil || string(got) != tt.want {
+				t.Errorf("destination = %q, %v, want %q", got, err, tt.want)
+			}
+			if runtime.GOOS == "windows" {
+				return
+			}
+			info, err := os.Stat(dst)
+			if err != nil {
+				t.Fatalf("Failed to stat destination: %v", err)
+			}
+			if tt.existing != nil && info.Mode().Perm() != 0600 {
+				t.Errorf("existing destination mode = %o, want it kept at 0600", info.Mode().Perm())
+			}
+			if tt.opts.PreserveMode && info.Mode().Perm() != 0640 {
+				t.Errorf("destination mode = %o, want 0640 from the source", info.Mode().Perm())
+			}
+			if tt.opts.PreserveTimes != info.ModTime().Equal(stamp) {
+				t.Errorf("destination modtime = %v, PreserveTimes %v", info.ModTime(), tt.opts.PreserveTimes)
+			}
+		})
+	}
+}
+
+func TestCopyErrors(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("source contents"), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		src     string
+		dst     string
+		opts    CopyOptions
+		wantErr error
+	}{
+		{"Missing source", filepath.Join(dir, "missing.txt"), filepath.Join(dir, "a.txt"), CopyOptions{}, ErrDoesNotExist},
+		{"Directory source", dir, filepath.Join(dir, "b.txt"), CopyOptions{}, ErrNotRegularFile},
+		{"Directory destination", src, t.TempDir(), CopyOptions{Overwrite: true}, ErrNotRegularFile},
+		{"Onto itself", src, src, CopyOptions{Overwrite: true}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Copy(tt.src, tt.dst, tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Copy() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if got, err := os.ReadFile(src); err != nil || string(got) != "source contents" {
+		t.Errorf("source after copying onto itself = %q, %v, want it untouched", got, err)
+	}
+}
+
+func TestCopyFailure(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	if err := os.WriteFile(src, []byte("source contents"), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	errRead := errors.New("read failed")
+	defer func(c func(io.Writer, io.Reader, []byte) (int64, error)) { copyBuffer = c }(copyBuffer)
+	copyBuffer = func(dst io.Writer, _ io.Reader, _ []byte) (int64, error) {
+		n, _ := dst.Write([]byte("sour"))
+		return int64(n), errRead
+	}
+
+	t.Run("New destination is removed", func(t *testing.T) {
+		dst := filepath.Join(dir, "new.txt")
+		if err := Copy(src, dst, CopyOptions{}); !errors.Is(err, errRead) {
+			t.Fatalf("Copy() error = %v, want the read error", err)
+		}
+		if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("partial destination left behind: %v", err)
+		}
+	})
+
+	t.Run("Existing destination is untouched", func(t *testing.T) {
+		dst := filepath.Join(dir, "existing.txt")
+		if err := os.WriteFile(dst, []byte("original"), 0644); err != nil {
+			t.Fatalf("Failed to create destination: %v", err)
+		}
+		if err := Copy(src, dst, CopyOptions{Overwrite: true}); !errors.Is(err, errRead) {
+			t.Fatalf("Copy() error = %v, want the read error", err)
+		}
+		if got, err := os.ReadFile(dst); err != nil || string(got) != "original" {
+			t.Errorf("destination = %q, %v, want the original", got, err)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) != 2 {
+			t.Errorf("directory has %d entries (%v), want no temp file left behind", len(entries), err)
+		}
+	})
+}
+
+func TestCopyUnreadableSource(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("needs a platform and user that permission bits apply to")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "secret.txt")
+	if err := os.WriteFile(src, []byte("x"), 0000); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	dst := filepath.Join(dir, "copy.txt")
+	if err := Copy(src, dst, CopyOptions{}); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("Copy() error = %v, want os.ErrPermission", err)
+	}
+	if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("destination left behind: %v", err)
+	}
+}
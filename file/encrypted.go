@@ -0,0 +1,131 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// EncryptionFormat selects the envelope RequireEncrypted expects a file to be wrapped in
+type EncryptionFormat int8
+
+const (
+	// NoEncryption leaves RequireEncrypted unset
+	NoEncryption EncryptionFormat = iota
+
+	// Age accepts binary age files ("age-encryption.org/v1" header) and complete ASCII-armored age files
+	Age
+
+	// PGPArmor accepts a complete "-----BEGIN PGP MESSAGE-----" ... "-----END PGP MESSAGE-----" envelope
+	PGPArmor
+
+	// PGPBinary accepts binary OpenPGP messages that start with a public-key or symmetric-key encrypted session key
+	// packet
+	PGPBinary
+)
+
+func (f EncryptionFormat) String() string {
+	switch f {
+	case NoEncryption:
+		return "none"
+	case Age:
+		return "age"
+	case PGPArmor:
+		return "pgp-armor"
+	case PGPBinary:
+		return "pgp-binary"
+	}
+	return fmt.Sprintf("EncryptionFormat(%d)", int8(f))
+}
+
+const (
+	ageHeader      = "age-encryption.org/v1\n"
+	ageArmorBegin  = "-----BEGIN AGE ENCRYPTED FILE-----"
+	ageArmorEnd    = "-----END AGE ENCRYPTED FILE-----"
+	pgpArmorBegin  = "-----BEGIN PGP MESSAGE-----"
+	pgpArmorEnd    = "-----END PGP MESSAGE-----"
+	pgpTagPKESK    = 1 // Public-Key Encrypted Session Key packet
+	pgpTagSKESK    = 3 // Symmetric-Key Encrypted Session Key packet
+	encryptedProbe = len(ageArmorBegin)
+)
+
+// checkEncrypted fails with ErrCheckNotEncrypted unless the file at path is wrapped in format: the leading magic bytes
+// must match and, for armored files, the whole file must be a single complete armor envelope
+func checkEncrypted(ctx context.Context, fsys fs.FS, path string, format EncryptionFormat) error {
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(common.ContextReader(ctx, f))
+	head, peekErr := r.Peek(encryptedProbe)
+	if peekErr != nil && peekErr != io.EOF {
+		return fmt.Errorf("failed to read %s: %w", path, peekErr)
+	}
+
+	var ok bool
+	switch format {
+	case Age:
+		if bytes.HasPrefix(head, []byte(ageHeader)) {
+			ok = true
+		} else {
+			ok, err = armored(r, ageArmorBegin, ageArmorEnd)
+		}
+	case PGPArmor:
+		ok, err = armored(r, pgpArmorBegin, pgpArmorEnd)
+	case PGPBinary:
+		ok = len(head) > 0 && isPGPSessionKeyPacket(head[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !ok {
+		return &ErrCheckNotEncrypted{Path: path, Format: format}
+	}
+	return nil
+}
+
+// armored reports whether r holds exactly one armor envelope: begin on the first non-blank line, end on the last one,
+// and no other armor lines in between
+func armored(r io.Reader, begin, end string) (bool, error) {
+	scanner := bufio.NewScanner(r)
+	var first, last string
+	lines := 0
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), trailingWhitespace)
+		if line == "" {
+			continue
+		}
+		lines++
+		if lines == 1 {
+			first = line
+		} else if lines > 2 && strings.HasPrefix(last, "-----") {
+			// an armor line that is not the final one means a truncated or concatenated envelope
+			return false, nil
+		}
+		last = line
+	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+	return lines > 1 && first == begin && last == end, nil
+}
+
+// isPGPSessionKeyPacket reports whether b is the header byte of an OpenPGP PKESK or SKESK packet, in either the old or
+// the new packet format
+func isPGPSessionKeyPacket(b byte) bool {
+	if b&0x80 == 0 {
+		return false
+	}
+	tag := b & 0x3f
+	if b&0x40 == 0 {
+		tag = (b >> 2) & 0x0f
+	}
+	return tag == pgpTagPKESK || tag == pgpTagSKESK
+}
@@ -0,0 +1,82 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileRequireEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	pgpArmored := "-----BEGIN PGP MESSAGE-----\n\nhQEMA0xJ2r8bAAAAAQf/WmE=\n=AbCd\n-----END PGP MESSAGE-----\n"
+	ageArmored := "-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCg==\n-----END AGE ENCRYPTED FILE-----\n"
+	files := map[string]string{
+		"secret.age":       "age-encryption.org/v1\n-> X25519 c2VjcmV0\nZmlsZQ\n--- aGVhZGVy\n\x00\x01\x02",
+		"secret.age.asc":   ageArmored,
+		"secret.asc":       pgpArmored,
+		"truncated.asc":    "-----BEGIN PGP MESSAGE-----\n\nhQEMA0xJ2r8bAAAAAQf/WmE=\n",
+		"concatenated.asc": pgpArmored + "plaintext trailer\n",
+		"pkesk.gpg":        "\xc1\x0c\x03\x4c\x49\xda\xbf\x1b",
+		"skesk.gpg":        "\x8c\x0d\x04\x09\x03\x08",
+		"signed.gpg":       "\x89\x01\x33\x04\x00",
+		"plain.txt":        "password=hunter2\n",
+		"empty.txt":        "",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		format  EncryptionFormat
+		wantErr bool
+	}{
+		{"Age binary header", "secret.age", Age, false},
+		{"Age armored", "secret.age.asc", Age, false},
+		{"PGP armored", "secret.asc", PGPArmor, false},
+		{"PGP binary public-key session key", "pkesk.gpg", PGPBinary, false},
+		{"PGP binary symmetric session key", "skesk.gpg", PGPBinary, false},
+		{"Plaintext as Age", "plain.txt", Age, true},
+		{"Plaintext as PGPArmor", "plain.txt", PGPArmor, true},
+		{"Plaintext as PGPBinary", "plain.txt", PGPBinary, true},
+		{"Empty file", "empty.txt", Age, true},
+		{"Truncated armor", "truncated.asc", PGPArmor, true},
+		{"Armor followed by plaintext", "concatenated.asc", PGPArmor, true},
+		{"Signature is not encryption", "signed.gpg", PGPBinary, true},
+		{"Age is not PGP", "secret.age", PGPArmor, true},
+		{"PGP armor is not age", "secret.asc", Age, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			err := File(path, Options{RequireEncrypted: tt.format})
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			var notEncrypted *ErrCheckNotEncrypted
+			if !errors.As(err, &notEncrypted) {
+				t.Fatalf("File() error = %v, want *ErrCheckNotEncrypted", err)
+			}
+			if notEncrypted.Path != path || notEncrypted.Format != tt.format {
+				t.Errorf("ErrCheckNotEncrypted = %+v, want Path %s and Format %s", notEncrypted, path, tt.format)
+			}
+			if !errors.Is(err, ErrContentMismatch) {
+				t.Errorf("File() error = %v, want it to match ErrContentMismatch", err)
+			}
+		})
+	}
+
+	t.Run("Unknown format", func(t *testing.T) {
+		err := File(filepath.Join(dir, "plain.txt"), Options{RequireEncrypted: EncryptionFormat(42)})
+		if !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("File() error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
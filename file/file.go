@@ -1,9 +1,17 @@
 package file
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,32 +30,67 @@ const (
 	// IfExists CreateKind will perform an action on the Create structure if the path exists
 	// This is intended to be a DESTRUCTIVE act when used since it removes the file first before Create.Run() is called.
 	IfExists CreateKind = iota
+
+	// EnsureValid CreateKind creates the file like IfNotExists when it is missing and otherwise converges the existing
+	// file on the Create in place, never removing it. See Create.Run for the rules.
+	EnsureValid CreateKind = iota
 )
 
 // Create is used to describe the File you wish to Create, you are not required to set the Path,
 // but you can if you wish to change it
 type Create struct {
-	Path     string      // Path stores where the resource will be created
-	Kind     CreateKind  // Kind requires either IfNotExists or another CreateKind
-	FileMode os.FileMode // FileMode allows you to set os.ModePerm etc.
-	OpenFlag int         // OpenFlag allows you to use os.O_CREATE|os.O_TRUNC|os.O_WRONLY
-	Size     int64       // Size allows you to fill a file with zeros, throws error if applied to a directory
+	Path      string      // Path stores where the resource will be created
+	Kind      CreateKind  // Kind requires either IfNotExists or another CreateKind
+	FileMode  os.FileMode // FileMode allows you to set os.ModePerm etc.
+	OpenFlag  int         // OpenFlag allows you to use os.O_CREATE|os.O_TRUNC|os.O_WRONLY
+	Size      int64       // Size allows you to fill a file with zeros, or checks the bytes written from Content/ContentReader
+	Sparse    bool        // Sparse extends the file to Size with Truncate instead of writing zeros, a hole where the filesystem allows
+	Atomic    bool        // Atomic writes to a sibling .tmp-* file and renames it over Path, OpenFlag is then ignored
+	Sync      bool        // Sync fsyncs the temp file before the rename, only used with Atomic
+	DryRun    bool        // DryRun makes Run only check that the create would succeed, see Plan
+	BackupDir string      // BackupDir receives the existing file on IfExists instead of it being removed, see RunWithBackup
+	Lock      bool        // Lock serializes concurrent creators of Path through an advisory lock on Path+".lock", see Run
+
+	Content       []byte    // Content is written to the file instead of Size zeros, nil is unset
+	ContentReader io.Reader `json:"-"` // ContentReader is copied into the file instead of Size zeros, cannot be used with Content
+
+	RequireBaseDir          string // RequireBaseDir refuses to touch a Path that escapes this directory, lexically or through symlinks
+	RequireFreeBytes        int64  // RequireFreeBytes refuses to write unless the filesystem holding Path has this much space available
+	RejectSymlinkComponents bool   // RejectSymlinkComponents refuses to write through a parent directory of Path that is a symlink
 }
 
 // NewCreate allows you to stack the .Run() call
 //
 // Example:
 //
-//			err := file.NewCreate(file.Create{
-//				Kind: file.IfNotExists,
-//				Path: "/opt/test.txt",
-//	  		OpenFlag: os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
-//				FileMode: 0644,
-//			}).Run()
+//	err := file.NewCreate(&file.Create{
+//		Kind:     file.IfNotExists,
+//		Path:     "/opt/test.txt",
+//		OpenFlag: os.O_CREATE | os.O_TRUNC | os.O_WRONLY,
+//		FileMode: 0644,
+//	}).Run()
 func NewCreate(create *Create) *Create {
-	return &Create{}
+	if create == nil {
+		return &Create{}
+	}
+	c := *create
+	return &c
 }
 
+var (
+	// ErrUnknownCreateKind is returned by Create.Run() when Kind is not one of the CreateKind constants
+	ErrUnknownCreateKind = errors.New("create kind not supported")
+
+	// ErrMissingOpenFlag is returned by Create.Run() when OpenFlag is unset without Atomic; use os.O_CREATE|os.O_WRONLY at minimum
+	ErrMissingOpenFlag = errors.New("create requires an OpenFlag such as os.O_CREATE|os.O_WRONLY")
+
+	// ErrBlake2bUnavailable is returned by the ExpectedBlake2b check unless checkfs is built with -tags checkfs_blake2b
+	ErrBlake2bUnavailable = errors.New("blake2b support not compiled in, build with -tags checkfs_blake2b")
+)
+
+// emptySHA256 is the SHA-256 digest of zero bytes, used so empty files are never opened for hashing
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
 const (
 	KB = 1 << (10 * iota)
 	MB
@@ -60,256 +103,2228 @@ func (create *Create) file() error {
 		return nil
 	}
 	defer func() { create.Kind = NoAction }()
+	if create.Atomic {
+		return create.atomicFile()
+	}
 	theFile, err := os.OpenFile(create.Path, create.OpenFlag, create.FileMode)
 	if err != nil {
 		return fmt.Errorf("could not create file: %w", err)
 	}
 	defer theFile.Close()
 
-	if create.Size > TB {
-		return fmt.Errorf("file size too big (max 1TB): %d", create.Size)
+	_, err = theFile.Seek(0, 0)
+	if err != nil {
+		return err
+	}
+	written, err := create.write(theFile)
+	if err != nil {
+		return fmt.Errorf("could not write to file: %w", err)
+	}
+	return create.checkWritten(written)
+}
+
+// write fills w from Content, ContentReader or else with Size zero bytes, returning the number of bytes written
+func (create *Create) write(w io.Writer) (int64, error) {
+	if create.ContentReader != nil {
+		return io.Copy(w, create.ContentReader)
+	}
+	if create.Content == nil {
+		return create.zeros(w)
 	}
+	if len(create.Content) == 0 {
+		return 0, nil
+	}
+	n, err := w.Write(create.Content)
+	return int64(n), err
+}
 
-	if create.Size > 0 {
-		b := make([]byte, create.Size)
-		for i := int64(0); i < create.Size; i++ {
-			b[i] = byte(i)
+// zeroChunk is the most zero bytes zeros writes at once, so a large Size is never allocated in full
+var zeroChunk int64 = 4 << 20
+
+// zeros writes Size zero bytes to w in chunks of at most zeroChunk, or, with Sparse and a w that can be truncated
+// such as an *os.File, sets its length to Size without writing anything
+func (create *Create) zeros(w io.Writer) (int64, error) {
+	if create.Size == 0 {
+		return 0, nil
+	}
+	if t, ok := w.(interface{ Truncate(size int64) error }); ok && create.Sparse {
+		if err := t.Truncate(create.Size); err != nil {
+			return 0, err
+		}
+		return create.Size, nil
+	}
+	chunk := zeroChunk
+	if create.Size < chunk {
+		chunk = create.Size
+	}
+	buf := make([]byte, chunk)
+	var written int64
+	for written < create.Size {
+		if remaining := create.Size - written; remaining < int64(len(buf)) {
+			buf = buf[:remaining]
 		}
-		_, err := theFile.Seek(0, 0)
+		n, err := w.Write(buf)
+		written += int64(n)
 		if err != nil {
-			return err
+			return written, err
 		}
-		bytesWritten, err := theFile.Write(b)
+	}
+	return written, nil
+}
+
+// checkWritten fails when Size is set and written disagrees with it
+func (create *Create) checkWritten(written int64) error {
+	if create.Size > 0 && written != create.Size {
+		return common.Errorf(ErrSizeMismatch, "wrote %d bytes to %s, want Size %d", written, create.Path, create.Size)
+	}
+	return nil
+}
+
+// writeTemp writes the contents to the temp file of an Atomic create, tests replace it to simulate a failed write
+var writeTemp = func(create *Create, f *os.File) (int64, error) {
+	return create.write(f)
+}
+
+// atomicFile writes the file to a temp file beside create.Path and renames it into place, so the target is either
+// untouched or complete; the temp file is removed on any error
+func (create *Create) atomicFile() (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(create.Path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not create temp file: %w", err)
+	}
+	defer func() {
 		if err != nil {
-			return fmt.Errorf("could not write to file: %w", err)
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
 		}
-		if bytesWritten != len(b) {
-			return fmt.Errorf("didnt write %d of %d to file", bytesWritten, create.Size)
+	}()
+
+	var written int64
+	written, err = writeTemp(create, tmp)
+	if err != nil {
+		return fmt.Errorf("could not write to file: %w", err)
+	}
+	if err = create.checkWritten(written); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(create.FileMode); err != nil {
+		return fmt.Errorf("could not chmod temp file: %w", err)
+	}
+	if create.Sync {
+		if err = tmp.Sync(); err != nil {
+			return fmt.Errorf("could not sync temp file: %w", err)
 		}
 	}
-
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("could not close temp file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), create.Path); err != nil {
+		return fmt.Errorf("could not rename temp file: %w", err)
+	}
 	return nil
 }
 
-func (create *Create) replaceFile() error {
+func (create *Create) replaceFile() (backup string, err error) {
 	if create.Kind != IfExists {
-		return nil
+		return "", nil
 	}
-	err := os.Remove(create.Path)
-	if err != nil {
-		return fmt.Errorf("could not remove file: %w", err)
+	if create.BackupDir != "" {
+		if backup, err = create.backup(); err != nil {
+			return "", err
+		}
 	}
 	create.Kind = IfNotExists
-	return create.file()
+	if create.Atomic {
+		// the rename replaces the file, removing it first would defeat the point
+		return backup, create.file()
+	}
+	if backup == "" {
+		if err := os.Remove(create.Path); err != nil {
+			return "", fmt.Errorf("could not remove file: %w", err)
+		}
+		return "", create.file()
+	}
+	if err := create.file(); err != nil {
+		return "", restore(backup, create.Path, err)
+	}
+	return backup, nil
 }
 
-func (create *Create) Run() error {
-	switch create.Kind {
-	case IfExists:
-		return create.replaceFile()
-	case IfNotExists:
-		return create.file()
-	default:
-		return fmt.Errorf("create kind not supported: %v", create.Kind)
+// backup copies the existing file into BackupDir for an Atomic create, which never leaves Path missing, and moves it
+// there otherwise
+func (create *Create) backup() (string, error) {
+	if err := os.MkdirAll(create.BackupDir, 0700); err != nil {
+		return "", fmt.Errorf("could not create backup directory: %w", err)
+	}
+	backup := common.BackupPath(create.BackupDir, create.Path)
+	move := common.MoveAll
+	if create.Atomic {
+		move = common.CopyAll
 	}
+	if err := move(create.Path, backup); err != nil {
+		return "", fmt.Errorf("could not back up file: %w", err)
+	}
+	return backup, nil
 }
 
-type Options struct {
-	CreatedBefore      time.Time   // Check file creation time
-	ModifiedBefore     time.Time   // Check file modified time
-	IsLessThan         int64       // Check if the size is less than
-	IsSize             int64       // Check the file size
-	IsGreaterThan      int64       // Check if the size is greater than
-	RequireExt         string      // Check if the file is of an extension
-	RequirePrefix      string      // Check if the file name begins with a prefix
-	RequireOwner       string      // Check if the file has a specific owner
-	RequireGroup       string      // Check if the file has a specific group
-	RequireBaseDir     string      // Check if the file is inside a specific base directory
-	IsFileMode         os.FileMode // Check the os.FileMode value
-	MorePermissiveThan os.FileMode // Check if mode is at least this permissive (e.g., >= 0444)
-	LessPermissiveThan os.FileMode // Check if mode is less permissive than this (e.g., <= 0400)
-	IsBaseNameLen      int         // Check if the file name length
-	RequireWrite       bool        // Check if the file is writable
-	ReadOnly           bool        // Check if the file is read-only
-	WriteOnly          bool        // Check if the file is write-only
-	Exists             bool        // Check if the file exists
-	Create             Create      // Allow the user to create the file
+// restore puts backup back at path after the create that replaced it failed with cause
+func restore(backup, path string, cause error) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%w (could not remove it to restore %s: %w)", cause, backup, err)
+	}
+	if err := common.MoveAll(backup, path); err != nil {
+		return fmt.Errorf("%w (could not restore %s: %w)", cause, backup, err)
+	}
+	return cause
 }
 
-// File performs the file checks
-func File(path string, opts Options) error {
-	info, err := os.Stat(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			if opts.Create.Kind == IfNotExists {
-				if len(opts.Create.Path) == 0 {
-					opts.Create.Path = path
-				}
-				return opts.Create.Run()
-			}
-			if opts.Exists {
-				return fmt.Errorf("file does not exist: %s", path)
-			}
-			return nil
+// validate rejects a Create that Run could never carry out, before anything is touched
+func (create *Create) validate() error {
+	switch create.Kind {
+	case IfExists, IfNotExists, EnsureValid:
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownCreateKind, create.Kind)
+	}
+	if create.Kind == EnsureValid && create.ContentReader != nil {
+		return common.Errorf(ErrInvalidOptions, "create %s cannot compare ContentReader with the existing file for EnsureValid", create.Path)
+	}
+	if create.OpenFlag == 0 && !create.Atomic {
+		return fmt.Errorf("%w: %s", ErrMissingOpenFlag, create.Path)
+	}
+	if create.Content != nil && create.ContentReader != nil {
+		return common.Errorf(ErrInvalidOptions, "create %s sets both Content and ContentReader", create.Path)
+	}
+	if create.Size < 0 {
+		return common.Errorf(ErrInvalidOptions, "create %s has a negative Size: %d", create.Path, create.Size)
+	}
+	if create.Size > TB {
+		return common.Errorf(ErrInvalidOptions, "create %s: file size too big (max 1TB): %d", create.Path, create.Size)
+	}
+	if create.Sparse && (create.Content != nil || create.ContentReader != nil) {
+		return common.Errorf(ErrInvalidOptions, "create %s: Sparse only applies to the zeros of Size, not Content or ContentReader", create.Path)
+	}
+	if create.RequireBaseDir != "" {
+		inBase, err := common.IsPathInBaseResolved(create.Path, create.RequireBaseDir)
+		if err != nil {
+			return fmt.Errorf("failed to check base directory: %w", err)
+		}
+		if !inBase {
+			return &ErrCheckBadBaseDir{Path: create.Path, BaseDir: create.RequireBaseDir}
 		}
-		return fmt.Errorf("failed to stat file %s: %w", path, err)
 	}
-
-	// Check if file is a regular file
-	if !info.Mode().IsRegular() {
-		return fmt.Errorf("not a regular file: %s", path)
+	if create.RejectSymlinkComponents {
+		found, link, err := common.HasSymlinkComponent(create.Path)
+		if err != nil {
+			return fmt.Errorf("failed to check %s for symlinks: %w", create.Path, err)
+		}
+		if found {
+			return &ErrCheckSymlinkInPath{Path: create.Path, Link: link}
+		}
 	}
-
-	// Check file creation time
-	if !opts.CreatedBefore.IsZero() {
-		createTime, err := common.GetCreationTime(path)
+	if create.RequireFreeBytes < 0 {
+		return common.Errorf(ErrInvalidOptions, "create %s has a negative RequireFreeBytes: %d", create.Path, create.RequireFreeBytes)
+	}
+	if create.RequireFreeBytes > 0 {
+		parent := filepath.Dir(create.Path)
+		available, err := common.AvailableBytes(parent)
 		if err != nil {
-			return fmt.Errorf("failed to get creation time for %s: %w", path, err)
+			return fmt.Errorf("failed to check free space: %w", err)
 		}
-		if createTime.After(opts.CreatedBefore) {
-			return fmt.Errorf("file created after specified time: %s", path)
+		if available < uint64(create.RequireFreeBytes) {
+			return common.Errorf(ErrSizeMismatch, "not enough free space to create %s: need %d bytes, %d available in %s", create.Path, create.RequireFreeBytes, available, parent)
 		}
 	}
+	return nil
+}
 
-	// Check modification time
-	if !opts.ModifiedBefore.IsZero() && info.ModTime().After(opts.ModifiedBefore) {
-		return fmt.Errorf("file modified after specified time: %s", path)
-	}
+// Run will read the Create.Kind and switch between IfExists and IfNotExists to run either file or replaceFile. With
+// DryRun set it only runs the checks of Plan and changes nothing.
+//
+// EnsureValid converges instead: a missing file is created as IfNotExists would, then the file at Path, new or not, is
+// brought in line with the Create without ever being removed or moved aside:
+//   - a path that exists but is not a regular file fails with ErrNotRegularFile and is left alone
+//   - when Content is set and the file holds anything else, it is rewritten with Content (through a temp file with
+//     Atomic, truncated in place otherwise)
+//   - otherwise, when Size is set and the file has another size, it is truncated to Size or extended with zero bytes
+//   - when its permission bits differ from those of FileMode, it is chmod'd to them, so the umask does not apply
+//
+// ContentReader cannot be compared with the file and is rejected with ErrInvalidOptions.
+//
+// With Lock set, Run holds an exclusive advisory lock on Path+".lock" (see common.FileLock) from before it looks at
+// Path until it is done, and IfNotExists leaves a file that exists by then alone. Processes racing to create the same
+// file with Lock therefore take turns, and exactly one of them writes it. The lock file is left in place.
+func (create *Create) Run() error {
+	_, err := create.RunWithBackup()
+	return err
+}
 
-	// Check file extension
-	if opts.RequireExt != "" {
-		ext := filepath.Ext(path)
-		if ext != opts.RequireExt {
-			return fmt.Errorf("incorrect file extension for %s: expected %s, got %s",
-				path, opts.RequireExt, ext)
+// RunWithBackup is Run that also returns where BackupDir received the file IfExists replaced, or "" when nothing was
+// backed up. The backup is moved (copied with Atomic) rather than removed, and moved back if creating the replacement
+// fails; cleaning it up afterwards is up to the caller.
+func (create *Create) RunWithBackup() (backup string, err error) {
+	if create.DryRun {
+		_, err := create.Plan()
+		return "", err
+	}
+	if err := create.validate(); err != nil {
+		return "", err
+	}
+	if create.Lock {
+		lock, err := common.FileLock(create.Path + ".lock")
+		if err != nil {
+			return "", err
 		}
+		defer func() {
+			if unlockErr := common.Unlock(lock); err == nil {
+				err = unlockErr
+			}
+		}()
+		if create.Kind == IfNotExists {
+			if _, statErr := os.Lstat(create.Path); statErr == nil {
+				create.Kind = NoAction
+				return "", nil
+			}
+		}
+	}
+	switch create.Kind {
+	case IfExists:
+		return create.replaceFile()
+	case EnsureValid:
+		return "", create.ensure()
 	}
+	return "", create.file()
+}
 
-	// Check file prefix
-	if opts.RequirePrefix != "" {
-		basename := filepath.Base(path)
-		if !strings.HasPrefix(basename, opts.RequirePrefix) {
-			return fmt.Errorf("incorrect file prefix for %s: expected prefix %s",
-				path, opts.RequirePrefix)
+// ensure carries out EnsureValid: a missing file is created as IfNotExists would, then converge applies the rest
+func (create *Create) ensure() error {
+	defer func() { create.Kind = NoAction }()
+	if _, err := os.Stat(create.Path); os.IsNotExist(err) {
+		create.Kind = IfNotExists
+		if err := create.file(); err != nil {
+			return err
 		}
 	}
+	_, err := create.converge(true)
+	return err
+}
 
-	// Check base directory
-	if opts.RequireBaseDir != "" {
-		isInBase, err := common.IsPathInBase(path, opts.RequireBaseDir)
-		if err != nil {
-			return fmt.Errorf("failed to check base directory for %s: %w", path, err)
+// converge returns the changes EnsureValid makes to the existing file at Path, in order, making them when apply is true
+func (create *Create) converge(apply bool) ([]string, error) {
+	info, err := os.Stat(create.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file %s: %w", create.Path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, common.Errorf(ErrNotRegularFile, "not a regular file: %s", create.Path)
+	}
+	var plan []string
+	switch {
+	case create.Content != nil:
+		same := info.Size() == int64(len(create.Content))
+		if same {
+			existing, err := os.ReadFile(create.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file %s: %w", create.Path, err)
+			}
+			same = bytes.Equal(existing, create.Content)
 		}
-		if !isInBase {
-			return &ErrCheckBadBaseDir{Path: path, BaseDir: opts.RequireBaseDir}
+		if same {
+			break
+		}
+		plan = append(plan, fmt.Sprintf("rewrite file %s with %d bytes of Content", create.Path, len(create.Content)))
+		if apply {
+			if err := create.rewrite(); err != nil {
+				return nil, err
+			}
+		}
+	case create.Size > 0 && info.Size() != create.Size:
+		plan = append(plan, fmt.Sprintf("truncate file %s to %d bytes", create.Path, create.Size))
+		if apply {
+			if err := os.Truncate(create.Path, create.Size); err != nil {
+				return nil, fmt.Errorf("could not truncate file: %w", err)
+			}
+		}
+	}
+	if info.Mode().Perm() != create.FileMode.Perm() {
+		plan = append(plan, fmt.Sprintf("chmod file %s to %s", create.Path, create.FileMode.Perm()))
+		if apply {
+			if err := os.Chmod(create.Path, create.FileMode.Perm()); err != nil {
+				return nil, fmt.Errorf("could not chmod file: %w", err)
+			}
 		}
 	}
+	return plan, nil
+}
 
-	// Check file size constraints
-	size := info.Size()
-	if opts.IsSize != 0 && size != opts.IsSize {
-		return fmt.Errorf("incorrect file size for %s: expected %d, got %d",
-			path, opts.IsSize, size)
+// rewrite replaces the contents of the existing file at Path with Content, keeping the file itself unless Atomic
+func (create *Create) rewrite() error {
+	if create.Atomic {
+		return create.atomicFile()
 	}
-	if opts.IsLessThan != 0 && size >= opts.IsLessThan {
-		return fmt.Errorf("file size %d is not less than %d: %s",
-			size, opts.IsLessThan, path)
+	f, err := os.OpenFile(create.Path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return fmt.Errorf("could not rewrite file: %w", err)
 	}
-	if opts.IsGreaterThan != 0 && size <= opts.IsGreaterThan {
-		return fmt.Errorf("file size %d is not greater than %d: %s",
-			size, opts.IsGreaterThan, path)
+	defer f.Close()
+	written, err := create.write(f)
+	if err != nil {
+		return fmt.Errorf("could not write to file: %w", err)
 	}
+	return create.checkWritten(written)
+}
 
-	// Check base name length
-	if opts.IsBaseNameLen != 0 {
-		basename := filepath.Base(path)
-		if len(basename) != opts.IsBaseNameLen {
-			return fmt.Errorf("incorrect base name length for %s: expected %d, got %d",
-				path, opts.IsBaseNameLen, len(basename))
+// Plan returns the actions Run would take, in order, without changing anything. It fails where Run would fail before
+// writing: invalid settings, too little free space for RequireFreeBytes, a missing target for IfExists, or a parent
+// directory that is missing, not a directory or not writable. For EnsureValid and an existing file it lists the
+// changes converging would make, none when the file already matches.
+func (create *Create) Plan() ([]string, error) {
+	if err := create.validate(); err != nil {
+		return nil, err
+	}
+	if create.Kind == EnsureValid {
+		if _, err := os.Stat(create.Path); !os.IsNotExist(err) {
+			return create.converge(false)
 		}
 	}
-
-	// Check file mode
-	mode := info.Mode()
-	if opts.IsFileMode != 0 && mode != opts.IsFileMode {
-		return fmt.Errorf("incorrect file mode for %s: expected %s, got %s",
-			path, opts.IsFileMode, mode)
+	parent := filepath.Dir(create.Path)
+	parentInfo, err := os.Stat(parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access parent directory %s: %w", parent, err)
+	}
+	if !parentInfo.IsDir() {
+		return nil, common.Errorf(common.ErrNotDirectory, "parent path is not a directory: %s", parent)
+	}
+	if parentInfo.Mode().Perm()&0200 == 0 {
+		return nil, common.Errorf(ErrPermissionMismatch, "parent directory not writable: %s", parent)
 	}
 
-	// Check more permissive than
-	if opts.MorePermissiveThan != 0 {
-		isMorePermissive, err := common.IsMorePermissiveThan(path, opts.MorePermissiveThan)
+	var plan []string
+	if create.Kind == IfExists {
+		info, err := os.Stat(create.Path)
 		if err != nil {
-			return fmt.Errorf("failed to check permissions for %s: %w", path, err)
+			return nil, fmt.Errorf("could not remove file: %w", err)
 		}
-		if !isMorePermissive {
-			return fmt.Errorf("file mode for %s is less permissive than required: expected at least %o, got %o",
-				path, opts.MorePermissiveThan, mode.Perm())
+		if info.IsDir() {
+			return nil, common.Errorf(ErrNotRegularFile, "could not remove file: %s is a directory", create.Path)
+		}
+		switch {
+		case create.BackupDir != "" && create.Atomic:
+			plan = append(plan, fmt.Sprintf("copy file %s into backup directory %s", create.Path, create.BackupDir))
+		case create.BackupDir != "":
+			plan = append(plan, fmt.Sprintf("move file %s into backup directory %s", create.Path, create.BackupDir))
+		case !create.Atomic:
+			plan = append(plan, fmt.Sprintf("remove file %s", create.Path))
 		}
 	}
-
-	// Check less permissive than
-	if opts.LessPermissiveThan != 0 {
-		isLessPermissive, err := common.IsLessPermissiveThan(path, opts.LessPermissiveThan)
-		if err != nil {
-			return fmt.Errorf("failed to check permissions for %s: %w", path, err)
+	if create.Atomic {
+		contents := create.describeContents()
+		if contents == "" {
+			contents = "nothing"
 		}
-		if !isLessPermissive {
-			return fmt.Errorf("file mode for %s is more permissive than allowed: expected at most %o, got %o",
-				path, opts.LessPermissiveThan, mode.Perm())
+		plan = append(plan, fmt.Sprintf("write %s to a temporary file in %s", contents, parent))
+		if create.Sync {
+			plan = append(plan, "sync the temporary file")
 		}
+		return append(plan, fmt.Sprintf("rename the temporary file to %s with mode %s", create.Path, create.FileMode)), nil
+	}
+	plan = append(plan, fmt.Sprintf("open file %s with flags %#x and mode %s", create.Path, create.OpenFlag, create.FileMode))
+	if contents := create.describeContents(); contents != "" {
+		plan = append(plan, fmt.Sprintf("write %s to %s", contents, create.Path))
 	}
+	return plan, nil
+}
 
-	// Check permissions
-	if opts.ReadOnly && mode.Perm()&0222 != 0 {
-		return &ErrCheckOpenPermissions{Path: path}
+// describeContents describes what write would put in the file for Plan, or "" when it writes nothing
+func (create *Create) describeContents() string {
+	switch {
+	case create.ContentReader != nil:
+		return "the contents of ContentReader"
+	case len(create.Content) > 0:
+		return fmt.Sprintf("%d bytes of Content", len(create.Content))
+	case create.Content == nil && create.Size > 0 && create.Sparse:
+		return fmt.Sprintf("%d sparse zero bytes", create.Size)
+	case create.Content == nil && create.Size > 0:
+		return fmt.Sprintf("%d zero bytes", create.Size)
+	}
+	return ""
+}
+
+// metadataChangeTolerance is how far the change time may trail the birth time before ForbidMetadataChangeAfterCreate
+// fails, covering the write that usually follows creating a file
+const metadataChangeTolerance = time.Second
+
+// ModePredicate evaluates a file mode and returns a non-nil error describing why the mode is not acceptable
+type ModePredicate func(mode os.FileMode) error
+
+type Options struct {
+	CreatedBefore                   time.Time        // Check file creation time
+	ModifiedBefore                  time.Time        // Check file modified time
+	CreatedAfter                    time.Time        // Check file creation time is not before
+	ModifiedAfter                   time.Time        // Check file modified time is not before
+	AccessedBefore                  time.Time        // Check file access time (atime) is not after, fails where atime is not kept (noatime)
+	AccessedAfter                   time.Time        // Check file access time (atime) is not before, fails where atime is not kept (noatime)
+	MaxAge                          time.Duration    // Check file was modified at most this long ago
+	MinAge                          time.Duration    // Check file was modified at least this long ago
+	Timeout                         time.Duration    // Abandon a check that reads the file with ErrCheckTimeout once it has run this long, 0 waits
+	IsLessThan                      int64            // Check if the size is strictly less than, so IsLessThan: 1024 rejects 1024 bytes; prefer the inclusive MaxSize
+	IsSize                          int64            // Check the file size
+	IsGreaterThan                   int64            // Check if the size is strictly greater than, so IsGreaterThan: 1024 rejects 1024 bytes; prefer the inclusive MinSize
+	IsLessThanStr                   string           // Check if the size is less than a human-readable size such as "10MB" or "1.5GB" (1024-based)
+	IsGreaterThanStr                string           // Check if the size is greater than a human-readable size such as "512KB" (1024-based)
+	MinSize                         int64            // Check if the size is at least this many bytes, so MinSize: 1024 accepts 1024 bytes, 0 is unset
+	MaxSize                         int64            // Check if the size is at most this many bytes, so MaxSize: 1024 accepts 1024 bytes, 0 is unset (see MustBeEmpty)
+	RequireExt                      string           // Check if the file is of an extension
+	RequireExts                     []string         // Check if the file is of any of these extensions (case-insensitive, includes RequireExt)
+	RequirePrefix                   string           // Check if the file name begins with a prefix
+	RequireSuffix                   string           // Check if the file name ends with a suffix (e.g. "_final"), independent of RequireExt
+	RequireNameMatch                string           // Check if the file name matches this filepath.Match pattern (e.g. "report-20??-*.csv")
+	RequireOwner                    string           // Check if the file has a specific owner
+	RequireGroup                    string           // Check if the file has a specific group
+	RequireOwnerName                string           // Check if the file owner resolves to this user name (e.g. "deploy")
+	RequireGroupName                string           // Check if the file group resolves to this group name
+	RequireOwnedByCurrentUser       bool             // Check if the file owner is the user running the process (os.Getuid()), unsupported on Windows
+	RequireOwnedByCurrentGroup      bool             // Check if the file group is the primary group of the process (os.Getgid()), unsupported on Windows
+	OwnerUIDRange                   [2]uint32        // Check if the owner uid is within [min, max] inclusive, {0, 0} is unset
+	GroupGIDRange                   [2]uint32        // Check if the group gid is within [min, max] inclusive, {0, 0} is unset
+	RequireBaseDir                  string           // Check if the file is inside a specific base directory
+	RequireBaseDirs                 []string         // Check if the file is inside any of these base directories, or RequireBaseDir
+	ForbidBaseDirs                  []string         // Check if the file is outside all of these directories (e.g. /etc, /proc), even through symlinks
+	RequireSHA256                   string           // Check if the file contents hash to this hex-encoded SHA-256 digest
+	ExpectedBlake2b                 string           // Check if the file contents hash to this hex-encoded Blake2b digest (needs -tags checkfs_blake2b)
+	Blake2bSize                     int              // Check ExpectedBlake2b with this digest size in bytes, 1 to 64 (0 means 64, i.e. Blake2b-512)
+	RequireContent                  []byte           // Check if the file contents are exactly these bytes, nil is unset
+	CompareTrimmed                  bool             // Check RequireContent ignoring trailing spaces, tabs and newlines at the end of the file
+	RequireContentRegex             string           // Check if at least one line of the file matches this regular expression
+	ForbidContentRegex              string           // Check if no line of the file matches this regular expression (e.g. secret scanning)
+	RequireValidUTF8                bool             // Check if the file is valid UTF-8, streamed in chunks
+	ForbidBOM                       bool             // Check if the file does not start with a UTF-8, UTF-16LE or UTF-16BE byte order mark
+	RequireBOM                      bool             // Check if the file starts with a UTF-8, UTF-16LE or UTF-16BE byte order mark
+	RequireTrailingNewline          bool             // Check if the last byte of the file is "\n", an empty file fails
+	ForbidTrailingNewline           bool             // Check if the last byte of the file is not "\n", an empty file passes
+	IsLineCount                     int              // Check if the file has exactly this many lines, a final line without a newline counts
+	MinLines                        int              // Check if the file has at least this many lines, 0 is unset
+	MaxLines                        int              // Check if the file has at most this many lines, 0 is unset
+	SizeSidecarExt                  string           // Check if the size matches the one recorded in path+SizeSidecarExt (e.g. ".size")
+	IsFileMode                      os.FileMode      // Check the os.FileMode value
+	MorePermissiveThan              os.FileMode      // Check if mode is at least this permissive (e.g., >= 0444)
+	LessPermissiveThan              os.FileMode      // Check if mode is less permissive than this (e.g., <= 0400)
+	IsBaseNameLen                   int              // Check if the file name length
+	MaxSymlinkComponents            int              // Check if at most this many components of the path are symlinks, 0 is unset
+	IsHardLinkCount                 int              // Check if the file has exactly this many hard links (1 for a file nothing else links to), 0 is unset
+	ExpectDevice                    uint64           // Check if the file lives on this device, see common.FileIdentity, 0 is unset
+	ExpectInode                     uint64           // Check if the file is this inode, i.e. has not been replaced, see common.FileIdentity, 0 is unset
+	RequireAccess                   AccessMode       // Check the current process may access the file this way, see common.Access
+	RequireExecutableBy             ExecutableBy     // Check the execute bit of each of these classes is set, e.g. ByOwner | ByGroup
+	CanonicalCodec                  Codec            `json:"-"` // Check if decoding then re-encoding the file with this Codec reproduces it exactly
+	RequireEncrypted                EncryptionFormat // Check if the file is wrapped in this encryption envelope (Age, PGPArmor, PGPBinary)
+	RequireContentType              string           // Check if http.DetectContentType of the first 512 bytes is this type (e.g. "image/png")
+	RequireContentTypePrefix        string           // Check if the sniffed content type starts with this prefix (e.g. "image/")
+	PermPredicate                   ModePredicate    `json:"-"` // Check the file mode with a custom policy, a non-nil error fails
+	RequireType                     FileType         // Check the path is this type of file (FIFO, Socket, CharDevice, BlockDevice, Symlink) instead of a regular one
+	NoFollowSymlinks                bool             // Run the mode and permission checks against a symlink itself instead of its target
+	Strict                          bool             // Fail if any component of the path, the file itself included, is a symlink, and Create with O_NOFOLLOW
+	RequireWrite                    bool             // Check if the file is writable
+	RequireExecutable               bool             // Check if any execute bit (0111) is set
+	ForbidSetuid                    bool             // Check the setuid bit is not set (never set on Windows)
+	ForbidSetgid                    bool             // Check the setgid bit is not set (never set on Windows)
+	RequireSticky                   bool             // Check the sticky bit is set (skipped on Windows)
+	RequireReadable                 bool             // Check the file can really be opened for reading, whatever its mode bits say
+	RequireOpenWritable             bool             // Check the file can really be opened for writing (without truncating it)
+	ReadOnly                        bool             // Check if the file is read-only: some read bit set, no write bit
+	WriteOnly                       bool             // Check if the file is write-only: some write bit set, no read bit
+	Exists                          bool             // Check if the file exists
+	RejectBrokenSymlink             bool             // Check the path is not a symlink whose target is missing
+	ForbidMetadataChangeAfterCreate bool             // Check the change time (ctime) is within a second of the birth time (btime)
+	NonEmpty                        bool             // Check if the file has at least one byte
+	MustBeEmpty                     bool             // Check if the file has zero bytes
+	Create                          Create           // Allow the user to create the file
+}
+
+// AccessMode is common.AccessMode, the accesses RequireAccess asks common.Access about
+type AccessMode = common.AccessMode
+
+// The accesses RequireAccess can ask about, combined with |
+const (
+	AccessRead    = common.AccessRead
+	AccessWrite   = common.AccessWrite
+	AccessExecute = common.AccessExecute
+)
+
+// ExecutableBy selects the classes of users whose execute bit RequireExecutableBy checks, combined with |
+type ExecutableBy uint8
+
+const (
+	ByOwner ExecutableBy = 1 << iota // ByOwner is the owner execute bit, 0100
+	ByGroup                          // ByGroup is the group execute bit, 0010
+	ByOther                          // ByOther is the other execute bit, 0001
+)
+
+// perm returns the execute bits of the classes in by
+func (by ExecutableBy) perm() os.FileMode {
+	var perm os.FileMode
+	if by&ByOwner != 0 {
+		perm |= 0100
 	}
-	if opts.WriteOnly && mode.Perm()&0444 != 0 {
-		return fmt.Errorf("file has read permissions when write-only required: %s", path)
+	if by&ByGroup != 0 {
+		perm |= 0010
 	}
-	if opts.RequireWrite && mode.Perm()&0200 == 0 {
-		return &ErrCheckNoWritePermissions{Path: path}
+	if by&ByOther != 0 {
+		perm |= 0001
 	}
+	return perm
+}
 
-	// Check owner and group
-	if opts.RequireOwner != "" || opts.RequireGroup != "" {
-		uid, gid, err := common.GetOwnerAndGroup(path)
-		if err != nil {
-			return fmt.Errorf("failed to get owner/group for %s: %w", path, err)
+// Sentinel errors usable with errors.Is to tell apart why File failed, see the common package for details
+var (
+	ErrInvalidOptions     = common.ErrInvalidOptions // ErrInvalidOptions is returned before the filesystem is touched
+	ErrDoesNotExist       = common.ErrDoesNotExist
+	ErrAlreadyExists      = common.ErrAlreadyExists
+	ErrNotRegularFile     = common.ErrNotRegularFile
+	ErrSizeMismatch       = common.ErrSizeMismatch
+	ErrTimeMismatch       = common.ErrTimeMismatch
+	ErrNameMismatch       = common.ErrNameMismatch
+	ErrBadBaseDir         = common.ErrBadBaseDir
+	ErrSymlinkMismatch    = common.ErrSymlinkMismatch
+	ErrPermissionMismatch = common.ErrPermissionMismatch
+	ErrOwnerMismatch      = common.ErrOwnerMismatch
+	ErrGroupMismatch      = common.ErrGroupMismatch
+	ErrContentMismatch    = common.ErrContentMismatch
+	ErrIdentityMismatch   = common.ErrIdentityMismatch
+	ErrUnsupportedFS      = common.ErrUnsupportedFS
+)
+
+// Validate rejects Options whose fields contradict each other or can never be satisfied, wrapping ErrInvalidOptions. It
+// only inspects opts, never the filesystem, and File and its variants call it before anything else.
+func (opts Options) Validate() error {
+	if !opts.CreatedBefore.IsZero() && opts.CreatedAfter.After(opts.CreatedBefore) {
+		return fmt.Errorf("%w: CreatedAfter %s is after CreatedBefore %s", ErrInvalidOptions,
+			opts.CreatedAfter.Format(time.RFC3339), opts.CreatedBefore.Format(time.RFC3339))
+	}
+	if !opts.ModifiedBefore.IsZero() && opts.ModifiedAfter.After(opts.ModifiedBefore) {
+		return fmt.Errorf("%w: ModifiedAfter %s is after ModifiedBefore %s", ErrInvalidOptions,
+			opts.ModifiedAfter.Format(time.RFC3339), opts.ModifiedBefore.Format(time.RFC3339))
+	}
+	if !opts.AccessedBefore.IsZero() && opts.AccessedAfter.After(opts.AccessedBefore) {
+		return fmt.Errorf("%w: AccessedAfter %s is after AccessedBefore %s", ErrInvalidOptions,
+			opts.AccessedAfter.Format(time.RFC3339), opts.AccessedBefore.Format(time.RFC3339))
+	}
+	if opts.MaxAge < 0 || opts.MinAge < 0 {
+		return fmt.Errorf("%w: MaxAge and MinAge cannot be negative", ErrInvalidOptions)
+	}
+	if opts.Timeout < 0 {
+		return fmt.Errorf("%w: Timeout cannot be negative", ErrInvalidOptions)
+	}
+	if opts.MaxAge > 0 && opts.MinAge > opts.MaxAge {
+		return fmt.Errorf("%w: MinAge %s is more than MaxAge %s", ErrInvalidOptions, opts.MinAge, opts.MaxAge)
+	}
+	if opts.IsHardLinkCount < 0 {
+		return fmt.Errorf("%w: IsHardLinkCount cannot be negative", ErrInvalidOptions)
+	}
+	if opts.NonEmpty && opts.MustBeEmpty {
+		return fmt.Errorf("%w: NonEmpty and MustBeEmpty are mutually exclusive", ErrInvalidOptions)
+	}
+	if opts.IsSize < 0 || opts.IsLessThan < 0 {
+		return fmt.Errorf("%w: IsSize and IsLessThan cannot be negative", ErrInvalidOptions)
+	}
+	if opts.MustBeEmpty && (opts.IsSize > 0 || opts.IsGreaterThan > 0) {
+		return fmt.Errorf("%w: MustBeEmpty contradicts a non-zero IsSize or IsGreaterThan", ErrInvalidOptions)
+	}
+	if opts.NonEmpty && opts.IsLessThan == 1 {
+		return fmt.Errorf("%w: NonEmpty contradicts IsLessThan 1", ErrInvalidOptions)
+	}
+	if opts.IsSize > 0 && opts.IsLessThan > 0 && opts.IsSize >= opts.IsLessThan {
+		return fmt.Errorf("%w: IsSize %d is not less than IsLessThan %d", ErrInvalidOptions, opts.IsSize, opts.IsLessThan)
+	}
+	if opts.IsSize > 0 && opts.IsGreaterThan != 0 && opts.IsSize <= opts.IsGreaterThan {
+		return fmt.Errorf("%w: IsSize %d is not greater than IsGreaterThan %d", ErrInvalidOptions, opts.IsSize, opts.IsGreaterThan)
+	}
+	if opts.IsLessThan > 0 && opts.IsGreaterThan != 0 && opts.IsLessThan-opts.IsGreaterThan <= 1 {
+		return fmt.Errorf("%w: no size is greater than %d and less than %d", ErrInvalidOptions, opts.IsGreaterThan, opts.IsLessThan)
+	}
+	if opts.MinSize < 0 || opts.MaxSize < 0 {
+		return fmt.Errorf("%w: MinSize and MaxSize cannot be negative", ErrInvalidOptions)
+	}
+	if opts.MaxSize > 0 && opts.MinSize > opts.MaxSize {
+		return fmt.Errorf("%w: MinSize %d is greater than MaxSize %d", ErrInvalidOptions, opts.MinSize, opts.MaxSize)
+	}
+	if opts.MustBeEmpty && opts.MinSize > 0 {
+		return fmt.Errorf("%w: MustBeEmpty contradicts a non-zero MinSize", ErrInvalidOptions)
+	}
+	if opts.IsSize > 0 && (opts.IsSize < opts.MinSize || opts.MaxSize > 0 && opts.IsSize > opts.MaxSize) {
+		return fmt.Errorf("%w: IsSize %d is outside MinSize %d and MaxSize %d", ErrInvalidOptions, opts.IsSize, opts.MinSize, opts.MaxSize)
+	}
+	if opts.IsLineCount < 0 || opts.MinLines < 0 || opts.MaxLines < 0 {
+		return fmt.Errorf("%w: IsLineCount, MinLines and MaxLines cannot be negative", ErrInvalidOptions)
+	}
+	if opts.MaxLines > 0 && opts.MinLines > opts.MaxLines {
+		return fmt.Errorf("%w: MinLines %d is greater than MaxLines %d", ErrInvalidOptions, opts.MinLines, opts.MaxLines)
+	}
+	if opts.IsLineCount > 0 && (opts.IsLineCount < opts.MinLines || (opts.MaxLines > 0 && opts.IsLineCount > opts.MaxLines)) {
+		return fmt.Errorf("%w: IsLineCount %d is outside MinLines %d and MaxLines %d", ErrInvalidOptions, opts.IsLineCount, opts.MinLines, opts.MaxLines)
+	}
+	if err := common.ValidatePermissions(opts.ReadOnly, opts.RequireWrite, opts.MorePermissiveThan, opts.LessPermissiveThan); err != nil {
+		return err
+	}
+	if opts.ReadOnly && opts.WriteOnly {
+		return fmt.Errorf("%w: ReadOnly and WriteOnly are mutually exclusive", ErrInvalidOptions)
+	}
+	if opts.WriteOnly && opts.LessPermissiveThan.Perm() != 0 && opts.LessPermissiveThan.Perm()&0222 == 0 {
+		return fmt.Errorf("%w: WriteOnly needs a write bit LessPermissiveThan %#o forbids", ErrInvalidOptions, opts.LessPermissiveThan.Perm())
+	}
+	if opts.WriteOnly && opts.MorePermissiveThan.Perm()&0444 != 0 {
+		return fmt.Errorf("%w: WriteOnly forbids the read bits MorePermissiveThan %#o requires", ErrInvalidOptions, opts.MorePermissiveThan.Perm())
+	}
+	if opts.Blake2bSize < 0 || opts.Blake2bSize > 64 {
+		return fmt.Errorf("%w: Blake2bSize must be between 1 and 64, got %d", ErrInvalidOptions, opts.Blake2bSize)
+	}
+	if opts.ForbidBOM && opts.RequireBOM {
+		return fmt.Errorf("%w: ForbidBOM and RequireBOM are mutually exclusive", ErrInvalidOptions)
+	}
+	if opts.RequireBOM && opts.MustBeEmpty {
+		return fmt.Errorf("%w: an empty file never has the byte order mark RequireBOM asks for", ErrInvalidOptions)
+	}
+	if opts.RequireTrailingNewline && opts.ForbidTrailingNewline {
+		return fmt.Errorf("%w: RequireTrailingNewline and ForbidTrailingNewline are mutually exclusive", ErrInvalidOptions)
+	}
+	if opts.RequireTrailingNewline && opts.MustBeEmpty {
+		return fmt.Errorf("%w: an empty file never has the trailing newline RequireTrailingNewline asks for", ErrInvalidOptions)
+	}
+	if opts.CompareTrimmed && opts.RequireContent == nil {
+		return fmt.Errorf("%w: CompareTrimmed requires RequireContent", ErrInvalidOptions)
+	}
+	if opts.RequireExecutableBy&^(ByOwner|ByGroup|ByOther) != 0 {
+		return fmt.Errorf("%w: unknown RequireExecutableBy %d", ErrInvalidOptions, opts.RequireExecutableBy)
+	}
+	if less := opts.LessPermissiveThan.Perm(); less != 0 {
+		if opts.RequireExecutable && less&0111 == 0 {
+			return fmt.Errorf("%w: RequireExecutable needs an execute bit LessPermissiveThan %#o forbids", ErrInvalidOptions, less)
+		}
+		if need := opts.RequireExecutableBy.perm(); need&^less != 0 {
+			return fmt.Errorf("%w: RequireExecutableBy needs the execute bits %#o LessPermissiveThan %#o forbids", ErrInvalidOptions, need, less)
+		}
+	}
+	if opts.RequireAccess&^(AccessRead|AccessWrite|AccessExecute) != 0 {
+		return fmt.Errorf("%w: unknown RequireAccess %s", ErrInvalidOptions, opts.RequireAccess)
+	}
+	if opts.RequireEncrypted < NoEncryption || opts.RequireEncrypted > PGPBinary {
+		return fmt.Errorf("%w: unknown RequireEncrypted format %s", ErrInvalidOptions, opts.RequireEncrypted)
+	}
+	if _, err := regexp.Compile(opts.RequireContentRegex); err != nil {
+		return fmt.Errorf("%w: RequireContentRegex: %w", ErrInvalidOptions, err)
+	}
+	if _, err := regexp.Compile(opts.ForbidContentRegex); err != nil {
+		return fmt.Errorf("%w: ForbidContentRegex: %w", ErrInvalidOptions, err)
+	}
+	if opts.IsLessThanStr != "" {
+		if _, err := common.ParseSize(opts.IsLessThanStr); err != nil {
+			return fmt.Errorf("%w: IsLessThanStr: %w", ErrInvalidOptions, err)
+		}
+	}
+	if opts.IsGreaterThanStr != "" {
+		if _, err := common.ParseSize(opts.IsGreaterThanStr); err != nil {
+			return fmt.Errorf("%w: IsGreaterThanStr: %w", ErrInvalidOptions, err)
+		}
+	}
+	if opts.OwnerUIDRange[0] > opts.OwnerUIDRange[1] {
+		return fmt.Errorf("%w: OwnerUIDRange min %d is greater than max %d", ErrInvalidOptions, opts.OwnerUIDRange[0], opts.OwnerUIDRange[1])
+	}
+	if opts.GroupGIDRange[0] > opts.GroupGIDRange[1] {
+		return fmt.Errorf("%w: GroupGIDRange min %d is greater than max %d", ErrInvalidOptions, opts.GroupGIDRange[0], opts.GroupGIDRange[1])
+	}
+	for _, base := range opts.RequireBaseDirs {
+		if base == "" {
+			return fmt.Errorf("%w: RequireBaseDirs cannot contain an empty path", ErrInvalidOptions)
 		}
-		if opts.RequireOwner != "" && uid != opts.RequireOwner {
-			return &ErrCheckBadOwner{Path: path, Expected: opts.RequireOwner, Actual: uid}
+	}
+	for _, base := range opts.ForbidBaseDirs {
+		if base == "" {
+			return fmt.Errorf("%w: ForbidBaseDirs cannot contain an empty path", ErrInvalidOptions)
 		}
-		if opts.RequireGroup != "" && gid != opts.RequireGroup {
-			return &ErrCheckBadGroup{Path: path, Expected: opts.RequireGroup, Actual: gid}
+	}
+	if opts.RequireNameMatch != "" {
+		if _, err := filepath.Match(opts.RequireNameMatch, ""); err != nil {
+			return fmt.Errorf("%w: RequireNameMatch %q: %w", ErrInvalidOptions, opts.RequireNameMatch, err)
 		}
 	}
+	if opts.Strict && opts.RequireType == Symlink {
+		return fmt.Errorf("%w: Strict refuses every symlink, so RequireType cannot be Symlink", ErrInvalidOptions)
+	}
+	return opts.validateType()
+}
+
+// File performs the file checks
+func File(path string, opts Options) error {
+	return FileContext(context.Background(), path, opts)
+}
+
+// FileContext performs the file checks, returning ctx.Err() as soon as ctx is done. The context is consulted before
+// the file is stat'd, between every check and while file contents are being read; a single stat that blocks (e.g. on a
+// hung NFS mount) cannot be interrupted, but nothing further runs once it returns.
+func FileContext(ctx context.Context, path string, opts Options) error {
+	_, err := StatContext(ctx, path, opts)
+	return err
+}
 
+// FileWithInfo performs the file checks against info, the os.FileInfo of path the caller already has (from os.Stat, or
+// fs.DirEntry.Info inside filepath.WalkDir), instead of stat'ing path again. Mode, size, time, name, hard link,
+// identity, owner and group ID checks read info alone. Options that need more still touch the filesystem: the content
+// checks and SizeSidecarExt read files, MaxSymlinkComponents, NoFollowSymlinks and Strict lstat, AccessedBefore and
+// AccessedAfter statfs path to rule out noatime, RequireReadable, RequireOpenWritable and RequireAccess open or
+// access(2) path, and RequireOwnerName and RequireGroupName look the name up. info is taken as is, so a symlink's own
+// info from a DirEntry fails with ErrNotRegularFile.
+func FileWithInfo(path string, info os.FileInfo, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if info == nil {
+		return fmt.Errorf("%w: FileWithInfo requires the os.FileInfo of %s", ErrInvalidOptions, path)
+	}
+	if opts.Strict {
+		if err := strictPath(path); err != nil {
+			return err
+		}
+	}
+	if _, failures := runInfo(context.Background(), nil, path, info, opts, false); len(failures) > 0 {
+		return failures[0].Err
+	}
 	return nil
 }
 
-type ErrCheckOpenPermissions struct{ Path string }
-type ErrCheckNoWritePermissions struct{ Path string }
-type ErrCheckBadOwner struct{ Path, Expected, Actual string }
-type ErrCheckBadGroup struct{ Path, Expected, Actual string }
-type ErrCheckBadBaseDir struct{ Path, BaseDir string }
+// Stat performs the file checks like File and, when they pass, returns the os.FileInfo they ran against, saving the
+// caller another stat. The info is nil when there was no file to check: it is missing and allowed to be, or
+// Create has just made it.
+func Stat(path string, opts Options) (os.FileInfo, error) {
+	return StatContext(context.Background(), path, opts)
+}
 
-func (e *ErrCheckOpenPermissions) Error() string {
-	return fmt.Sprintf("permissions too open: %s", e.Path)
+// StatContext is Stat, returning ctx.Err() as soon as ctx is done
+func StatContext(ctx context.Context, path string, opts Options) (os.FileInfo, error) {
+	info, failures := runStat(ctx, nil, path, opts, false)
+	if len(failures) > 0 {
+		return nil, failures[0].Err
+	}
+	return info, nil
 }
 
-func (e *ErrCheckNoWritePermissions) Error() string {
-	return fmt.Sprintf("no write permission: %s", e.Path)
+// FileAll performs every file check and returns each failure in the order File would have encountered them, or nil
+// when every check passes. Invalid Options and a failed os.Stat still stop the run immediately, since no other check
+// can run without them.
+func FileAll(path string, opts Options) []error {
+	return common.Errors(run(context.Background(), nil, path, opts, true))
 }
 
-func (e *ErrCheckBadOwner) Error() string {
-	return fmt.Sprintf("bad owner for %s: expected %s, got %s", e.Path, e.Expected, e.Actual)
+// FileResult performs every file check like FileAll and returns the failures as a common.Result with a stable Code and the
+// failing Options field for each violation
+func FileResult(path string, opts Options) common.Result {
+	return common.NewResult(path, run(context.Background(), nil, path, opts, true))
 }
 
-func (e *ErrCheckBadGroup) Error() string {
-	return fmt.Sprintf("bad group for %s: expected %s, got %s", e.Path, e.Expected, e.Actual)
+// statFS is the only place run stats a path; every check works from the resulting os.FileInfo so a run costs a single
+// stat however many Options are set, served from the common.StatCache carried by the context if any. Tests replace it
+// to count calls.
+var statFS = common.StatContext
+
+// state is shared by every check run against a single path
+type state struct {
+	ctx  context.Context
+	fsys fs.FS // fsys is nil when checking the OS filesystem
+	path string
+	info os.FileInfo
+	opts *Options
+
+	owner *common.OwnerCache // owner is shared by every owner/group check so the lookups run once
+
+	linkInfo os.FileInfo // linkInfo is the os.Lstat result permInfo memoizes for NoFollowSymlinks
+	sniffed  string      // sniffed is the content type contentType memoizes
+	lines    *int        // lines is the line count lineCount memoizes
 }
 
-func (e *ErrCheckBadBaseDir) Error() string {
-	return fmt.Sprintf("file %s is not in required base directory %s", e.Path, e.BaseDir)
+// permInfo returns the os.FileInfo the permission checks inspect: info, or the symlink itself when NoFollowSymlinks is
+// set, lstat'd once however many permission checks run
+func (s *state) permInfo() (os.FileInfo, error) {
+	if !s.opts.NoFollowSymlinks {
+		return s.info, nil
+	}
+	if s.linkInfo == nil {
+		info, err := os.Lstat(s.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lstat %s: %w", s.path, err)
+		}
+		s.linkInfo = info
+	}
+	return s.linkInfo, nil
+}
+
+// accessTime returns the access time of the file, failing with common.ErrAccessTimeUnsupported when info carries none
+// or the filesystem is mounted noatime, since a time that never moves would make AccessedBefore pass regardless
+func (s *state) accessTime() (time.Time, error) {
+	disabled, err := common.AccessTimeDisabled(s.path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to check access times for %s: %w", s.path, err)
+	}
+	if disabled {
+		return time.Time{}, fmt.Errorf("%w on the filesystem holding %s, it is mounted noatime", common.ErrAccessTimeUnsupported, s.path)
+	}
+	accessTime, err := common.GetAccessTimeInfo(s.info)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get access time for %s: %w", s.path, err)
+	}
+	return accessTime, nil
+}
+
+// check is a single validation step; enabled reports whether the Options ask for it
+type check struct {
+	name    string
+	enabled func(opts *Options) bool
+	run     func(s *state) error
+}
+
+// run stats path (in fsys, or the OS filesystem when fsys is nil) and runs every enabled check in order, stopping at
+// the first failure unless all is true
+func run(ctx context.Context, fsys fs.FS, path string, opts Options, all bool) []common.Failure {
+	_, failures := runStat(ctx, fsys, path, opts, all)
+	return failures
+}
+
+// runStat is run, also returning the os.FileInfo the checks ran against; it is nil when no checks ran
+func runStat(ctx context.Context, fsys fs.FS, path string, opts Options, all bool) (os.FileInfo, []common.Failure) {
+	if err := opts.Validate(); err != nil {
+		return nil, []common.Failure{{Err: err}}
+	}
+	if fsys != nil {
+		if err := opts.validateFS(); err != nil {
+			return nil, []common.Failure{{Err: err}}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, []common.Failure{{Err: err}}
+	}
+
+	stat := statFor(opts)
+	if opts.Strict {
+		if err := strictPath(path); err != nil {
+			return nil, []common.Failure{{Field: "Strict", Err: err}}
+		}
+		if opts.Create.OpenFlag != 0 {
+			opts.Create.OpenFlag |= common.ONoFollow
+		}
+	}
+	info, err := stat(ctx, fsys, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			if opts.RejectBrokenSymlink {
+				if err := brokenSymlink(path); err != nil {
+					return nil, []common.Failure{{Field: "RejectBrokenSymlink", Err: err}}
+				}
+			}
+			if opts.Create.Kind == IfNotExists || opts.Create.Kind == EnsureValid {
+				if len(opts.Create.Path) == 0 {
+					opts.Create.Path = path
+				}
+				if err := opts.Create.Run(); err != nil {
+					return nil, []common.Failure{{Err: err}}
+				}
+				return nil, nil
+			}
+			if opts.Exists {
+				return nil, []common.Failure{{Err: common.Errorf(ErrDoesNotExist, "file does not exist: %s", path)}}
+			}
+			return nil, nil
+		}
+		return nil, []common.Failure{{Err: fmt.Errorf("failed to stat file %s: %w", path, err)}}
+	}
+	if opts.Create.Kind == EnsureValid {
+		if len(opts.Create.Path) == 0 {
+			opts.Create.Path = path
+		}
+		if err := opts.Create.Run(); err != nil {
+			return nil, []common.Failure{{Err: err}}
+		}
+		if info, err = stat(ctx, fsys, path); err != nil {
+			return nil, []common.Failure{{Err: fmt.Errorf("failed to stat file %s: %w", path, err)}}
+		}
+	}
+
+	if opts.RequireType == Symlink && opts.RejectBrokenSymlink && info.Mode()&fs.ModeSymlink != 0 {
+		if err := brokenSymlink(path); err != nil {
+			return nil, []common.Failure{{Field: "RejectBrokenSymlink", Err: err}}
+		}
+	}
+
+	return runInfo(ctx, fsys, path, info, opts, all)
+}
+
+// statFor returns how runStat and Inspect stat path for opts: lstat for RequireType Symlink and Strict, which must see
+// a symlink rather than its target, and statFS otherwise
+func statFor(opts Options) func(context.Context, fs.FS, string) (fs.FileInfo, error) {
+	if opts.RequireType == Symlink || opts.Strict {
+		return lstat
+	}
+	return statFS
+}
+
+// lstat stats the symlink at path itself for RequireType Symlink and Strict, which validateFS keeps away from an fs.FS
+func lstat(_ context.Context, _ fs.FS, path string) (fs.FileInfo, error) {
+	return os.Lstat(path)
+}
+
+// strictPath fails with *ErrCheckSymlinkInPath when any component of path is a symlink, so that Strict never resolves
+// one on the way to the file; components that do not exist yet cannot be links and are not an error
+func strictPath(path string) error {
+	link, err := common.FirstSymlink(path)
+	if err != nil {
+		return fmt.Errorf("failed to check %s for symlinks: %w", path, err)
+	}
+	if link != "" {
+		return &ErrCheckSymlinkInPath{Path: path, Link: link}
+	}
+	return nil
+}
+
+// runInfo runs every enabled check against info, the file at path as statFS returned it, in order, stopping at the
+// first failure unless all is true
+func runInfo(ctx context.Context, fsys fs.FS, path string, info os.FileInfo, opts Options, all bool) (os.FileInfo, []common.Failure) {
+	if failure := checkType(path, info, opts); failure != nil {
+		return nil, []common.Failure{*failure}
+	}
+
+	s := &state{
+		ctx:   ctx,
+		fsys:  fsys,
+		path:  path,
+		info:  info,
+		opts:  &opts,
+		owner: &common.OwnerCache{Path: path, Info: info},
+	}
+	var failures []common.Failure
+	for _, c := range checks {
+		if !c.enabled(s.opts) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return info, append(failures, common.Failure{Err: err})
+		}
+		if err := runCheck(s, c); err != nil {
+			failures = append(failures, common.Failure{Field: c.name, Err: err})
+			if !all {
+				break
+			}
+		}
+	}
+	return info, failures
+}
+
+// checkType fails unless info is a regular file, or the type RequireType asks for
+func checkType(path string, info os.FileInfo, opts Options) *common.Failure {
+	if opts.RequireType != Regular {
+		if !opts.RequireType.matches(info.Mode()) {
+			return &common.Failure{Field: "RequireType", Err: &ErrCheckWrongType{Path: path, Want: opts.RequireType, Got: info.Mode().Type()}}
+		}
+	} else if !info.Mode().IsRegular() {
+		return &common.Failure{Err: common.Errorf(ErrNotRegularFile, "not a regular file: %s", path)}
+	}
+	return nil
+}
+
+// runCheck runs c against s. With Timeout set, a check in contentChecks runs in its own goroutine against a copy of s
+// instead, and fails with *ErrCheckTimeout once Timeout passes. The abandoned check's context is canceled, so a read
+// that returns stops there, but one stuck in the kernel keeps its goroutine until it comes back.
+func runCheck(s *state, c check) error {
+	if s.opts.Timeout <= 0 || !contentChecks[c.name] {
+		return c.run(s)
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	timed := *s
+	timed.ctx = ctx
+	done := make(chan error, 1)
+	go func() { done <- c.run(&timed) }()
+
+	timer := time.NewTimer(s.opts.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		// keep what the check memoized, such as the line count, for the checks after it
+		timed.ctx = s.ctx
+		*s = timed
+		return err
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	case <-timer.C:
+		return &ErrCheckTimeout{Path: s.path, Op: c.name}
+	}
+}
+
+// brokenSymlink returns ErrCheckBrokenSymlink when path is a symlink whose target does not resolve
+func brokenSymlink(path string) error {
+	broken, err := common.IsBrokenSymlink(path)
+	if err != nil || !broken {
+		return err
+	}
+	target, err := common.SymlinkTarget(path)
+	if err != nil {
+		return err
+	}
+	return &ErrCheckBrokenSymlink{Path: path, Target: target}
+}
+
+// checks run in order against a path that exists and is a regular file
+var checks = []check{
+	// Check file creation time
+	{"CreatedBefore", func(o *Options) bool { return !o.CreatedBefore.IsZero() }, func(s *state) error {
+		createTime, err := common.GetCreationTimeInfo(s.info)
+		if err != nil {
+			return fmt.Errorf("failed to get creation time for %s: %w", s.path, err)
+		}
+		if createTime.After(s.opts.CreatedBefore) {
+			return common.Errorf(ErrTimeMismatch, "file created after specified time: %s", s.path)
+		}
+		return nil
+	}},
+	{"CreatedAfter", func(o *Options) bool { return !o.CreatedAfter.IsZero() }, func(s *state) error {
+		createTime, err := common.GetCreationTimeInfo(s.info)
+		if err != nil {
+			return fmt.Errorf("failed to get creation time for %s: %w", s.path, err)
+		}
+		if createTime.Before(s.opts.CreatedAfter) {
+			return common.Errorf(ErrTimeMismatch, "file %s created at %s, before %s",
+				s.path, createTime.Format(time.RFC3339), s.opts.CreatedAfter.Format(time.RFC3339))
+		}
+		return nil
+	}},
+
+	// Check modification time
+	{"ModifiedBefore", func(o *Options) bool { return !o.ModifiedBefore.IsZero() }, func(s *state) error {
+		if s.info.ModTime().After(s.opts.ModifiedBefore) {
+			return common.Errorf(ErrTimeMismatch, "file modified after specified time: %s", s.path)
+		}
+		return nil
+	}},
+	{"ModifiedAfter", func(o *Options) bool { return !o.ModifiedAfter.IsZero() }, func(s *state) error {
+		if modTime := s.info.ModTime(); modTime.Before(s.opts.ModifiedAfter) {
+			return common.Errorf(ErrTimeMismatch, "file %s modified at %s, before %s",
+				s.path, modTime.Format(time.RFC3339), s.opts.ModifiedAfter.Format(time.RFC3339))
+		}
+		return nil
+	}},
+
+	// Check access time
+	{"AccessedBefore", func(o *Options) bool { return !o.AccessedBefore.IsZero() }, func(s *state) error {
+		accessTime, err := s.accessTime()
+		if err != nil {
+			return err
+		}
+		if accessTime.After(s.opts.AccessedBefore) {
+			return common.Errorf(ErrTimeMismatch, "file %s accessed at %s, after %s",
+				s.path, accessTime.Format(time.RFC3339), s.opts.AccessedBefore.Format(time.RFC3339))
+		}
+		return nil
+	}},
+	{"AccessedAfter", func(o *Options) bool { return !o.AccessedAfter.IsZero() }, func(s *state) error {
+		accessTime, err := s.accessTime()
+		if err != nil {
+			return err
+		}
+		if accessTime.Before(s.opts.AccessedAfter) {
+			return common.Errorf(ErrTimeMismatch, "file %s accessed at %s, before %s",
+				s.path, accessTime.Format(time.RFC3339), s.opts.AccessedAfter.Format(time.RFC3339))
+		}
+		return nil
+	}},
+
+	// Check modification age, relative to now
+	{"MaxAge", func(o *Options) bool { return o.MaxAge > 0 }, func(s *state) error {
+		if age := time.Since(s.info.ModTime()); age > s.opts.MaxAge {
+			return common.Errorf(ErrTimeMismatch, "file %s last modified %s ago, more than %s", s.path, age.Round(time.Second), s.opts.MaxAge)
+		}
+		return nil
+	}},
+	{"MinAge", func(o *Options) bool { return o.MinAge > 0 }, func(s *state) error {
+		if age := time.Since(s.info.ModTime()); age < s.opts.MinAge {
+			return common.Errorf(ErrTimeMismatch, "file %s last modified %s ago, less than %s", s.path, age.Round(time.Second), s.opts.MinAge)
+		}
+		return nil
+	}},
+
+	// Check metadata was not changed after creation (chmod, chown, later writes)
+	{"ForbidMetadataChangeAfterCreate", func(o *Options) bool { return o.ForbidMetadataChangeAfterCreate }, func(s *state) error {
+		birth, change, err := common.GetBirthAndChangeTimeInfo(s.info)
+		if err != nil {
+			return fmt.Errorf("failed to get birth and change time for %s: %w", s.path, err)
+		}
+		if change.Sub(birth) > metadataChangeTolerance {
+			return &ErrCheckMetadataChanged{Path: s.path, Birth: birth, Change: change}
+		}
+		return nil
+	}},
+
+	// Check file extension
+	{"RequireExt", func(o *Options) bool { return o.RequireExt != "" && len(o.RequireExts) == 0 }, func(s *state) error {
+		ext := filepath.Ext(s.path)
+		if ext != s.opts.RequireExt {
+			return common.Errorf(ErrNameMismatch, "incorrect file extension for %s: expected %s, got %s",
+				s.path, s.opts.RequireExt, ext)
+		}
+		return nil
+	}},
+
+	// Check file extension against the accepted set, ignoring case
+	{"RequireExts", func(o *Options) bool { return len(o.RequireExts) > 0 }, func(s *state) error {
+		accepted := s.opts.RequireExts
+		if s.opts.RequireExt != "" {
+			accepted = append([]string{s.opts.RequireExt}, accepted...)
+		}
+		ext := filepath.Ext(s.path)
+		for _, want := range accepted {
+			if strings.EqualFold(ext, want) {
+				return nil
+			}
+		}
+		return common.Errorf(ErrNameMismatch, "incorrect file extension for %s: expected one of %s, got %s",
+			s.path, strings.Join(accepted, ", "), ext)
+	}},
+
+	// Check file prefix and suffix
+	{"RequirePrefix", func(o *Options) bool { return o.RequirePrefix != "" }, func(s *state) error {
+		basename := filepath.Base(s.path)
+		if !strings.HasPrefix(basename, s.opts.RequirePrefix) {
+			return common.Errorf(ErrNameMismatch, "incorrect file prefix for %s: expected prefix %s",
+				s.path, s.opts.RequirePrefix)
+		}
+		return nil
+	}},
+	{"RequireSuffix", func(o *Options) bool { return o.RequireSuffix != "" }, func(s *state) error {
+		basename := filepath.Base(s.path)
+		if !strings.HasSuffix(basename, s.opts.RequireSuffix) {
+			return common.Errorf(ErrNameMismatch, "incorrect file suffix for %s: expected suffix %s",
+				s.path, s.opts.RequireSuffix)
+		}
+		return nil
+	}},
+	{"RequireNameMatch", func(o *Options) bool { return o.RequireNameMatch != "" }, func(s *state) error {
+		basename := filepath.Base(s.path)
+		if matched, _ := filepath.Match(s.opts.RequireNameMatch, basename); !matched { // the pattern was checked by Validate
+			return common.Errorf(ErrNameMismatch, "file name %s does not match pattern %s: %s",
+				basename, s.opts.RequireNameMatch, s.path)
+		}
+		return nil
+	}},
+
+	// Check base directory
+	{"RequireBaseDir", func(o *Options) bool { return o.RequireBaseDir != "" && len(o.RequireBaseDirs) == 0 }, func(s *state) error {
+		isInBase, err := common.IsPathInBase(s.path, s.opts.RequireBaseDir)
+		if err != nil {
+			return fmt.Errorf("failed to check base directory for %s: %w", s.path, err)
+		}
+		if !isInBase {
+			return &ErrCheckBadBaseDir{Path: s.path, BaseDir: s.opts.RequireBaseDir}
+		}
+		return nil
+	}},
+	// RequireBaseDirs takes over RequireBaseDir when both are set, passing a path inside either
+	{"RequireBaseDirs", func(o *Options) bool { return len(o.RequireBaseDirs) > 0 }, func(s *state) error {
+		bases := s.opts.RequireBaseDirs
+		if s.opts.RequireBaseDir != "" {
+			bases = append([]string{s.opts.RequireBaseDir}, bases...)
+		}
+		for _, base := range bases {
+			isInBase, err := common.IsPathInBase(s.path, base)
+			if err != nil {
+				return fmt.Errorf("failed to check base directory for %s: %w", s.path, err)
+			}
+			if isInBase {
+				return nil
+			}
+		}
+		return &ErrCheckBadBaseDirs{Path: s.path, BaseDirs: bases}
+	}},
+
+	// Check the file is not inside a denied directory, as written or once symlinks are resolved
+	{"ForbidBaseDirs", func(o *Options) bool { return len(o.ForbidBaseDirs) > 0 }, func(s *state) error {
+		for _, base := range s.opts.ForbidBaseDirs {
+			inBase, err := common.ResolvesIntoBase(s.path, base)
+			if err != nil {
+				return fmt.Errorf("failed to check forbidden base directory for %s: %w", s.path, err)
+			}
+			if inBase {
+				return &ErrCheckForbiddenBaseDir{Path: s.path, BaseDir: base}
+			}
+		}
+		return nil
+	}},
+
+	// Check how many path components are symlinks
+	{"MaxSymlinkComponents", func(o *Options) bool { return o.MaxSymlinkComponents > 0 }, func(s *state) error {
+		count, err := common.SymlinkComponents(s.path)
+		if err != nil {
+			return fmt.Errorf("failed to count symlinks in %s: %w", s.path, err)
+		}
+		if count > s.opts.MaxSymlinkComponents {
+			return &ErrCheckSymlinkComponents{Path: s.path, Max: s.opts.MaxSymlinkComponents, Actual: count}
+		}
+		return nil
+	}},
+	{"IsHardLinkCount", func(o *Options) bool { return o.IsHardLinkCount > 0 }, func(s *state) error {
+		count, err := common.LinkCountInfo(s.info)
+		if err != nil {
+			return fmt.Errorf("failed to count hard links to %s: %w", s.path, err)
+		}
+		if count != uint64(s.opts.IsHardLinkCount) {
+			return &ErrCheckHardLinkCount{Path: s.path, Expected: uint64(s.opts.IsHardLinkCount), Actual: count}
+		}
+		return nil
+	}},
+
+	// Check the file is still the one identified earlier
+	{"ExpectDevice", func(o *Options) bool { return o.ExpectDevice != 0 }, func(s *state) error {
+		dev, _, err := common.FileIdentityInfo(s.info)
+		if err != nil {
+			return fmt.Errorf("failed to get the identity of %s: %w", s.path, err)
+		}
+		if dev != s.opts.ExpectDevice {
+			return &ErrCheckIdentityChanged{Path: s.path, Field: "device", Expected: s.opts.ExpectDevice, Actual: dev}
+		}
+		return nil
+	}},
+	{"ExpectInode", func(o *Options) bool { return o.ExpectInode != 0 }, func(s *state) error {
+		_, ino, err := common.FileIdentityInfo(s.info)
+		if err != nil {
+			return fmt.Errorf("failed to get the identity of %s: %w", s.path, err)
+		}
+		if ino != s.opts.ExpectInode {
+			return &ErrCheckIdentityChanged{Path: s.path, Field: "inode", Expected: s.opts.ExpectInode, Actual: ino}
+		}
+		return nil
+	}},
+
+	// Check file size constraints
+	{"NonEmpty", func(o *Options) bool { return o.NonEmpty }, func(s *state) error {
+		if s.info.Size() == 0 {
+			return common.Errorf(ErrSizeMismatch, "file is empty: %s", s.path)
+		}
+		return nil
+	}},
+	{"MustBeEmpty", func(o *Options) bool { return o.MustBeEmpty }, func(s *state) error {
+		if size := s.info.Size(); size != 0 {
+			return common.Errorf(ErrSizeMismatch, "file is not empty, has %d bytes: %s", size, s.path)
+		}
+		return nil
+	}},
+	{"IsSize", func(o *Options) bool { return o.IsSize != 0 }, func(s *state) error {
+		if size := s.info.Size(); size != s.opts.IsSize {
+			return common.Errorf(ErrSizeMismatch, "incorrect file size for %s: expected %d, got %d",
+				s.path, s.opts.IsSize, size)
+		}
+		return nil
+	}},
+	{"IsLessThan", func(o *Options) bool { return o.IsLessThan != 0 }, func(s *state) error {
+		if size := s.info.Size(); size >= s.opts.IsLessThan {
+			return common.Errorf(ErrSizeMismatch, "file size %d is not less than %d: %s",
+				size, s.opts.IsLessThan, s.path)
+		}
+		return nil
+	}},
+	{"IsGreaterThan", func(o *Options) bool { return o.IsGreaterThan != 0 }, func(s *state) error {
+		if size := s.info.Size(); size <= s.opts.IsGreaterThan {
+			return common.Errorf(ErrSizeMismatch, "file size %d is not greater than %d: %s",
+				size, s.opts.IsGreaterThan, s.path)
+		}
+		return nil
+	}},
+	{"IsLessThanStr", func(o *Options) bool { return o.IsLessThanStr != "" }, func(s *state) error {
+		limit, _ := common.ParseSize(s.opts.IsLessThanStr) // already checked by Validate
+		if size := s.info.Size(); size >= limit {
+			return common.Errorf(ErrSizeMismatch, "file size %d is not less than %s (%d): %s",
+				size, s.opts.IsLessThanStr, limit, s.path)
+		}
+		return nil
+	}},
+	{"IsGreaterThanStr", func(o *Options) bool { return o.IsGreaterThanStr != "" }, func(s *state) error {
+		limit, _ := common.ParseSize(s.opts.IsGreaterThanStr) // already checked by Validate
+		if size := s.info.Size(); size <= limit {
+			return common.Errorf(ErrSizeMismatch, "file size %d is not greater than %s (%d): %s",
+				size, s.opts.IsGreaterThanStr, limit, s.path)
+		}
+		return nil
+	}},
+	{"MinSize", func(o *Options) bool { return o.MinSize != 0 }, func(s *state) error {
+		if size := s.info.Size(); size < s.opts.MinSize {
+			return common.Errorf(ErrSizeMismatch, "file size %d is less than the minimum of %d: %s",
+				size, s.opts.MinSize, s.path)
+		}
+		return nil
+	}},
+	{"MaxSize", func(o *Options) bool { return o.MaxSize != 0 }, func(s *state) error {
+		if size := s.info.Size(); size > s.opts.MaxSize {
+			return common.Errorf(ErrSizeMismatch, "file size %d is more than the maximum of %d: %s",
+				size, s.opts.MaxSize, s.path)
+		}
+		return nil
+	}},
+
+	// Check the size recorded in the sidecar file
+	{"SizeSidecarExt", func(o *Options) bool { return o.SizeSidecarExt != "" }, func(s *state) error {
+		sidecar := s.path + s.opts.SizeSidecarExt
+		data, err := common.ReadFileFS(s.fsys, sidecar)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return &ErrCheckMissingSidecar{Path: s.path, Sidecar: sidecar}
+			}
+			return fmt.Errorf("failed to read size sidecar %s: %w", sidecar, err)
+		}
+		expected, err := common.ParseSize(string(data))
+		if err != nil {
+			return &ErrCheckMalformedSidecar{Path: s.path, Sidecar: sidecar, Reason: err.Error()}
+		}
+		if size := s.info.Size(); size != expected {
+			return &ErrCheckSidecarSize{Path: s.path, Sidecar: sidecar, Expected: expected, Actual: size}
+		}
+		return nil
+	}},
+
+	// Check content digest, after the size checks so obviously-wrong files fail without being read
+	{"RequireSHA256", func(o *Options) bool { return o.RequireSHA256 != "" }, func(s *state) error {
+		expected := strings.ToLower(s.opts.RequireSHA256)
+		actual := emptySHA256
+		if s.info.Size() > 0 {
+			var err error
+			actual, err = common.SHA256FS(s.ctx, s.fsys, s.path)
+			if err != nil {
+				return fmt.Errorf("failed to hash %s: %w", s.path, err)
+			}
+		}
+		if actual != expected {
+			return &ErrCheckBadChecksum{Path: s.path, Expected: expected, Actual: actual}
+		}
+		return nil
+	}},
+
+	// Check Blake2b digest, compiled in only with the checkfs_blake2b build tag
+	{"ExpectedBlake2b", func(o *Options) bool { return o.ExpectedBlake2b != "" }, func(s *state) error {
+		size := s.opts.Blake2bSize
+		if size == 0 {
+			size = 64
+		}
+		expected := strings.ToLower(s.opts.ExpectedBlake2b)
+		actual, err := blake2bFS(s.ctx, s.fsys, s.path, size)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", s.path, err)
+		}
+		if actual != expected {
+			return &ErrCheckBadChecksum{Path: s.path, Expected: expected, Actual: actual}
+		}
+		return nil
+	}},
+
+	// Check exact contents
+	{"RequireContent", func(o *Options) bool { return o.RequireContent != nil }, func(s *state) error {
+		return checkContent(s.ctx, s.fsys, s.path, s.opts.RequireContent, s.opts.CompareTrimmed)
+	}},
+
+	// Check lines against regular expressions
+	{"RequireContentRegex", func(o *Options) bool { return o.RequireContentRegex != "" }, func(s *state) error {
+		re := regexp.MustCompile(s.opts.RequireContentRegex) // already checked by Validate
+		line, err := matchLines(s.ctx, s.fsys, s.path, re)
+		if err != nil {
+			return err
+		}
+		if line == 0 {
+			return &ErrCheckContentNoMatch{Path: s.path, Pattern: s.opts.RequireContentRegex}
+		}
+		return nil
+	}},
+	{"ForbidContentRegex", func(o *Options) bool { return o.ForbidContentRegex != "" }, func(s *state) error {
+		re := regexp.MustCompile(s.opts.ForbidContentRegex) // already checked by Validate
+		line, err := matchLines(s.ctx, s.fsys, s.path, re)
+		if err != nil {
+			return err
+		}
+		if line != 0 {
+			return &ErrCheckContentForbidden{Path: s.path, Pattern: s.opts.ForbidContentRegex, Line: line}
+		}
+		return nil
+	}},
+	{"RequireValidUTF8", func(o *Options) bool { return o.RequireValidUTF8 }, func(s *state) error {
+		return checkUTF8(s.ctx, s.fsys, s.path)
+	}},
+
+	// Check the file starts, or does not start, with a byte order mark
+	{"ForbidBOM", func(o *Options) bool { return o.ForbidBOM }, func(s *state) error {
+		encoding, err := byteOrderMark(s.ctx, s.fsys, s.path)
+		if err != nil {
+			return err
+		}
+		if encoding != "" {
+			return &ErrCheckBOM{Path: s.path, Encoding: encoding}
+		}
+		return nil
+	}},
+	{"RequireBOM", func(o *Options) bool { return o.RequireBOM }, func(s *state) error {
+		encoding, err := byteOrderMark(s.ctx, s.fsys, s.path)
+		if err != nil {
+			return err
+		}
+		if encoding == "" {
+			return &ErrCheckBOM{Path: s.path}
+		}
+		return nil
+	}},
+
+	// Check the file ends, or does not end, with a newline
+	{"RequireTrailingNewline", func(o *Options) bool { return o.RequireTrailingNewline }, func(s *state) error {
+		if s.info.Size() == 0 {
+			return common.Errorf(ErrContentMismatch, "file %s is empty, so it does not end with a newline", s.path)
+		}
+		ends, err := endsWithNewline(s.ctx, s.fsys, s.path, s.info.Size())
+		if err != nil {
+			return err
+		}
+		if !ends {
+			return common.Errorf(ErrContentMismatch, "file %s does not end with a newline", s.path)
+		}
+		return nil
+	}},
+	{"ForbidTrailingNewline", func(o *Options) bool { return o.ForbidTrailingNewline }, func(s *state) error {
+		ends, err := endsWithNewline(s.ctx, s.fsys, s.path, s.info.Size())
+		if err != nil {
+			return err
+		}
+		if ends {
+			return common.Errorf(ErrContentMismatch, "file %s ends with a newline", s.path)
+		}
+		return nil
+	}},
+
+	// Check the line count
+	{"IsLineCount", func(o *Options) bool { return o.IsLineCount != 0 }, func(s *state) error {
+		count, err := s.lineCount()
+		if err != nil {
+			return err
+		}
+		if count != s.opts.IsLineCount {
+			return &ErrCheckLineCount{Path: s.path, Expected: s.opts.IsLineCount, Actual: count}
+		}
+		return nil
+	}},
+	{"MinLines", func(o *Options) bool { return o.MinLines != 0 }, func(s *state) error {
+		count, err := s.lineCount()
+		if err != nil {
+			return err
+		}
+		if count < s.opts.MinLines {
+			return &ErrCheckLineRange{Path: s.path, Min: s.opts.MinLines, Max: s.opts.MaxLines, Actual: count}
+		}
+		return nil
+	}},
+	{"MaxLines", func(o *Options) bool { return o.MaxLines != 0 }, func(s *state) error {
+		count, err := s.lineCount()
+		if err != nil {
+			return err
+		}
+		if count > s.opts.MaxLines {
+			return &ErrCheckLineRange{Path: s.path, Min: s.opts.MinLines, Max: s.opts.MaxLines, Actual: count}
+		}
+		return nil
+	}},
+
+	// Check the contents are in the canonical form of the codec
+	{"CanonicalCodec", func(o *Options) bool { return o.CanonicalCodec != nil }, func(s *state) error {
+		return checkCanonical(s.ctx, s.fsys, s.path, s.opts.CanonicalCodec)
+	}},
+
+	// Check the contents are wrapped in an encryption envelope
+	{"RequireEncrypted", func(o *Options) bool { return o.RequireEncrypted != NoEncryption }, func(s *state) error {
+		return checkEncrypted(s.ctx, s.fsys, s.path, s.opts.RequireEncrypted)
+	}},
+
+	// Check the sniffed content type
+	{"RequireContentType", func(o *Options) bool { return o.RequireContentType != "" }, func(s *state) error {
+		actual, err := s.contentType()
+		if err != nil {
+			return err
+		}
+		if !contentTypeMatches(actual, s.opts.RequireContentType) {
+			return &ErrCheckBadContentType{Path: s.path, Expected: s.opts.RequireContentType, Actual: actual}
+		}
+		return nil
+	}},
+	{"RequireContentTypePrefix", func(o *Options) bool { return o.RequireContentTypePrefix != "" }, func(s *state) error {
+		actual, err := s.contentType()
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(actual, s.opts.RequireContentTypePrefix) {
+			return &ErrCheckBadContentType{Path: s.path, Expected: s.opts.RequireContentTypePrefix + "*", Actual: actual}
+		}
+		return nil
+	}},
+
+	// Check base name length
+	{"IsBaseNameLen", func(o *Options) bool { return o.IsBaseNameLen != 0 }, func(s *state) error {
+		basename := filepath.Base(s.path)
+		if len(basename) != s.opts.IsBaseNameLen {
+			return common.Errorf(ErrNameMismatch, "incorrect base name length for %s: expected %d, got %d",
+				s.path, s.opts.IsBaseNameLen, len(basename))
+		}
+		return nil
+	}},
+
+	// Check file mode
+	{"IsFileMode", func(o *Options) bool { return o.IsFileMode != 0 }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if mode := info.Mode(); mode != s.opts.IsFileMode {
+			return common.Errorf(ErrPermissionMismatch, "incorrect file mode for %s: expected %s, got %s",
+				s.path, s.opts.IsFileMode, mode)
+		}
+		return nil
+	}},
+
+	// Check more permissive than
+	{"MorePermissiveThan", func(o *Options) bool { return o.MorePermissiveThan != 0 }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		isMorePermissive := common.IsMorePermissiveThanInfo(info, s.opts.MorePermissiveThan)
+		if !isMorePermissive {
+			missing, _ := common.ModeDiff(info.Mode(), s.opts.MorePermissiveThan)
+			return common.Errorf(ErrPermissionMismatch, "file mode for %s is less permissive than required: expected at least %o, got %o, missing bits: %04o",
+				s.path, s.opts.MorePermissiveThan, info.Mode().Perm(), missing)
+		}
+		return nil
+	}},
+
+	// Check less permissive than
+	{"LessPermissiveThan", func(o *Options) bool { return o.LessPermissiveThan != 0 }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		isLessPermissive := common.IsLessPermissiveThanInfo(info, s.opts.LessPermissiveThan)
+		if !isLessPermissive {
+			_, extra := common.ModeDiff(info.Mode(), s.opts.LessPermissiveThan)
+			return common.Errorf(ErrPermissionMismatch, "file mode for %s is more permissive than allowed: expected at most %o, got %o, extra bits: %04o",
+				s.path, s.opts.LessPermissiveThan, info.Mode().Perm(), extra)
+		}
+		return nil
+	}},
+
+	// Check permissions
+	{"ReadOnly", func(o *Options) bool { return o.ReadOnly }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&0222 != 0 {
+			return &ErrCheckOpenPermissions{Path: s.path}
+		}
+		if info.Mode().Perm()&0444 == 0 {
+			return &ErrCheckNoReadPermissions{Path: s.path}
+		}
+		return nil
+	}},
+	{"WriteOnly", func(o *Options) bool { return o.WriteOnly }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&0444 != 0 {
+			return &ErrCheckReadPermissions{Path: s.path}
+		}
+		if info.Mode().Perm()&0222 == 0 {
+			return &ErrCheckNoWritePermissions{Path: s.path}
+		}
+		return nil
+	}},
+	{"RequireWrite", func(o *Options) bool { return o.RequireWrite }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&0200 == 0 {
+			return &ErrCheckNoWritePermissions{Path: s.path}
+		}
+		return nil
+	}},
+	{"RequireExecutable", func(o *Options) bool { return o.RequireExecutable }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&0111 == 0 {
+			return &ErrCheckNotExecutable{Path: s.path}
+		}
+		return nil
+	}},
+	{"RequireExecutableBy", func(o *Options) bool { return o.RequireExecutableBy != 0 }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if need := s.opts.RequireExecutableBy.perm(); info.Mode().Perm()&need != need {
+			return &ErrCheckNotExecutable{Path: s.path}
+		}
+		return nil
+	}},
+
+	// Check the special bits, which Perm() and so the permissive-than checks leave out
+	{"ForbidSetuid", func(o *Options) bool { return o.ForbidSetuid }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSetuid != 0 {
+			return &ErrCheckSetuidSet{Path: s.path}
+		}
+		return nil
+	}},
+	{"ForbidSetgid", func(o *Options) bool { return o.ForbidSetgid }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSetgid != 0 {
+			return &ErrCheckSetgidSet{Path: s.path}
+		}
+		return nil
+	}},
+	{"RequireSticky", func(o *Options) bool { return o.RequireSticky && runtime.GOOS != "windows" }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSticky == 0 {
+			return &ErrCheckStickyMissing{Path: s.path}
+		}
+		return nil
+	}},
+
+	// Check access by opening the file, since ACLs, SELinux and NFS root squashing can contradict the mode bits
+	{"RequireReadable", func(o *Options) bool { return o.RequireReadable }, func(s *state) error {
+		f, err := common.OpenFS(s.fsys, s.path)
+		if err != nil {
+			return common.Errorf(ErrPermissionMismatch, "file %s cannot be opened for reading: %w", s.path, err)
+		}
+		return f.Close()
+	}},
+	{"RequireOpenWritable", func(o *Options) bool { return o.RequireOpenWritable }, func(s *state) error {
+		f, err := os.OpenFile(s.path, os.O_WRONLY, 0)
+		if err != nil {
+			return common.Errorf(ErrPermissionMismatch, "file %s cannot be opened for writing: %w", s.path, err)
+		}
+		return f.Close()
+	}},
+	{"RequireAccess", func(o *Options) bool { return o.RequireAccess != 0 }, func(s *state) error {
+		ok, err := common.Access(s.path, s.opts.RequireAccess)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return common.Errorf(ErrPermissionMismatch, "file %s is not accessible to this process with %q access", s.path, s.opts.RequireAccess)
+		}
+		return nil
+	}},
+
+	// Check permissions against the caller's policy
+	{"PermPredicate", func(o *Options) bool { return o.PermPredicate != nil }, func(s *state) error {
+		info, err := s.permInfo()
+		if err != nil {
+			return err
+		}
+		mode := info.Mode()
+		if err := s.opts.PermPredicate(mode); err != nil {
+			return common.Errorf(ErrPermissionMismatch, "permission policy rejected %s (%s): %w", s.path, mode, err)
+		}
+		return nil
+	}},
+
+	// Check owner and group
+	{"RequireOwner", func(o *Options) bool { return o.RequireOwner != "" }, func(s *state) error {
+		uid, _, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
+		}
+		if uid != s.opts.RequireOwner {
+			return &ErrCheckBadOwner{Path: s.path, Expected: s.opts.RequireOwner, Actual: uid}
+		}
+		return nil
+	}},
+	{"RequireGroup", func(o *Options) bool { return o.RequireGroup != "" }, func(s *state) error {
+		_, gid, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
+		}
+		if gid != s.opts.RequireGroup {
+			return &ErrCheckBadGroup{Path: s.path, Expected: s.opts.RequireGroup, Actual: gid}
+		}
+		return nil
+	}},
+	{"RequireOwnedByCurrentUser", func(o *Options) bool { return o.RequireOwnedByCurrentUser }, func(s *state) error {
+		uid, _, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
+		}
+		if current := strconv.Itoa(os.Getuid()); uid != current {
+			return &ErrCheckBadOwner{Path: s.path, Expected: current, Actual: uid}
+		}
+		return nil
+	}},
+	{"RequireOwnedByCurrentGroup", func(o *Options) bool { return o.RequireOwnedByCurrentGroup }, func(s *state) error {
+		_, gid, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
+		}
+		if current := strconv.Itoa(os.Getgid()); gid != current {
+			return &ErrCheckBadGroup{Path: s.path, Expected: current, Actual: gid}
+		}
+		return nil
+	}},
+	{"RequireOwnerName", func(o *Options) bool { return o.RequireOwnerName != "" }, func(s *state) error {
+		name, err := s.owner.OwnerName()
+		if err != nil {
+			return fmt.Errorf("failed to get owner name for %s: %w", s.path, err)
+		}
+		if name != s.opts.RequireOwnerName {
+			return &ErrCheckBadOwner{Path: s.path, Expected: s.opts.RequireOwnerName, Actual: name}
+		}
+		return nil
+	}},
+	{"RequireGroupName", func(o *Options) bool { return o.RequireGroupName != "" }, func(s *state) error {
+		name, err := s.owner.GroupName()
+		if err != nil {
+			return fmt.Errorf("failed to get group name for %s: %w", s.path, err)
+		}
+		if name != s.opts.RequireGroupName {
+			return &ErrCheckBadGroup{Path: s.path, Expected: s.opts.RequireGroupName, Actual: name}
+		}
+		return nil
+	}},
+	{"OwnerUIDRange", func(o *Options) bool { return o.OwnerUIDRange != [2]uint32{} }, func(s *state) error {
+		uid, _, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
+		}
+		actual, err := strconv.ParseUint(uid, 10, 32)
+		if err != nil {
+			return fmt.Errorf("failed to parse uid %q for %s: %w", uid, s.path, err)
+		}
+		if r := s.opts.OwnerUIDRange; uint32(actual) < r[0] || uint32(actual) > r[1] {
+			return &ErrCheckOwnerUIDRange{Path: s.path, Min: r[0], Max: r[1], Actual: uint32(actual)}
+		}
+		return nil
+	}},
+	{"GroupGIDRange", func(o *Options) bool { return o.GroupGIDRange != [2]uint32{} }, func(s *state) error {
+		_, gid, err := s.owner.IDs()
+		if err != nil {
+			return fmt.Errorf("failed to get owner/group for %s: %w", s.path, err)
+		}
+		actual, err := strconv.ParseUint(gid, 10, 32)
+		if err != nil {
+			return fmt.Errorf("failed to parse gid %q for %s: %w", gid, s.path, err)
+		}
+		if r := s.opts.GroupGIDRange; uint32(actual) < r[0] || uint32(actual) > r[1] {
+			return &ErrCheckGroupGIDRange{Path: s.path, Min: r[0], Max: r[1], Actual: uint32(actual)}
+		}
+		return nil
+	}},
+}
+
+type ErrCheckOpenPermissions struct{ Path string }
+type ErrCheckNoWritePermissions struct{ Path string }
+type ErrCheckNoReadPermissions struct{ Path string }
+type ErrCheckReadPermissions struct{ Path string }
+type ErrCheckNotExecutable struct{ Path string }
+type ErrCheckSetuidSet struct{ Path string }
+type ErrCheckSetgidSet struct{ Path string }
+type ErrCheckStickyMissing struct{ Path string }
+type ErrCheckBadOwner struct{ Path, Expected, Actual string }
+type ErrCheckBadGroup struct{ Path, Expected, Actual string }
+type ErrCheckOwnerUIDRange struct {
+	Path             string
+	Min, Max, Actual uint32
+}
+type ErrCheckGroupGIDRange struct {
+	Path             string
+	Min, Max, Actual uint32
+}
+type ErrCheckBadBaseDir struct{ Path, BaseDir string }
+type ErrCheckBadBaseDirs struct {
+	Path     string
+	BaseDirs []string
+}
+type ErrCheckForbiddenBaseDir struct{ Path, BaseDir string }
+type ErrCheckMetadataChanged struct {
+	Path          string
+	Birth, Change time.Time
+}
+type ErrCheckSymlinkComponents struct {
+	Path        string
+	Max, Actual int
+}
+type ErrCheckSymlinkInPath struct{ Path, Link string }
+type ErrCheckTimeout struct{ Path, Op string }
+type ErrCheckHardLinkCount struct {
+	Path             string
+	Expected, Actual uint64
+}
+type ErrCheckIdentityChanged struct {
+	Path, Field      string
+	Expected, Actual uint64
+}
+type ErrCheckBadChecksum struct{ Path, Expected, Actual string }
+type ErrCheckMissingSidecar struct{ Path, Sidecar string }
+type ErrCheckMalformedSidecar struct{ Path, Sidecar, Reason string }
+type ErrCheckSidecarSize struct {
+	Path, Sidecar    string
+	Expected, Actual int64
+}
+type ErrCheckUnexpectedContent struct {
+	Path             string
+	Offset           int
+	Expected, Actual string
+}
+type ErrCheckContentNoMatch struct{ Path, Pattern string }
+type ErrCheckContentForbidden struct {
+	Path, Pattern string
+	Line          int
+}
+type ErrCheckNotCanonical struct {
+	Path             string
+	Offset           int
+	Expected, Actual string
+}
+type ErrCheckBrokenSymlink struct{ Path, Target string }
+type ErrCheckBOM struct {
+	Path     string
+	Encoding string // Encoding is the encoding the byte order mark found announces, "" when there is none
+}
+type ErrCheckWrongType struct {
+	Path string
+	Want FileType
+	Got  os.FileMode // Got holds the type bits of the file found, see os.FileMode.Type
+}
+type ErrCheckNotEncrypted struct {
+	Path   string
+	Format EncryptionFormat
+}
+type ErrCheckBadContentType struct{ Path, Expected, Actual string }
+type ErrCheckInvalidUTF8 struct {
+	Path   string
+	Offset int64
+}
+type ErrCheckLineCount struct {
+	Path             string
+	Expected, Actual int
+}
+type ErrCheckLineRange struct {
+	Path             string
+	Min, Max, Actual int
+}
+
+func (e *ErrCheckOpenPermissions) Error() string {
+	return fmt.Sprintf("permissions too open: %s", e.Path)
+}
+
+func (e *ErrCheckOpenPermissions) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckNoWritePermissions) Error() string {
+	return fmt.Sprintf("no write permission: %s", e.Path)
+}
+
+func (e *ErrCheckNoWritePermissions) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckNoReadPermissions) Error() string {
+	return fmt.Sprintf("no read permission: %s", e.Path)
+}
+
+func (e *ErrCheckNoReadPermissions) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckReadPermissions) Error() string {
+	return fmt.Sprintf("read permission on a write-only file: %s", e.Path)
+}
+
+func (e *ErrCheckReadPermissions) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckNotExecutable) Error() string {
+	return fmt.Sprintf("no execute permission: %s", e.Path)
+}
+
+func (e *ErrCheckNotExecutable) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckSetuidSet) Error() string {
+	return fmt.Sprintf("setuid bit set: %s", e.Path)
+}
+
+func (e *ErrCheckSetuidSet) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckSetgidSet) Error() string {
+	return fmt.Sprintf("setgid bit set: %s", e.Path)
+}
+
+func (e *ErrCheckSetgidSet) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckStickyMissing) Error() string {
+	return fmt.Sprintf("sticky bit not set: %s", e.Path)
+}
+
+func (e *ErrCheckStickyMissing) Is(target error) bool {
+	return target == ErrPermissionMismatch
+}
+
+func (e *ErrCheckBadOwner) Error() string {
+	return fmt.Sprintf("bad owner for %s: expected %s, got %s", e.Path, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckBadOwner) Is(target error) bool {
+	return target == ErrOwnerMismatch
+}
+
+func (e *ErrCheckBadGroup) Error() string {
+	return fmt.Sprintf("bad group for %s: expected %s, got %s", e.Path, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckBadGroup) Is(target error) bool {
+	return target == ErrGroupMismatch
+}
+
+func (e *ErrCheckOwnerUIDRange) Error() string {
+	return fmt.Sprintf("bad owner for %s: expected uid in [%d, %d], got %d", e.Path, e.Min, e.Max, e.Actual)
+}
+
+func (e *ErrCheckOwnerUIDRange) Is(target error) bool {
+	return target == ErrOwnerMismatch
+}
+
+func (e *ErrCheckGroupGIDRange) Error() string {
+	return fmt.Sprintf("bad group for %s: expected gid in [%d, %d], got %d", e.Path, e.Min, e.Max, e.Actual)
+}
+
+func (e *ErrCheckGroupGIDRange) Is(target error) bool {
+	return target == ErrGroupMismatch
+}
+
+func (e *ErrCheckBadBaseDir) Error() string {
+	return fmt.Sprintf("file %s is not in required base directory %s", e.Path, e.BaseDir)
+}
+
+func (e *ErrCheckBadBaseDir) Is(target error) bool {
+	return target == ErrBadBaseDir
+}
+
+func (e *ErrCheckBadBaseDirs) Error() string {
+	return fmt.Sprintf("file %s is not in any of the required base directories %s", e.Path, strings.Join(e.BaseDirs, ", "))
+}
+
+func (e *ErrCheckBadBaseDirs) Is(target error) bool {
+	return target == ErrBadBaseDir
+}
+
+func (e *ErrCheckForbiddenBaseDir) Error() string {
+	return fmt.Sprintf("file %s is inside forbidden base directory %s", e.Path, e.BaseDir)
+}
+
+func (e *ErrCheckForbiddenBaseDir) Is(target error) bool {
+	return target == ErrBadBaseDir
+}
+
+func (e *ErrCheckMetadataChanged) Error() string {
+	return fmt.Sprintf("metadata of %s changed %s after creation", e.Path, e.Change.Sub(e.Birth))
+}
+
+func (e *ErrCheckMetadataChanged) Is(target error) bool {
+	return target == ErrTimeMismatch
+}
+
+func (e *ErrCheckSymlinkComponents) Error() string {
+	return fmt.Sprintf("too many symlinked components in %s: expected at most %d, got %d", e.Path, e.Max, e.Actual)
+}
+
+func (e *ErrCheckSymlinkComponents) Is(target error) bool {
+	return target == ErrSymlinkMismatch
+}
+
+func (e *ErrCheckSymlinkInPath) Error() string {
+	return fmt.Sprintf("strict mode refuses %s: %s is a symlink", e.Path, e.Link)
+}
+
+func (e *ErrCheckSymlinkInPath) Is(target error) bool {
+	return target == ErrSymlinkMismatch
+}
+
+func (e *ErrCheckTimeout) Error() string {
+	return fmt.Sprintf("%s timed out reading %s", e.Op, e.Path)
+}
+
+func (e *ErrCheckTimeout) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+func (e *ErrCheckHardLinkCount) Error() string {
+	return fmt.Sprintf("unexpected hard link count for %s: expected %d, got %d", e.Path, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckHardLinkCount) Is(target error) bool {
+	return target == ErrIdentityMismatch
+}
+
+func (e *ErrCheckIdentityChanged) Error() string {
+	return fmt.Sprintf("%s was replaced: expected %s %d, got %d", e.Path, e.Field, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckIdentityChanged) Is(target error) bool {
+	return target == ErrIdentityMismatch
+}
+
+func (e *ErrCheckBadChecksum) Error() string {
+	return fmt.Sprintf("bad checksum for %s: expected %s, got %s", e.Path, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckBadChecksum) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckMissingSidecar) Error() string {
+	return fmt.Sprintf("missing sidecar %s for %s", e.Sidecar, e.Path)
+}
+
+func (e *ErrCheckMissingSidecar) Is(target error) bool {
+	return target == ErrDoesNotExist
+}
+
+func (e *ErrCheckMalformedSidecar) Error() string {
+	return fmt.Sprintf("malformed sidecar %s for %s: %s", e.Sidecar, e.Path, e.Reason)
+}
+
+func (e *ErrCheckMalformedSidecar) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckSidecarSize) Error() string {
+	return fmt.Sprintf("size of %s does not match sidecar %s: expected %d, got %d",
+		e.Path, e.Sidecar, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckSidecarSize) Is(target error) bool {
+	return target == ErrSizeMismatch
+}
+
+func (e *ErrCheckUnexpectedContent) Error() string {
+	return fmt.Sprintf("unexpected content in %s: differs at byte %d, expected %q, got %q",
+		e.Path, e.Offset, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckUnexpectedContent) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckContentNoMatch) Error() string {
+	return fmt.Sprintf("no line in %s matches %s", e.Path, e.Pattern)
+}
+
+func (e *ErrCheckContentNoMatch) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckContentForbidden) Error() string {
+	return fmt.Sprintf("line %d of %s matches forbidden pattern %s", e.Line, e.Path, e.Pattern)
+}
+
+func (e *ErrCheckContentForbidden) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckNotCanonical) Error() string {
+	return fmt.Sprintf("file %s is not canonical: differs at byte %d, expected %q, got %q",
+		e.Path, e.Offset, e.Expected, e.Actual)
+}
+
+func (e *ErrCheckNotCanonical) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckNotEncrypted) Error() string {
+	return fmt.Sprintf("file %s is not encrypted as %s", e.Path, e.Format)
+}
+
+func (e *ErrCheckNotEncrypted) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckBrokenSymlink) Error() string {
+	return fmt.Sprintf("broken symlink %s: target %s does not exist", e.Path, e.Target)
+}
+
+func (e *ErrCheckBrokenSymlink) Is(target error) bool {
+	return target == ErrSymlinkMismatch
+}
+
+func (e *ErrCheckBOM) Error() string {
+	if e.Encoding == "" {
+		return fmt.Sprintf("file %s has no byte order mark", e.Path)
+	}
+	return fmt.Sprintf("file %s starts with a %s byte order mark", e.Path, e.Encoding)
+}
+
+func (e *ErrCheckBOM) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckWrongType) Error() string {
+	return fmt.Sprintf("file %s is a %s, not a %s", e.Path, typeName(e.Got), e.Want)
+}
+
+func (e *ErrCheckWrongType) Is(target error) bool {
+	return target == ErrNotRegularFile
+}
+
+func (e *ErrCheckBadContentType) Error() string {
+	return fmt.Sprintf("file %s has content type %s, expected %s", e.Path, e.Actual, e.Expected)
+}
+
+func (e *ErrCheckBadContentType) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckInvalidUTF8) Error() string {
+	return fmt.Sprintf("file %s is not valid UTF-8: invalid sequence at byte %d", e.Path, e.Offset)
+}
+
+func (e *ErrCheckInvalidUTF8) Is(target error) bool {
+	return target == ErrContentMismatch
+}
+
+func (e *ErrCheckLineCount) Error() string {
+	return fmt.Sprintf("file %s has %d lines, expected %d", e.Path, e.Actual, e.Expected)
+}
+
+func (e *ErrCheckLineCount) Is(target error) bool {
+	return target == ErrSizeMismatch
+}
+
+func (e *ErrCheckLineRange) Error() string {
+	if e.Max == 0 {
+		return fmt.Sprintf("file %s has %d lines, expected at least %d", e.Path, e.Actual, e.Min)
+	}
+	return fmt.Sprintf("file %s has %d lines, expected between %d and %d", e.Path, e.Actual, e.Min, e.Max)
+}
+
+func (e *ErrCheckLineRange) Is(target error) bool {
+	return target == ErrSizeMismatch
 }
@@ -1,10 +1,20 @@
 package file
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
 )
 
 func TestFile(t *testing.T) {
@@ -91,6 +101,9 @@ func TestFile(t *testing.T) {
 		{"Valid read-only", regularFile, Options{ReadOnly: true}, true},           // 0644 has write bits
 		{"Valid write required", regularFile, Options{RequireWrite: true}, false}, // 0644 has write
 		{"Valid write-only", regularFile, Options{WriteOnly: true}, true},         // 0644 has read bits
+		{"Opens for reading", regularFile, Options{RequireReadable: true}, false},
+		{"Opens for writing", regularFile, Options{RequireOpenWritable: true}, false},
+		{"Access read and write", regularFile, Options{RequireAccess: AccessRead | AccessWrite}, false},
 
 		// File mode tests
 		{"Valid file mode", regularFile, Options{IsFileMode: 0644}, false},
@@ -189,3 +202,2100 @@ func BenchmarkFile(b *testing.B) {
 		})
 	}
 }
+
+func TestNewCreate(t *testing.T) {
+	dir := t.TempDir()
+	original := &Create{
+		Path:     filepath.Join(dir, "test.txt"),
+		Kind:     IfNotExists,
+		FileMode: 0640,
+		OpenFlag: os.O_CREATE | os.O_TRUNC | os.O_WRONLY,
+		Size:     12,
+		Atomic:   true,
+		Sync:     true,
+		Content:  []byte("hello world\n"),
+
+		RequireFreeBytes: 1,
+	}
+
+	t.Run("Round trips every field", func(t *testing.T) {
+		got := NewCreate(original)
+		if got == original {
+			t.Fatal("NewCreate() should return a copy, not the argument")
+		}
+		if !reflect.DeepEqual(got, original) {
+			t.Errorf("NewCreate() = %+v, want %+v", *got, *original)
+		}
+		if empty := NewCreate(nil); !reflect.DeepEqual(*empty, Create{}) {
+			t.Errorf("NewCreate(nil) = %+v, want zero value", *empty)
+		}
+	})
+
+	t.Run("Doc comment example creates the file", func(t *testing.T) {
+		path := filepath.Join(dir, "example.txt")
+		err := NewCreate(&Create{
+			Kind:     IfNotExists,
+			Path:     path,
+			OpenFlag: os.O_CREATE | os.O_TRUNC | os.O_WRONLY,
+			FileMode: 0644,
+		}).Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if err := File(path, Options{Exists: true}); err != nil {
+			t.Errorf("File() error = %v", err)
+		}
+	})
+
+	t.Run("Missing OpenFlag", func(t *testing.T) {
+		err := NewCreate(&Create{Kind: IfNotExists, Path: filepath.Join(dir, "flagless.txt")}).Run()
+		if !errors.Is(err, ErrMissingOpenFlag) {
+			t.Errorf("Run() error = %v, want ErrMissingOpenFlag", err)
+		}
+	})
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		for _, kind := range []CreateKind{NoAction, CreateKind(42)} {
+			err := NewCreate(&Create{Kind: kind, OpenFlag: os.O_CREATE}).Run()
+			if !errors.Is(err, ErrUnknownCreateKind) {
+				t.Errorf("Run() with kind %d error = %v, want ErrUnknownCreateKind", kind, err)
+			}
+		}
+	})
+}
+
+func TestCreateAtomic(t *testing.T) {
+	dir := t.TempDir()
+	original := []byte("original contents")
+
+	// leftovers lists the .tmp-* files an Atomic create left behind in dir
+	leftovers := func(t *testing.T) []string {
+		t.Helper()
+		matches, err := filepath.Glob(filepath.Join(dir, ".tmp-*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return matches
+	}
+
+	t.Run("Creates the file with FileMode", func(t *testing.T) {
+		path := filepath.Join(dir, "new.conf")
+		err := NewCreate(&Create{Kind: IfNotExists, Path: path, FileMode: 0640, Size: 8, Atomic: true, Sync: true}).Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if err := File(path, Options{IsSize: 8, IsFileMode: 0640}); err != nil {
+			t.Errorf("File() error = %v", err)
+		}
+		if got := leftovers(t); len(got) != 0 {
+			t.Errorf("temp files left behind: %v", got)
+		}
+	})
+
+	t.Run("Replaces an existing file", func(t *testing.T) {
+		path := filepath.Join(dir, "replace.conf")
+		if err := os.WriteFile(path, original, 0600); err != nil {
+			t.Fatal(err)
+		}
+		err := NewCreate(&Create{Kind: IfExists, Path: path, FileMode: 0644, Size: 4, Atomic: true}).Run()
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if err := File(path, Options{IsSize: 4, IsFileMode: 0644}); err != nil {
+			t.Errorf("File() error = %v", err)
+		}
+	})
+
+	t.Run("Failed write leaves the target untouched", func(t *testing.T) {
+		path := filepath.Join(dir, "untouched.conf")
+		if err := os.WriteFile(path, original, 0600); err != nil {
+			t.Fatal(err)
+		}
+		errDiskFull := errors.New("disk full")
+		defer func(w func(*Create, *os.File) (int64, error)) { writeTemp = w }(writeTemp)
+		writeTemp = func(_ *Create, f *os.File) (int64, error) {
+			n, _ := f.Write(make([]byte, 8))
+			return int64(n), errDiskFull
+		}
+
+		err := NewCreate(&Create{Kind: IfExists, Path: path, FileMode: 0644, Size: 16, Atomic: true}).Run()
+		if !errors.Is(err, errDiskFull) {
+			t.Fatalf("Run() error = %v, want %v", err, errDiskFull)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(original) {
+			t.Errorf("target = %q, want %q", got, original)
+		}
+		if got := leftovers(t); len(got) != 0 {
+			t.Errorf("temp files left behind: %v", got)
+		}
+	})
+
+	t.Run("Short write is an error", func(t *testing.T) {
+		path := filepath.Join(dir, "short.conf")
+		defer func(w func(*Create, *os.File) (int64, error)) { writeTemp = w }(writeTemp)
+		writeTemp = func(_ *Create, f *os.File) (int64, error) {
+			n, err := f.Write([]byte{0})
+			return int64(n), err
+		}
+
+		err := NewCreate(&Create{Kind: IfNotExists, Path: path, FileMode: 0644, Size: 16, Atomic: true}).Run()
+		if err == nil {
+			t.Fatal("Run() error = nil, want a short write error")
+		}
+		if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat() error = %v, want fs.ErrNotExist", err)
+		}
+		if got := leftovers(t); len(got) != 0 {
+			t.Errorf("temp files left behind: %v", got)
+		}
+	})
+}
+
+func TestCreateDryRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+
+	replace := &Create{
+		Kind:     IfExists,
+		Path:     path,
+		OpenFlag: os.O_CREATE | os.O_TRUNC | os.O_WRONLY,
+		FileMode: 0600,
+		Content:  []byte("port: 9090\n"),
+		DryRun:   true,
+	}
+	if err := replace.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() after dry run error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "port: 8080\n" || after.Mode() != before.Mode() || !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("dry run changed %s: %q, %s, %s", path, data, after.Mode(), after.ModTime())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("dry run left %d entries in %s, want 1", len(entries), dir)
+	}
+
+	plan, err := replace.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	want := []string{
+		"remove file " + path,
+		fmt.Sprintf("open file %s with flags %#x and mode -rw-------", path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY),
+		"write 11 bytes of Content to " + path,
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("Plan() = %q, want %q", plan, want)
+	}
+
+	atomic := *replace
+	atomic.Atomic, atomic.Sync, atomic.OpenFlag, atomic.Content = true, true, 0, nil
+	plan, err = atomic.Plan()
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan) != 3 || !strings.HasPrefix(plan[0], "write nothing to a temporary file") {
+		t.Errorf("Plan() = %q, want an atomic write, sync and rename", plan)
+	}
+
+	tests := []struct {
+		name    string
+		create  Create
+		wantErr error
+	}{
+		{"IfExists without a target", Create{Kind: IfExists, Path: filepath.Join(dir, "missing"), OpenFlag: os.O_WRONLY}, fs.ErrNotExist},
+		{"Missing parent", Create{Kind: IfNotExists, Path: filepath.Join(dir, "nested", "new"), OpenFlag: os.O_CREATE}, fs.ErrNotExist},
+		{"Parent is a file", Create{Kind: IfNotExists, Path: filepath.Join(path, "new"), OpenFlag: os.O_CREATE}, common.ErrNotDirectory},
+		{"Unknown kind", Create{Kind: CreateKind(42), Path: path}, ErrUnknownCreateKind},
+		{"Missing OpenFlag", Create{Kind: IfNotExists, Path: path}, ErrMissingOpenFlag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.create.DryRun = true
+			if err := tt.create.Run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if _, err := os.Stat(filepath.Join(dir, "nested")); !os.IsNotExist(err) {
+		t.Errorf("dry run created %s", filepath.Join(dir, "nested"))
+	}
+}
+
+func TestCreateBackup(t *testing.T) {
+	dir := t.TempDir()
+	backups := filepath.Join(dir, "backups")
+	path := filepath.Join(dir, "app.conf")
+	write := func(t *testing.T, contents string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	read := func(t *testing.T, name string) string {
+		t.Helper()
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		return string(data)
+	}
+
+	for _, atomic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Replace with Atomic %v", atomic), func(t *testing.T) {
+			write(t, "old")
+			backup, err := (&Create{
+				Kind:      IfExists,
+				Path:      path,
+				OpenFlag:  os.O_CREATE | os.O_TRUNC | os.O_WRONLY,
+				FileMode:  0644,
+				Content:   []byte("new"),
+				Atomic:    atomic,
+				BackupDir: backups,
+			}).RunWithBackup()
+			if err != nil {
+				t.Fatalf("RunWithBackup() error = %v", err)
+			}
+			if filepath.Dir(backup) != backups {
+				t.Fatalf("RunWithBackup() backup = %s, want it inside %s", backup, backups)
+			}
+			if got := read(t, backup); got != "old" {
+				t.Errorf("backup holds %q, want %q", got, "old")
+			}
+			if got := read(t, path); got != "new" {
+				t.Errorf("%s holds %q, want %q", path, got, "new")
+			}
+		})
+	}
+
+	t.Run("Failed create restores the backup", func(t *testing.T) {
+		write(t, "old")
+		before, _ := os.ReadDir(backups)
+		backup, err := (&Create{
+			Kind:      IfExists,
+			Path:      path,
+			OpenFlag:  os.O_WRONLY, // fails once the file has been moved away
+			BackupDir: backups,
+		}).RunWithBackup()
+		if err == nil || backup != "" {
+			t.Fatalf("RunWithBackup() = %q, %v, want an error and no backup", backup, err)
+		}
+		if got := read(t, path); got != "old" {
+			t.Errorf("%s holds %q after the failed create, want the restored %q", path, got, "old")
+		}
+		if after, _ := os.ReadDir(backups); len(after) != len(before) {
+			t.Errorf("backup directory holds %d entries, want %d", len(after), len(before))
+		}
+	})
+
+	t.Run("Without BackupDir nothing is kept", func(t *testing.T) {
+		write(t, "old")
+		backup, err := (&Create{Kind: IfExists, Path: path, OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644}).RunWithBackup()
+		if err != nil || backup != "" {
+			t.Errorf("RunWithBackup() = %q, %v, want no backup", backup, err)
+		}
+	})
+}
+
+func TestCreateRequireBaseDir(t *testing.T) {
+	root := t.TempDir()
+	base := filepath.Join(root, "uploads")
+	if err := os.MkdirAll(filepath.Join(base, "user"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	outside := filepath.Join(root, "escape")
+	if err := os.WriteFile(outside, []byte("keep"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		kind    CreateKind
+		wantErr bool
+	}{
+		{"Nested path", filepath.Join(base, "user", "avatar.png"), IfNotExists, false},
+		{"Traversal", filepath.Join(base, "user", "..", "..", "escape.new"), IfNotExists, true},
+		{"Traversal with IfExists", filepath.Join(base, "..", "escape"), IfExists, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create := &Create{
+				Kind:           tt.kind,
+				Path:           tt.path,
+				OpenFlag:       os.O_CREATE | os.O_WRONLY,
+				FileMode:       0644,
+				RequireBaseDir: base,
+			}
+			err := create.Run()
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Run() error = %v", err)
+				}
+				return
+			}
+			var badBase *ErrCheckBadBaseDir
+			if !errors.As(err, &badBase) || !errors.Is(err, ErrBadBaseDir) {
+				t.Errorf("Run() error = %v, want *ErrCheckBadBaseDir", err)
+			}
+			if _, err := os.Stat(filepath.Join(root, "escape.new")); !os.IsNotExist(err) {
+				t.Errorf("Run() created a file outside %s", base)
+			}
+			if data, err := os.ReadFile(outside); err != nil || string(data) != "keep" {
+				t.Errorf("Run() touched %s: %q, %v", outside, data, err)
+			}
+		})
+	}
+
+	t.Run("Symlink out of the base", func(t *testing.T) {
+		if err := os.Symlink(root, filepath.Join(base, "link")); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+		create := &Create{Kind: IfExists, Path: filepath.Join(base, "link", "escape"), OpenFlag: os.O_CREATE | os.O_WRONLY, RequireBaseDir: base}
+		if err := create.Run(); !errors.Is(err, ErrBadBaseDir) {
+			t.Errorf("Run() error = %v, want ErrBadBaseDir", err)
+		}
+		if _, err := create.Plan(); !errors.Is(err, ErrBadBaseDir) {
+			t.Errorf("Plan() error = %v, want ErrBadBaseDir", err)
+		}
+		if data, err := os.ReadFile(outside); err != nil || string(data) != "keep" {
+			t.Errorf("Run() touched %s: %q, %v", outside, data, err)
+		}
+	})
+}
+
+func TestCreateRequireFreeBytes(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := common.AvailableBytes(dir); errors.Is(err, common.ErrFreeSpaceUnsupported) {
+		t.Skipf("free space not supported: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		freeBytes int64
+		err       error
+	}{
+		{"Unset", 0, nil},
+		{"Plausible", 1, nil},
+		{"Absurd", 1 << 62, ErrSizeMismatch},
+		{"Negative", -1, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".bin")
+			create := &Create{
+				Kind:             IfNotExists,
+				Path:             path,
+				OpenFlag:         os.O_CREATE | os.O_WRONLY,
+				FileMode:         0644,
+				Size:             KB,
+				RequireFreeBytes: tt.freeBytes,
+			}
+			if _, err := create.Plan(); !errors.Is(err, tt.err) {
+				t.Errorf("Plan() error = %v, want %v", err, tt.err)
+			}
+			err := create.Run()
+			if tt.err == nil {
+				if err != nil {
+					t.Errorf("Run() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Run() error = %v, want %v", err, tt.err)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("Run() created %s despite failing the precheck", path)
+			}
+		})
+	}
+}
+
+func TestCreateRejectSymlinkComponents(t *testing.T) {
+	// resolve the temp dir itself, which sits below a symlink on some systems (/var on macOS)
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	victim := filepath.Join(root, "etc")
+	if err := os.MkdirAll(filepath.Join(root, "spool", "jobs"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.Mkdir(victim, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	link := filepath.Join(root, "spool", "incoming")
+	if err := os.Symlink(victim, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		kind     CreateKind
+		atomic   bool
+		wantLink string // wantLink is the component ErrCheckSymlinkInPath names, "" when Run passes
+	}{
+		{"Real parents", filepath.Join(root, "spool", "jobs", "1.job"), IfNotExists, false, ""},
+		{"Missing parents", filepath.Join(root, "spool", "new", "1.job"), IfNotExists, false, ""},
+		{"Symlinked parent", filepath.Join(link, "passwd"), IfNotExists, false, link},
+		{"Symlinked grandparent", filepath.Join(link, "cron.d", "job"), IfNotExists, false, link},
+		{"Symlinked parent with IfExists", filepath.Join(link, "passwd"), IfExists, false, link},
+		{"Symlinked parent with Atomic", filepath.Join(link, "passwd"), IfExists, true, link},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create := &Create{
+				Kind:                    tt.kind,
+				Path:                    tt.path,
+				OpenFlag:                os.O_CREATE | os.O_WRONLY,
+				FileMode:                0644,
+				Atomic:                  tt.atomic,
+				RejectSymlinkComponents: true,
+			}
+			if tt.wantLink == "" {
+				if err := os.MkdirAll(filepath.Dir(tt.path), 0755); err != nil {
+					t.Fatalf("Failed to create test directory: %v", err)
+				}
+				if err := create.Run(); err != nil {
+					t.Errorf("Run() error = %v", err)
+				}
+				return
+			}
+			if _, err := create.Plan(); !errors.Is(err, ErrSymlinkMismatch) {
+				t.Errorf("Plan() error = %v, want ErrSymlinkMismatch", err)
+			}
+			var linkErr *ErrCheckSymlinkInPath
+			if err := create.Run(); !errors.As(err, &linkErr) || linkErr.Link != tt.wantLink {
+				t.Errorf("Run() error = %v, want *ErrCheckSymlinkInPath naming %s", err, tt.wantLink)
+			}
+		})
+	}
+	if entries, err := os.ReadDir(victim); err != nil || len(entries) > 0 {
+		t.Errorf("Run() wrote through the symlink into %s: %v, %v", victim, entries, err)
+	}
+
+	t.Run("Unset follows the symlink", func(t *testing.T) {
+		create := &Create{Kind: IfNotExists, Path: filepath.Join(link, "followed"), OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644}
+		if err := create.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(victim, "followed")); err != nil {
+			t.Errorf("Run() without RejectSymlinkComponents did not follow the symlink: %v", err)
+		}
+	})
+}
+
+// chunkRecorder is an io.Writer that counts what it is given and remembers the largest single write
+type chunkRecorder struct{ total, largest int64 }
+
+func (w *chunkRecorder) Write(p []byte) (int, error) {
+	w.total += int64(len(p))
+	if int64(len(p)) > w.largest {
+		w.largest = int64(len(p))
+	}
+	for _, b := range p {
+		if b != 0 {
+			return 0, errors.New("non-zero byte")
+		}
+	}
+	return len(p), nil
+}
+
+func TestCreateSize(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("Negative", func(t *testing.T) {
+		path := filepath.Join(dir, "negative.bin")
+		create := &Create{Kind: IfNotExists, Path: path, OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644, Size: -1}
+		if _, err := create.Plan(); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Plan() error = %v, want ErrInvalidOptions", err)
+		}
+		if err := create.Run(); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Run() error = %v, want ErrInvalidOptions", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Run() created %s despite the negative Size", path)
+		}
+	})
+
+	t.Run("Chunked", func(t *testing.T) {
+		size := 3*zeroChunk + 5
+		var w chunkRecorder
+		written, err := (&Create{Size: size}).write(&w)
+		if err != nil || written != size || w.total != size {
+			t.Fatalf("write() = %d, %v with %d bytes seen, want %d", written, err, w.total, size)
+		}
+		if w.largest > zeroChunk {
+			t.Errorf("largest write = %d bytes, want at most zeroChunk %d", w.largest, zeroChunk)
+		}
+
+		path := filepath.Join(dir, "chunked.bin")
+		if err := (&Create{Kind: IfNotExists, Path: path, OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644, Size: zeroChunk + 1}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if err := File(path, Options{IsSize: zeroChunk + 1}); err != nil {
+			t.Errorf("File() error = %v", err)
+		}
+	})
+
+	t.Run("Sparse", func(t *testing.T) {
+		for _, atomic := range []bool{false, true} {
+			path := filepath.Join(dir, fmt.Sprintf("sparse-%v.img", atomic))
+			create := &Create{Kind: IfNotExists, Path: path, OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644, Size: 256 << 20, Sparse: true, Atomic: atomic}
+			if plan, err := create.Plan(); err != nil || !strings.Contains(strings.Join(plan, "\n"), "sparse") {
+				t.Errorf("Plan() = %q, %v; want a sparse write", plan, err)
+			}
+			if err := create.Run(); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if err := File(path, Options{IsSize: 256 << 20}); err != nil {
+				t.Errorf("File() error = %v", err)
+			}
+		}
+		withContent := &Create{Kind: IfNotExists, Path: filepath.Join(dir, "content.img"), OpenFlag: os.O_CREATE | os.O_WRONLY, Content: []byte("x"), Sparse: true}
+		if err := withContent.Run(); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Run() with Sparse and Content error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
+
+func TestCreateLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if lock, err := common.FileLock(filepath.Join(dir, "probe.lock")); err != nil {
+		t.Skipf("file locking not supported: %v", err)
+	} else {
+		_ = common.Unlock(lock)
+	}
+
+	const creators = 8
+	readers := make([]*strings.Reader, creators)
+	contents := make([]string, creators)
+	errs := make(chan error, creators)
+	start := make(chan struct{})
+	for i := range readers {
+		contents[i] = strings.Repeat(string(rune('a'+i)), 64<<10)
+		readers[i] = strings.NewReader(contents[i])
+		create := &Create{
+			Kind:          IfNotExists,
+			Path:          path,
+			OpenFlag:      os.O_CREATE | os.O_TRUNC | os.O_WRONLY,
+			FileMode:      0644,
+			ContentReader: readers[i],
+			Lock:          true,
+		}
+		go func() {
+			<-start
+			errs <- create.Run()
+		}()
+	}
+	close(start)
+	for i := 0; i < creators; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	}
+
+	winner := -1
+	for i, r := range readers {
+		switch r.Len() {
+		case 0:
+			if winner >= 0 {
+				t.Errorf("creators %d and %d both wrote the file", winner, i)
+			}
+			winner = i
+		case len(contents[i]):
+		default:
+			t.Errorf("creator %d stopped part way through its contents", i)
+		}
+	}
+	if winner < 0 {
+		t.Fatal("no creator wrote the file")
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != contents[winner] {
+		t.Errorf("file holds %d bytes, %v, want exactly the contents of creator %d", len(got), err, winner)
+	}
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Errorf("lock file missing: %v", err)
+	}
+
+	replace := &Create{Kind: IfExists, Path: path, OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644, Content: []byte("new"), Lock: true}
+	if err := replace.Run(); err != nil {
+		t.Fatalf("Run() with IfExists error = %v", err)
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != "new" {
+		t.Errorf("file after IfExists = %q, %v, want \"new\"", got, err)
+	}
+}
+
+func TestCreateContent(t *testing.T) {
+	dir := t.TempDir()
+	const want = "listen: 0.0.0.0:8080\n"
+	flag := os.O_CREATE | os.O_TRUNC | os.O_WRONLY
+
+	tests := []struct {
+		name    string
+		create  Create
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "Content",
+			create: Create{Content: []byte(want), OpenFlag: flag},
+			want:   want,
+		},
+		{
+			name:   "ContentReader",
+			create: Create{ContentReader: strings.NewReader(want), OpenFlag: flag},
+			want:   want,
+		},
+		{
+			name:   "Atomic ContentReader",
+			create: Create{ContentReader: strings.NewReader(want), Atomic: true},
+			want:   want,
+		},
+		{
+			name:   "Content with matching Size",
+			create: Create{Content: []byte(want), Size: int64(len(want)), OpenFlag: flag},
+			want:   want,
+		},
+		{
+			name:    "Content with wrong Size",
+			create:  Create{Content: []byte(want), Size: 3, OpenFlag: flag},
+			wantErr: ErrSizeMismatch,
+		},
+		{
+			name:   "Size writes zeros",
+			create: Create{Size: 4, OpenFlag: flag},
+			want:   "\x00\x00\x00\x00",
+		},
+		{
+			name:    "Content and ContentReader",
+			create:  Create{Content: []byte(want), ContentReader: strings.NewReader(want), OpenFlag: flag},
+			wantErr: ErrInvalidOptions,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			create := tt.create
+			create.Kind = IfNotExists
+			create.FileMode = 0644
+			create.Path = filepath.Join(dir, fmt.Sprintf("content-%d.txt", i))
+			err := create.Run()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			got, err := os.ReadFile(create.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("contents = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileRequireSHA256(t *testing.T) {
+	dir := t.TempDir()
+	abcFile := filepath.Join(dir, "abc.txt")
+	corruptFile := filepath.Join(dir, "corrupt.txt")
+	emptyFile := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(abcFile, []byte("abc"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(corruptFile, []byte("abd"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(emptyFile, nil, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	abc := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Known vector", abcFile, Options{RequireSHA256: abc}, false},
+		{"Uppercase digest", abcFile, Options{RequireSHA256: strings.ToUpper(abc)}, false},
+		{"Corrupted file", corruptFile, Options{RequireSHA256: abc}, true},
+		{"Empty file with empty digest", emptyFile, Options{RequireSHA256: emptySHA256}, false},
+		{"Empty file with other digest", emptyFile, Options{RequireSHA256: abc}, true},
+		{"Size check fails first", abcFile, Options{RequireSHA256: abc, IsSize: 10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var checksumErr *ErrCheckBadChecksum
+	if err := File(corruptFile, Options{RequireSHA256: abc}); !errors.As(err, &checksumErr) || checksumErr.Expected != abc {
+		t.Errorf("File() error = %v, want ErrCheckBadChecksum", err)
+	}
+}
+
+func TestFileRequireExts(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"config.yaml", "config.YML", "DATA.JSON", "notes.txt", "Makefile"}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	configExts := []string{".yaml", ".yml", ".json"}
+	tests := []struct {
+		name    string
+		file    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Lowercase match", "config.yaml", Options{RequireExts: configExts}, false},
+		{"Mixed case match", "config.YML", Options{RequireExts: configExts}, false},
+		{"Uppercase file", "DATA.JSON", Options{RequireExts: configExts}, false},
+		{"Uppercase accepted extension", "notes.txt", Options{RequireExts: []string{".TXT"}}, false},
+		{"No match", "notes.txt", Options{RequireExts: configExts}, true},
+		{"No extension", "Makefile", Options{RequireExts: configExts}, true},
+		{"Combined with RequireExt", "notes.txt", Options{RequireExt: ".txt", RequireExts: configExts}, false},
+		{"Combined with RequireExt no match", "Makefile", Options{RequireExt: ".txt", RequireExts: configExts}, true},
+		{"RequireExt alone stays exact", "DATA.JSON", Options{RequireExt: ".json"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(filepath.Join(dir, tt.file), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	err := File(filepath.Join(dir, "notes.txt"), Options{RequireExts: configExts})
+	if err == nil || !strings.Contains(err.Error(), ".yaml, .yml, .json") {
+		t.Errorf("File() error = %v, want every accepted extension listed", err)
+	}
+}
+
+func TestFileRequireSuffix(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"db.sql.backup", "report_final", "report_final.pdf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Suffix that is an extension", "db.sql.backup", Options{RequireSuffix: ".backup"}, false},
+		{"Suffix that is not an extension", "report_final", Options{RequireSuffix: "_final"}, false},
+		{"Suffix before the extension", "report_final.pdf", Options{RequireSuffix: "_final"}, true},
+		{"Suffix overlapping RequireExt", "db.sql.backup", Options{RequireSuffix: "sql.backup", RequireExt: ".backup"}, false},
+		{"Suffix passes but RequireExt fails", "report_final", Options{RequireSuffix: "_final", RequireExt: ".pdf"}, true},
+		{"Empty suffix is a no-op", "report_final", Options{RequireSuffix: ""}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(filepath.Join(dir, tt.file), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrNameMismatch) {
+				t.Errorf("File() error = %v, want ErrNameMismatch", err)
+			}
+		})
+	}
+}
+
+func TestFileRequireNameMatch(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"report-2024-q1.csv", "report-1999-q1.csv", "report-2024.csv"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		opts    Options
+		wantErr error
+	}{
+		{"Match", "report-2024-q1.csv", Options{RequireNameMatch: "report-20??-*.csv"}, nil},
+		{"Wrong century", "report-1999-q1.csv", Options{RequireNameMatch: "report-20??-*.csv"}, ErrNameMismatch},
+		{"Missing quarter", "report-2024.csv", Options{RequireNameMatch: "report-20??-*.csv"}, ErrNameMismatch},
+		{"Only the base name is matched", "report-2024-q1.csv", Options{RequireNameMatch: "*/report-*"}, ErrNameMismatch},
+		{"With prefix and ext", "report-2024-q1.csv", Options{RequireNameMatch: "*-q[1-4].*", RequirePrefix: "report-", RequireExt: ".csv"}, nil},
+		{"Match but wrong ext", "report-2024-q1.csv", Options{RequireNameMatch: "report-*", RequireExt: ".pdf"}, ErrNameMismatch},
+		{"Invalid pattern", "report-2024-q1.csv", Options{RequireNameMatch: "["}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(filepath.Join(dir, tt.file), tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("File() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileEmptiness(t *testing.T) {
+	dir := t.TempDir()
+	emptyFile := filepath.Join(dir, "empty.lock")
+	oneByteFile := filepath.Join(dir, "one.log")
+	if err := os.WriteFile(emptyFile, nil, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(oneByteFile, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr bool
+	}{
+		{"NonEmpty with 0-byte file", emptyFile, Options{NonEmpty: true}, true},
+		{"NonEmpty with 1-byte file", oneByteFile, Options{NonEmpty: true}, false},
+		{"MustBeEmpty with 0-byte file", emptyFile, Options{MustBeEmpty: true}, false},
+		{"MustBeEmpty with 1-byte file", oneByteFile, Options{MustBeEmpty: true}, true},
+		{"NonEmpty with matching IsSize", oneByteFile, Options{NonEmpty: true, IsSize: 1}, false},
+		{"NonEmpty with mismatched IsSize", oneByteFile, Options{NonEmpty: true, IsSize: 2}, true},
+		{"MustBeEmpty with IsSize", emptyFile, Options{MustBeEmpty: true, IsSize: 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Both set is a configuration error", func(t *testing.T) {
+		missing := filepath.Join(dir, "never-stat.txt")
+		err := File(missing, Options{NonEmpty: true, MustBeEmpty: true})
+		if !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("File() error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
+
+func TestFileExecutable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no execute bits")
+	}
+	dir := t.TempDir()
+	modes := map[string]os.FileMode{"config.yaml": 0644, "deploy.sh": 0755, "group.sh": 0710}
+	for name, mode := range modes {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Chmod(path, mode); err != nil { // undo the umask
+			t.Fatalf("Failed to chmod test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		opts    Options
+		wantErr bool
+	}{
+		{"0644 is not executable", "config.yaml", Options{RequireExecutable: true}, true},
+		{"0755 is executable", "deploy.sh", Options{RequireExecutable: true}, false},
+		{"0710 is executable", "group.sh", Options{RequireExecutable: true}, false},
+		{"0755 by everyone", "deploy.sh", Options{RequireExecutableBy: ByOwner | ByGroup | ByOther}, false},
+		{"0644 by owner", "config.yaml", Options{RequireExecutableBy: ByOwner}, true},
+		{"0710 by group", "group.sh", Options{RequireExecutableBy: ByGroup}, false},
+		{"0710 by owner and group", "group.sh", Options{RequireExecutableBy: ByOwner | ByGroup}, false},
+		{"0710 by other", "group.sh", Options{RequireExecutableBy: ByOther}, true},
+		{"With LessPermissiveThan", "deploy.sh", Options{RequireExecutable: true, LessPermissiveThan: 0755}, false},
+		{"With MorePermissiveThan", "group.sh", Options{RequireExecutableBy: ByGroup, MorePermissiveThan: 0700}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(filepath.Join(dir, tt.file), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var notExec *ErrCheckNotExecutable
+			if tt.wantErr && (!errors.As(err, &notExec) || !errors.Is(err, ErrPermissionMismatch)) {
+				t.Errorf("File() error = %v, want ErrCheckNotExecutable matching ErrPermissionMismatch", err)
+			}
+		})
+	}
+}
+
+func TestFileSizeStr(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "upload.bin")
+	if err := os.WriteFile(path, make([]byte, 1536), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{"Less than 2KB", Options{IsLessThanStr: "2KB"}, nil},
+		{"Less than 1.5KB", Options{IsLessThanStr: "1.5KB"}, ErrSizeMismatch},
+		{"Greater than 1KB", Options{IsGreaterThanStr: "1KB"}, nil},
+		{"Greater than 1.5 KiB", Options{IsGreaterThanStr: "1.5 KiB"}, ErrSizeMismatch},
+		{"Missing unit is bytes", Options{IsGreaterThanStr: "1535", IsLessThanStr: "1537"}, nil},
+		{"Invalid size", Options{IsLessThanStr: "ten megabytes"}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(path, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("File() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileSizeRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "block.bin")
+	if err := os.WriteFile(path, make([]byte, 1024), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"MinSize equal", Options{MinSize: 1024}, false},
+		{"IsGreaterThan equal", Options{IsGreaterThan: 1024}, true},
+		{"MaxSize equal", Options{MaxSize: 1024}, false},
+		{"IsLessThan equal", Options{IsLessThan: 1024}, true},
+		{"Exact range", Options{MinSize: 1024, MaxSize: 1024}, false},
+		{"Inside range", Options{MinSize: 1, MaxSize: 4096}, false},
+		{"Below range", Options{MinSize: 1025, MaxSize: 4096}, true},
+		{"Above range", Options{MinSize: 1, MaxSize: 1023}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrSizeMismatch) {
+				t.Errorf("File() error = %v, want ErrSizeMismatch", err)
+			}
+		})
+	}
+}
+
+func TestFileRequireBaseDirs(t *testing.T) {
+	root := t.TempDir()
+	bases := []string{filepath.Join(root, "data"), filepath.Join(root, "uploads"), filepath.Join(root, "work")}
+	for _, base := range append(bases, filepath.Join(root, "etc")) {
+		if err := os.Mkdir(base, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	inSecond := filepath.Join(bases[1], "avatar.png")
+	outside := filepath.Join(root, "etc", "passwd")
+	for _, path := range []string{inSecond, outside} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Inside the second of three", inSecond, Options{RequireBaseDirs: bases}, false},
+		{"Outside all three", outside, Options{RequireBaseDirs: bases}, true},
+		{"Union with RequireBaseDir", outside, Options{RequireBaseDir: filepath.Join(root, "etc"), RequireBaseDirs: bases}, false},
+		{"Outside the union", outside, Options{RequireBaseDir: bases[0], RequireBaseDirs: bases[1:]}, true},
+		{"Traversal", filepath.Join(bases[0], "..", "etc", "passwd"), Options{RequireBaseDirs: bases}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			var badBase *ErrCheckBadBaseDirs
+			if !errors.As(err, &badBase) || !errors.Is(err, ErrBadBaseDir) {
+				t.Fatalf("File() error = %v, want *ErrCheckBadBaseDirs", err)
+			}
+			if len(badBase.BaseDirs) != len(bases) {
+				t.Errorf("BaseDirs = %v, want all of %v", badBase.BaseDirs, bases)
+			}
+			for _, base := range bases {
+				if !strings.Contains(err.Error(), base) {
+					t.Errorf("File() error = %q, want it to list %s", err, base)
+				}
+			}
+		})
+	}
+
+	if err := File(inSecond, Options{RequireBaseDirs: []string{bases[0], ""}}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("File() with an empty base error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestFileForbidBaseDirs(t *testing.T) {
+	root := t.TempDir()
+	denied := []string{filepath.Join(root, "etc"), filepath.Join(root, "proc")}
+	for _, dir := range append(denied, filepath.Join(root, "uploads")) {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	secret := filepath.Join(denied[1], "environ")
+	safe := filepath.Join(root, "uploads", "report.csv")
+	for _, path := range []string{secret, safe} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	link := filepath.Join(root, "uploads", "report-link.csv")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantBaseDir string // wantBaseDir is the denied directory ErrCheckForbiddenBaseDir names, "" when File passes
+	}{
+		{"Inside a denied directory", secret, denied[1]},
+		{"Safely outside", safe, ""},
+		{"Symlink into a denied directory", link, denied[1]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, Options{Exists: true, ForbidBaseDirs: denied})
+			if tt.wantBaseDir == "" {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			var forbidden *ErrCheckForbiddenBaseDir
+			if !errors.As(err, &forbidden) || forbidden.BaseDir != tt.wantBaseDir || !errors.Is(err, ErrBadBaseDir) {
+				t.Errorf("File() error = %v, want *ErrCheckForbiddenBaseDir naming %s", err, tt.wantBaseDir)
+			}
+		})
+	}
+
+	if err := File(safe, Options{ForbidBaseDirs: []string{""}}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("File() with an empty denied directory error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestFilePermPredicate(t *testing.T) {
+	dir := t.TempDir()
+	plainFile := filepath.Join(dir, "plain.txt")
+	scriptFile := filepath.Join(dir, "script.sh")
+	if err := os.WriteFile(plainFile, []byte("plain"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(scriptFile, []byte("#!/bin/sh"), 0744); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(scriptFile, 0744); err != nil {
+		t.Fatalf("Failed to chmod test file: %v", err)
+	}
+
+	errOwnerExec := errors.New("owner execute bit must be clear")
+	noOwnerExec := func(mode os.FileMode) error {
+		if mode&0100 != 0 {
+			return errOwnerExec
+		}
+		return nil
+	}
+
+	if err := File(plainFile, Options{PermPredicate: noOwnerExec}); err != nil {
+		t.Errorf("File() error = %v", err)
+	}
+	if err := File(scriptFile, Options{PermPredicate: noOwnerExec}); !errors.Is(err, errOwnerExec) {
+		t.Errorf("File() error = %v, want the predicate's error", err)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Zero options", Options{}, false},
+		{"Consistent bounds", Options{IsGreaterThan: 1, IsSize: 5, IsLessThan: 10, NonEmpty: true}, false},
+		{"Consistent permissions", Options{MorePermissiveThan: 0600, LessPermissiveThan: 0644, RequireWrite: true}, false},
+		{"NonEmpty and MustBeEmpty", Options{NonEmpty: true, MustBeEmpty: true}, true},
+		{"Negative IsSize", Options{IsSize: -1}, true},
+		{"Negative IsLessThan", Options{IsLessThan: -1}, true},
+		{"MustBeEmpty and IsSize", Options{MustBeEmpty: true, IsSize: 10}, true},
+		{"MustBeEmpty and IsGreaterThan", Options{MustBeEmpty: true, IsGreaterThan: 10}, true},
+		{"NonEmpty and IsLessThan 1", Options{NonEmpty: true, IsLessThan: 1}, true},
+		{"IsSize and IsLessThan", Options{IsSize: 10, IsLessThan: 10}, true},
+		{"IsSize and IsGreaterThan", Options{IsSize: 10, IsGreaterThan: 10}, true},
+		{"IsGreaterThan and IsLessThan", Options{IsGreaterThan: 10, IsLessThan: 11}, true},
+		{"Single size MinSize and MaxSize", Options{MinSize: 10, IsSize: 10, MaxSize: 10}, false},
+		{"Negative MinSize", Options{MinSize: -1}, true},
+		{"MinSize above MaxSize", Options{MinSize: 11, MaxSize: 10}, true},
+		{"MustBeEmpty and MinSize", Options{MustBeEmpty: true, MinSize: 1}, true},
+		{"IsSize above MaxSize", Options{IsSize: 11, MaxSize: 10}, true},
+		{"ReadOnly and RequireWrite", Options{ReadOnly: true, RequireWrite: true}, true},
+		{"MorePermissiveThan and LessPermissiveThan", Options{MorePermissiveThan: 0644, LessPermissiveThan: 0600}, true},
+		{"ReadOnly and MorePermissiveThan", Options{ReadOnly: true, MorePermissiveThan: 0600}, true},
+		{"RequireWrite and LessPermissiveThan", Options{RequireWrite: true, LessPermissiveThan: 0444}, true},
+		{"WriteOnly and MorePermissiveThan", Options{WriteOnly: true, MorePermissiveThan: 0400}, true},
+		{"ReadOnly and WriteOnly", Options{ReadOnly: true, WriteOnly: true}, true},
+		{"WriteOnly and LessPermissiveThan", Options{WriteOnly: true, LessPermissiveThan: 0555}, true},
+		{"Unknown RequireAccess", Options{RequireAccess: 1 << 5}, true},
+		{"Unknown RequireExecutableBy", Options{RequireExecutableBy: 1 << 3}, true},
+		{"RequireExecutable and LessPermissiveThan", Options{RequireExecutable: true, LessPermissiveThan: 0644}, true},
+		{"RequireExecutableBy and LessPermissiveThan", Options{RequireExecutableBy: ByOther, LessPermissiveThan: 0750}, true},
+		{"Executable within LessPermissiveThan", Options{RequireExecutableBy: ByOwner | ByGroup, LessPermissiveThan: 0750}, false},
+		{"Consistent time window", Options{ModifiedAfter: time.Unix(100, 0), ModifiedBefore: time.Unix(200, 0)}, false},
+		{"ModifiedAfter without ModifiedBefore", Options{ModifiedAfter: time.Unix(100, 0)}, false},
+		{"ModifiedAfter after ModifiedBefore", Options{ModifiedAfter: time.Unix(200, 0), ModifiedBefore: time.Unix(100, 0)}, true},
+		{"CreatedAfter after CreatedBefore", Options{CreatedAfter: time.Unix(200, 0), CreatedBefore: time.Unix(100, 0)}, true},
+		{"Consistent ages", Options{MinAge: time.Minute, MaxAge: time.Hour}, false},
+		{"Negative MaxAge", Options{MaxAge: -time.Hour}, true},
+		{"MinAge more than MaxAge", Options{MinAge: time.Hour, MaxAge: time.Minute}, true},
+		{"RequireType with mode checks", Options{RequireType: Socket, IsFileMode: os.ModeSocket | 0755, RequireOwner: "0"}, false},
+		{"Unknown RequireType", Options{RequireType: 42}, true},
+		{"RequireType and a content check", Options{RequireType: FIFO, RequireSHA256: emptySHA256}, true},
+		{"RequireType and Create", Options{RequireType: FIFO, Create: Create{Kind: IfNotExists, OpenFlag: os.O_CREATE}}, true},
+	}
+	path := filepath.Join(t.TempDir(), "missing.txt")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Validate() error = %v, want ErrInvalidOptions", err)
+			}
+			if err := File(path, tt.opts); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("File() error = %v, want ErrInvalidOptions before the missing file is noticed", err)
+			}
+		})
+	}
+}
+
+func TestFileAll(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(path, []byte("twelve bytes"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	t.Run("Collects every failure", func(t *testing.T) {
+		errs := FileAll(path, Options{
+			RequireExt:     ".csv",      // fails
+			RequireBaseDir: dir,         // passes
+			IsLessThan:     10,          // fails
+			ReadOnly:       true,        // fails, 0644 has write bits
+			RequireOwner:   "99999",     // fails
+			RequirePrefix:  "report",    // passes
+			RequireSHA256:  emptySHA256, // fails
+		})
+		if len(errs) != 5 {
+			t.Fatalf("FileAll() returned %d errors, want 5: %v", len(errs), errs)
+		}
+		if !strings.Contains(errs[0].Error(), "extension") {
+			t.Errorf("errs[0] = %v, want extension failure", errs[0])
+		}
+		if !strings.Contains(errs[1].Error(), "not less than") {
+			t.Errorf("errs[1] = %v, want size failure", errs[1])
+		}
+		var checksumErr *ErrCheckBadChecksum
+		if !errors.As(errs[2], &checksumErr) {
+			t.Errorf("errs[2] = %T, want *ErrCheckBadChecksum", errs[2])
+		}
+		var permErr *ErrCheckOpenPermissions
+		if !errors.As(errs[3], &permErr) {
+			t.Errorf("errs[3] = %T, want *ErrCheckOpenPermissions", errs[3])
+		}
+		var ownerErr *ErrCheckBadOwner
+		if !errors.As(errs[4], &ownerErr) {
+			t.Errorf("errs[4] = %T, want *ErrCheckBadOwner", errs[4])
+		}
+		if err := File(path, Options{RequireExt: ".csv", IsLessThan: 10, ReadOnly: true}); err == nil || err.Error() != errs[0].Error() {
+			t.Errorf("File() error = %v, want the first FileAll error", err)
+		}
+	})
+
+	t.Run("Passing file", func(t *testing.T) {
+		if errs := FileAll(path, Options{RequireExt: ".txt", IsSize: 12}); errs != nil {
+			t.Errorf("FileAll() = %v, want nil", errs)
+		}
+	})
+
+	t.Run("Stat failure short-circuits", func(t *testing.T) {
+		errs := FileAll(filepath.Join(dir, "missing.txt"), Options{Exists: true, RequireExt: ".csv", IsLessThan: 10})
+		if len(errs) != 1 {
+			t.Errorf("FileAll() = %v, want exactly one error", errs)
+		}
+	})
+}
+
+func TestFileContext(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	t.Run("Cancelled before the filesystem is touched", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := FileContext(ctx, filepath.Join(dir, "missing.txt"), Options{Exists: true})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("FileContext() error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("1ns timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+		err := FileContext(ctx, path, Options{RequireSHA256: emptySHA256})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("FileContext() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("Cancelled between checks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		cancelling := func(os.FileMode) error {
+			cancel()
+			return nil
+		}
+		err := FileContext(ctx, path, Options{PermPredicate: cancelling, RequireOwner: "99999"})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("FileContext() error = %v, want context.Canceled before RequireOwner runs", err)
+		}
+	})
+
+	t.Run("Live context", func(t *testing.T) {
+		if err := FileContext(context.Background(), path, Options{RequireExt: ".txt"}); err != nil {
+			t.Errorf("FileContext() error = %v", err)
+		}
+	})
+}
+
+// stuckFS is an fs.FS whose files block in Read until release is closed, like a read on a hung NFS mount; Stat
+// answers at once
+type stuckFS struct {
+	fstest.MapFS
+	release chan struct{}
+}
+
+type stuckFile struct {
+	fs.File
+	release chan struct{}
+}
+
+func (fsys stuckFS) Open(name string) (fs.File, error) {
+	f, err := fsys.MapFS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return stuckFile{f, fsys.release}, nil
+}
+
+func (f stuckFile) Read(p []byte) (int, error) {
+	<-f.release
+	return f.File.Read(p)
+}
+
+func TestFileTimeout(t *testing.T) {
+	mapFS := fstest.MapFS{"data.csv": &fstest.MapFile{Data: []byte("a,b\n1,2\n")}}
+	stuck := stuckFS{mapFS, make(chan struct{})}
+	defer close(stuck.release)
+
+	tests := []struct {
+		name    string
+		fsys    fs.FS
+		opts    Options
+		wantErr error
+	}{
+		{"Stuck checksum", stuck, Options{Timeout: 20 * time.Millisecond, RequireSHA256: emptySHA256}, context.DeadlineExceeded},
+		{"Stuck line count", stuck, Options{Timeout: 20 * time.Millisecond, IsSize: 8, MinLines: 1}, context.DeadlineExceeded},
+		{"Stat checks stay synchronous", stuck, Options{Timeout: 20 * time.Millisecond, IsSize: 8, RequireExt: ".csv"}, nil},
+		{"Fast reads", mapFS, Options{Timeout: time.Minute, RequireValidUTF8: true, MinLines: 2, MaxLines: 2}, nil},
+		{"Mismatch within the timeout", mapFS, Options{Timeout: time.Minute, RequireContentRegex: "^x"}, ErrContentMismatch},
+		{"Negative", mapFS, Options{Timeout: -time.Second}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			err := FileFS(tt.fsys, "data.csv", tt.opts)
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Fatalf("FileFS() took %s", elapsed)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("FileFS() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FileFS() error = %v, want %v", err, tt.wantErr)
+			}
+			var timeout *ErrCheckTimeout
+			if errors.As(err, &timeout) && (timeout.Path != "data.csv" || !contentChecks[timeout.Op]) {
+				t.Errorf("ErrCheckTimeout = %+v, want a content check on data.csv", timeout)
+			}
+		})
+	}
+
+	t.Run("Inspect", func(t *testing.T) {
+		opts := Options{Timeout: 20 * time.Millisecond, RequireSHA256: emptySHA256, RequireExt: ".csv"}
+		start := time.Now()
+		report, err := inspect(context.Background(), stuck, "data.csv", opts)
+		if elapsed := time.Since(start); elapsed > 10*time.Second {
+			t.Fatalf("inspect() took %s", elapsed)
+		}
+		if err != nil {
+			t.Fatalf("inspect() error = %v", err)
+		}
+		want := map[string]bool{"RequireExt": true, "RequireSHA256": false}
+		for _, c := range report.Checks {
+			if passed, ok := want[c.Name]; !ok || c.Passed != passed {
+				t.Errorf("inspect() check %+v, want %v", c, want)
+			}
+		}
+		if failed := report.Failed(); len(failed) != 1 || !strings.Contains(failed[0].Detail, "timed out") {
+			t.Errorf("inspect() failed checks = %+v, want RequireSHA256 timed out", failed)
+		}
+	})
+}
+
+func TestFileSizeSidecar(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		return path
+	}
+	artifact := write("artifact.bin", make([]byte, 2048))
+	write("artifact.bin.size", []byte("2048\n"))
+	withUnits := write("units.bin", make([]byte, 2048))
+	write("units.bin.size", []byte("2KB"))
+	mismatched := write("mismatch.bin", make([]byte, 10))
+	write("mismatch.bin.size", []byte("11"))
+	malformed := write("malformed.bin", make([]byte, 10))
+	write("malformed.bin.size", []byte("ten bytes"))
+	orphan := write("orphan.bin", make([]byte, 10))
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"Matching sidecar", artifact, false},
+		{"Matching sidecar with units", withUnits, false},
+		{"Mismatching sidecar", mismatched, true},
+		{"Malformed sidecar", malformed, true},
+		{"Missing sidecar", orphan, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, Options{SizeSidecarExt: ".size"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var sizeErr *ErrCheckSidecarSize
+	if err := File(mismatched, Options{SizeSidecarExt: ".size"}); !errors.As(err, &sizeErr) || sizeErr.Expected != 11 {
+		t.Errorf("File() error = %v, want ErrCheckSidecarSize", err)
+	}
+	var malformedErr *ErrCheckMalformedSidecar
+	err := File(malformed, Options{SizeSidecarExt: ".size"})
+	if !errors.As(err, &malformedErr) || malformedErr.Sidecar != malformed+".size" || !errors.Is(err, ErrContentMismatch) {
+		t.Errorf("File() error = %v, want ErrCheckMalformedSidecar", err)
+	}
+	if code := common.CodeOf(err); code != common.CodeContentMismatch {
+		t.Errorf("CodeOf() = %v, want CodeContentMismatch", code)
+	}
+	var missingErr *ErrCheckMissingSidecar
+	if err := File(orphan, Options{SizeSidecarExt: ".size"}); !errors.As(err, &missingErr) {
+		t.Errorf("File() error = %v, want ErrCheckMissingSidecar", err)
+	}
+}
+
+func TestFileSentinelErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		opts Options
+		want error
+	}{
+		{"Missing file", filepath.Join(dir, "missing.txt"), Options{Exists: true}, ErrDoesNotExist},
+		{"Directory", dir, Options{Exists: true}, ErrNotRegularFile},
+		{"Size", path, Options{IsSize: 10}, ErrSizeMismatch},
+		{"Empty", path, Options{MustBeEmpty: true}, ErrSizeMismatch},
+		{"Time", path, Options{ModifiedBefore: time.Now().Add(-time.Hour)}, ErrTimeMismatch},
+		{"Prefix", path, Options{RequirePrefix: "invoice"}, ErrNameMismatch},
+		{"Extension", path, Options{RequireExt: ".csv"}, ErrNameMismatch},
+		{"Base dir", path, Options{RequireBaseDir: filepath.Join(dir, "other")}, ErrBadBaseDir},
+		{"File mode", path, Options{IsFileMode: 0600}, ErrPermissionMismatch},
+		{"Read only", path, Options{ReadOnly: true}, ErrPermissionMismatch},
+		{"Owner", path, Options{RequireOwner: "nobody-checkfs"}, ErrOwnerMismatch},
+		{"Group", path, Options{RequireGroup: "nobody-checkfs"}, ErrGroupMismatch},
+		{"Checksum", path, Options{RequireSHA256: emptySHA256}, ErrContentMismatch},
+		{"Invalid options", path, Options{NonEmpty: true, MustBeEmpty: true}, ErrInvalidOptions},
+		{"Invalid Blake2b size", path, Options{ExpectedBlake2b: "00", Blake2bSize: 65}, ErrInvalidOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("File() error = %v, want errors.Is(err, %v)", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("Message is unchanged", func(t *testing.T) {
+		err := File(path, Options{IsSize: 10})
+		if want := "incorrect file size for " + path + ": expected 10, got 5"; err == nil || err.Error() != want {
+			t.Errorf("File() error = %q, want %q", err, want)
+		}
+	})
+}
+
+func TestFileMaxSymlinkComponents(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "releases", "v2"), 0755); err != nil {
+		t.Fatalf("Failed to create test directories: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "releases", "v2", "app.bin"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "releases"), filepath.Join(dir, "srv")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("v2", filepath.Join(dir, "releases", "current")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	// the temp dir itself may sit behind symlinks (e.g. /var on macOS), so count relative to it
+	baseline, err := common.SymlinkComponents(dir)
+	if err != nil {
+		t.Fatalf("SymlinkComponents() error = %v", err)
+	}
+	path := filepath.Join(dir, "srv", "current", "app.bin")
+
+	tests := []struct {
+		name    string
+		path    string
+		max     int
+		wantErr bool
+	}{
+		{"Two symlinked directories within limit", path, baseline + 2, false},
+		{"Two symlinked directories over limit", path, baseline + 1, true},
+		{"Real path within tight limit", filepath.Join(dir, "releases", "v2", "app.bin"), baseline + 1, false},
+		{"Zero is unset", path, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, Options{MaxSymlinkComponents: tt.max})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrSymlinkMismatch) {
+				t.Errorf("File() error = %v, want ErrSymlinkMismatch", err)
+			}
+		})
+	}
+}
+
+func TestFileRejectBrokenSymlink(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "app.conf")
+	valid := filepath.Join(dir, "current.conf")
+	broken := filepath.Join(dir, "stale.conf")
+	if err := os.WriteFile(regular, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Symlink("app.conf", valid); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink("gone.conf", broken); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr bool
+	}{
+		{"Valid symlink", valid, Options{Exists: true, RejectBrokenSymlink: true}, false},
+		{"Regular file", regular, Options{Exists: true, RejectBrokenSymlink: true}, false},
+		{"Missing path is not a symlink", filepath.Join(dir, "missing.conf"), Options{RejectBrokenSymlink: true}, false},
+		{"Broken symlink", broken, Options{RejectBrokenSymlink: true}, true},
+		{"Broken symlink with Exists", broken, Options{Exists: true, RejectBrokenSymlink: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			var brokenErr *ErrCheckBrokenSymlink
+			if !errors.As(err, &brokenErr) {
+				t.Fatalf("File() error = %v, want *ErrCheckBrokenSymlink", err)
+			}
+			if want := filepath.Join(dir, "gone.conf"); brokenErr.Target != want {
+				t.Errorf("Target = %s, want %s", brokenErr.Target, want)
+			}
+			if !errors.Is(err, ErrSymlinkMismatch) {
+				t.Errorf("File() error = %v, want ErrSymlinkMismatch", err)
+			}
+		})
+	}
+
+	t.Run("Create does not follow a broken symlink", func(t *testing.T) {
+		err := File(broken, Options{RejectBrokenSymlink: true, Create: Create{Kind: IfNotExists, OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644}})
+		if !errors.Is(err, ErrSymlinkMismatch) {
+			t.Errorf("File() error = %v, want ErrSymlinkMismatch", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "gone.conf")); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("symlink target was created: %v", err)
+		}
+	})
+}
+
+func TestFileNoFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "secret.key")
+	if err := os.WriteFile(target, []byte("x"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(target, 0600); err != nil {
+		t.Fatalf("Failed to chmod test file: %v", err)
+	}
+	link := filepath.Join(dir, "link.key")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	linkInfo, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("Lstat() error = %v", err)
+	}
+	linkPerm := linkInfo.Mode().Perm()
+	if linkPerm == 0600 {
+		t.Skipf("symlink mode %o matches its target", linkPerm)
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantFollow bool // wantFollow and wantNoFollow report whether File passes
+		wantLink   bool
+	}{
+		{"LessPermissiveThan target mode", Options{LessPermissiveThan: 0600}, true, false},
+		{"MorePermissiveThan link mode", Options{MorePermissiveThan: linkPerm}, false, true},
+		{"IsFileMode target", Options{IsFileMode: 0600}, true, false},
+		{"PermPredicate sees the symlink", Options{PermPredicate: func(mode os.FileMode) error {
+			if mode&os.ModeSymlink == 0 {
+				return errors.New("not a symlink")
+			}
+			return nil
+		}}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := File(link, tt.opts); (err == nil) != tt.wantFollow {
+				t.Errorf("File() following symlinks error = %v, want pass %v", err, tt.wantFollow)
+			}
+			tt.opts.NoFollowSymlinks = true
+			err := File(link, tt.opts)
+			if (err == nil) != tt.wantLink {
+				t.Errorf("File() with NoFollowSymlinks error = %v, want pass %v", err, tt.wantLink)
+			}
+			if err != nil && !errors.Is(err, ErrPermissionMismatch) {
+				t.Errorf("File() with NoFollowSymlinks error = %v, want ErrPermissionMismatch", err)
+			}
+		})
+	}
+
+	t.Run("Regular file", func(t *testing.T) {
+		if err := File(target, Options{NoFollowSymlinks: true, IsFileMode: 0600}); err != nil {
+			t.Errorf("File() error = %v", err)
+		}
+	})
+}
+
+func TestFileStrict(t *testing.T) {
+	// resolve the temp dir itself, which sits below a symlink on some systems (/var on macOS)
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	realDir := filepath.Join(dir, "real")
+	if err := os.Mkdir(realDir, 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	target := filepath.Join(realDir, "app.conf")
+	if err := os.WriteFile(target, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	via := filepath.Join(dir, "via")
+	if err := os.Symlink("real", via); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	leaf := filepath.Join(dir, "leaf.conf")
+	if err := os.Symlink(target, leaf); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	create := Create{Kind: IfNotExists, OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644}
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     Options
+		wantLink string // wantLink is the component ErrCheckSymlinkInPath names, "" when File passes
+	}{
+		{"Real path", target, Options{Exists: true, Strict: true}, ""},
+		{"Intermediate symlink without Strict", filepath.Join(via, "app.conf"), Options{Exists: true}, ""},
+		{"Intermediate symlink", filepath.Join(via, "app.conf"), Options{Exists: true, Strict: true}, via},
+		{"Leaf symlink", leaf, Options{Exists: true, Strict: true}, leaf},
+		{"Missing file through a symlink", filepath.Join(via, "missing.conf"), Options{Strict: true}, via},
+		{"Missing file", filepath.Join(realDir, "missing.conf"), Options{Strict: true}, ""},
+		{"Create through a symlink", filepath.Join(via, "new.conf"), Options{Strict: true, Create: create}, via},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			if tt.wantLink == "" {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			var linkErr *ErrCheckSymlinkInPath
+			if !errors.As(err, &linkErr) || linkErr.Link != tt.wantLink {
+				t.Fatalf("File() error = %v, want *ErrCheckSymlinkInPath naming %s", err, tt.wantLink)
+			}
+			if !errors.Is(err, ErrSymlinkMismatch) {
+				t.Errorf("File() error = %v, want ErrSymlinkMismatch", err)
+			}
+		})
+	}
+	if _, err := os.Lstat(filepath.Join(realDir, "new.conf")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Create ran through the symlink: %v", err)
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		path := filepath.Join(realDir, "new.conf")
+		if err := File(path, Options{Strict: true, Create: create}); err != nil {
+			t.Fatalf("File() error = %v", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Create did not create %s: %v", path, err)
+		}
+	})
+
+	t.Run("FileWithInfo", func(t *testing.T) {
+		info, err := os.Stat(target)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if err := FileWithInfo(target, info, Options{Strict: true}); err != nil {
+			t.Errorf("FileWithInfo() error = %v", err)
+		}
+		if err := FileWithInfo(filepath.Join(via, "app.conf"), info, Options{Strict: true}); !errors.Is(err, ErrSymlinkMismatch) {
+			t.Errorf("FileWithInfo() through a symlink error = %v, want ErrSymlinkMismatch", err)
+		}
+	})
+
+	t.Run("Inspect", func(t *testing.T) {
+		report, err := Inspect(target, Options{Strict: true, RequireExt: ".conf"})
+		if err != nil || !report.Passed() || report.Info == nil {
+			t.Errorf("Inspect() = %+v, %v, want every check passed", report, err)
+		}
+		for _, path := range []string{filepath.Join(via, "app.conf"), leaf} {
+			report, err := Inspect(path, Options{Strict: true, RequireExt: ".conf"})
+			if err != nil {
+				t.Fatalf("Inspect(%s) error = %v", path, err)
+			}
+			if failed := report.Failed(); len(report.Checks) != 1 || len(failed) != 1 || failed[0].Name != "Strict" {
+				t.Errorf("Inspect(%s) checks = %+v, want only Strict, failed", path, report.Checks)
+			}
+		}
+	})
+
+	t.Run("RequireType Symlink", func(t *testing.T) {
+		if err := File(leaf, Options{Strict: true, RequireType: Symlink}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("File() error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
+
+func TestFileForbidMetadataChangeAfterCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sealed.bin")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	opts := Options{ForbidMetadataChangeAfterCreate: true}
+
+	if _, _, err := common.GetBirthAndChangeTime(path); errors.Is(err, common.ErrBirthTimeUnsupported) {
+		if err := File(path, opts); !errors.Is(err, common.ErrBirthTimeUnsupported) {
+			t.Errorf("File() error = %v, want ErrBirthTimeUnsupported", err)
+		}
+		t.Skipf("birth time not available: %v", err)
+	}
+
+	if err := File(path, opts); err != nil {
+		t.Fatalf("File() on a fresh file error = %v, want nil", err)
+	}
+	time.Sleep(metadataChangeTolerance + 500*time.Millisecond)
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatalf("Failed to chmod test file: %v", err)
+	}
+	var changed *ErrCheckMetadataChanged
+	if err := File(path, opts); !errors.As(err, &changed) || !errors.Is(err, ErrTimeMismatch) {
+		t.Errorf("File() after chmod error = %v, want ErrCheckMetadataChanged", err)
+	}
+}
+
+func TestFileSingleStat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stat.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	stats := 0
+	defer func(original func(context.Context, fs.FS, string) (fs.FileInfo, error)) { statFS = original }(statFS)
+	statFS = func(ctx context.Context, fsys fs.FS, name string) (fs.FileInfo, error) {
+		stats++
+		return common.StatContext(ctx, fsys, name)
+	}
+
+	opts := Options{
+		Exists:             true,
+		CreatedBefore:      time.Now().Add(time.Hour),
+		ModifiedBefore:     time.Now().Add(time.Hour),
+		MorePermissiveThan: 0400,
+		LessPermissiveThan: 0777,
+		IsLessThan:         10,
+	}
+	if runtime.GOOS != "windows" {
+		uid, gid, err := common.GetOwnerAndGroup(path)
+		if err != nil {
+			t.Fatalf("GetOwnerAndGroup() error = %v", err)
+		}
+		opts.RequireOwner, opts.RequireGroup = uid, gid
+	}
+	if err := File(path, opts); err != nil {
+		t.Fatalf("File() error = %v", err)
+	}
+	if stats != 1 {
+		t.Errorf("File() stat'd %s %d times, want 1", path, stats)
+	}
+}
+
+func TestFileWithInfo(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{"a.txt": "hello", "b.log": "", "sub/c.txt": "hello, world"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	optionSets := []Options{
+		{Exists: true},
+		{RequireExt: ".txt", NonEmpty: true},
+		{IsLessThan: 6, RequirePrefix: "a"},
+		{MorePermissiveThan: 0400, LessPermissiveThan: 0644, ModifiedBefore: time.Now().Add(time.Hour)},
+		{MaxAge: time.Hour, RequireContent: []byte("hello")},
+	}
+
+	stats := 0
+	defer func(original func(context.Context, fs.FS, string) (fs.FileInfo, error)) { statFS = original }(statFS)
+	statFS = func(ctx context.Context, fsys fs.FS, name string) (fs.FileInfo, error) {
+		stats++
+		return common.StatContext(ctx, fsys, name)
+	}
+
+	walked := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		walked++
+		for _, opts := range optionSets {
+			before := stats
+			got := FileWithInfo(path, info, opts)
+			if stats != before {
+				t.Errorf("FileWithInfo(%s, %+v) stat'd %s, want no stat", path, opts, path)
+			}
+			want := File(path, opts)
+			if (got == nil) != (want == nil) || got != nil && got.Error() != want.Error() {
+				t.Errorf("FileWithInfo(%s, %+v) = %v, want %v as File returns", path, opts, got, want)
+			}
+		}
+		return nil
+	})
+	if err != nil || walked != 3 {
+		t.Fatalf("WalkDir() visited %d files, error = %v", walked, err)
+	}
+
+	if err := FileWithInfo(filepath.Join(root, "a.txt"), nil, Options{}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("FileWithInfo() without info error = %v, want ErrInvalidOptions", err)
+	}
+	dirInfo, err := os.Stat(filepath.Join(root, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := FileWithInfo(filepath.Join(root, "sub"), dirInfo, Options{}); !errors.Is(err, ErrNotRegularFile) {
+		t.Errorf("FileWithInfo() of a directory error = %v, want ErrNotRegularFile", err)
+	}
+}
+
+func BenchmarkFileStat(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "stat.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		b.Fatalf("Failed to create test file: %v", err)
+	}
+	opts := Options{MorePermissiveThan: 0400, LessPermissiveThan: 0777, CreatedBefore: time.Now().Add(time.Hour)}
+	if runtime.GOOS != "windows" {
+		opts.RequireOwner, opts.RequireGroup, _ = common.GetOwnerAndGroup(path)
+	}
+
+	// PathHelpers is what File used to do: one stat up front and another inside every helper
+	b.Run("PathHelpers", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = os.Stat(path)
+			_, _ = common.IsMorePermissiveThan(path, opts.MorePermissiveThan)
+			_, _ = common.IsLessPermissiveThan(path, opts.LessPermissiveThan)
+			_, _ = common.GetCreationTime(path)
+			_, _, _ = common.GetOwnerAndGroup(path)
+		}
+	})
+	b.Run("File", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = File(path, opts)
+		}
+	})
+}
+
+func TestFileTimeWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stamped")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	stamp := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, stamp, stamp); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"ModifiedAfter earlier", Options{ModifiedAfter: stamp.Add(-time.Hour)}, false},
+		{"ModifiedAfter exact", Options{ModifiedAfter: stamp}, false},
+		{"ModifiedAfter later", Options{ModifiedAfter: stamp.Add(time.Hour)}, true},
+		{"In window", Options{ModifiedAfter: stamp.Add(-time.Hour), ModifiedBefore: stamp.Add(time.Hour)}, false},
+		{"Too old for window", Options{ModifiedAfter: stamp.Add(time.Hour), ModifiedBefore: stamp.Add(2 * time.Hour)}, true},
+		{"Too new for window", Options{ModifiedAfter: stamp.Add(-2 * time.Hour), ModifiedBefore: stamp.Add(-time.Hour)}, true},
+		{"CreatedAfter earlier", Options{CreatedAfter: stamp.Add(-time.Hour)}, false},
+		{"CreatedAfter future", Options{CreatedAfter: time.Now().Add(time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrTimeMismatch) {
+				t.Errorf("File() error = %v, want ErrTimeMismatch", err)
+			}
+		})
+	}
+}
+
+func TestFileAccessTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cached.blob")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if disabled, err := common.AccessTimeDisabled(path); err != nil || disabled {
+		t.Skipf("access times not kept here: %v, %v", disabled, err)
+	}
+	// the access time is a month before the modification time, so only a check reading atime sees stamp
+	stamp := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, stamp, stamp.AddDate(0, 1, 0)); err != nil {
+		t.Fatalf("Failed to set times: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{"AccessedBefore later", Options{AccessedBefore: stamp.Add(time.Hour)}, nil},
+		{"AccessedBefore exact", Options{AccessedBefore: stamp}, nil},
+		{"AccessedBefore earlier", Options{AccessedBefore: stamp.Add(-time.Hour)}, ErrTimeMismatch},
+		{"AccessedAfter earlier", Options{AccessedAfter: stamp.Add(-time.Hour)}, nil},
+		{"AccessedAfter later", Options{AccessedAfter: stamp.Add(time.Hour)}, ErrTimeMismatch},
+		{"In window", Options{AccessedAfter: stamp.Add(-time.Hour), AccessedBefore: stamp.Add(time.Hour)}, nil},
+		{"Too new for window", Options{AccessedAfter: stamp.Add(-2 * time.Hour), AccessedBefore: stamp.Add(-time.Hour)}, ErrTimeMismatch},
+		{"Not accessed in the last 30 days", Options{AccessedBefore: time.Now().AddDate(0, 0, -30)}, nil},
+		{"AccessedAfter after AccessedBefore", Options{AccessedAfter: stamp.Add(time.Hour), AccessedBefore: stamp}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(path, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("File() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("FileInfo without stat data", func(t *testing.T) {
+		info := fstest.MapFS{"cached.blob": {Data: []byte("x")}}
+		fi, err := fs.Stat(info, "cached.blob")
+		if err != nil {
+			t.Fatalf("fs.Stat() error = %v", err)
+		}
+		if err := FileWithInfo(path, fi, Options{AccessedBefore: time.Now()}); !errors.Is(err, common.ErrAccessTimeUnsupported) {
+			t.Errorf("FileWithInfo() error = %v, want common.ErrAccessTimeUnsupported", err)
+		}
+	})
+}
+
+func TestFileAge(t *testing.T) {
+	dir := t.TempDir()
+	aged := func(name string, age time.Duration) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		stamp := time.Now().Add(-age)
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+		return path
+	}
+	// a minute of slack either side of the bound keeps the test independent of how long it takes to run
+	inside := aged("inside.txt", time.Hour-time.Minute)
+	outside := aged("outside.txt", time.Hour+time.Minute)
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr bool
+	}{
+		{"MaxAge just inside", inside, Options{MaxAge: time.Hour}, false},
+		{"MaxAge just outside", outside, Options{MaxAge: time.Hour}, true},
+		{"MinAge just inside", outside, Options{MinAge: time.Hour}, false},
+		{"MinAge just outside", inside, Options{MinAge: time.Hour}, true},
+		{"Age window", inside, Options{MinAge: time.Minute, MaxAge: time.Hour}, false},
+		{"MaxAge and ModifiedBefore both pass", inside, Options{MaxAge: time.Hour, ModifiedBefore: time.Now()}, false},
+		{"MaxAge passes, ModifiedBefore fails", inside, Options{MaxAge: time.Hour, ModifiedBefore: time.Now().Add(-2 * time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("File() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrTimeMismatch) {
+				t.Errorf("File() error = %v, want ErrTimeMismatch", err)
+			}
+		})
+	}
+}
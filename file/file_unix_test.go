@@ -0,0 +1,605 @@
+//go:build unix
+
+package file
+
+import (
+	"errors"
+	"math"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+func TestFileIDRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenant.dat")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	uid, gid := uint32(os.Getuid()), uint32(os.Getgid())
+	narrow := func(id uint32) [2]uint32 {
+		if id == math.MaxUint32 {
+			return [2]uint32{0, id - 1}
+		}
+		return [2]uint32{id + 1, math.MaxUint32}
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{"Owner in wide range", Options{OwnerUIDRange: [2]uint32{0, math.MaxUint32}}, nil},
+		{"Owner exact range", Options{OwnerUIDRange: [2]uint32{uid, uid}}, nil},
+		{"Owner outside narrow range", Options{OwnerUIDRange: narrow(uid)}, ErrOwnerMismatch},
+		{"Group in wide range", Options{GroupGIDRange: [2]uint32{0, math.MaxUint32}}, nil},
+		{"Group outside narrow range", Options{GroupGIDRange: narrow(gid)}, ErrGroupMismatch},
+		{"Inverted range", Options{OwnerUIDRange: [2]uint32{10, 5}}, ErrInvalidOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(path, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("File() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("File() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("Error reports actual uid", func(t *testing.T) {
+		var rangeErr *ErrCheckOwnerUIDRange
+		if err := File(path, Options{OwnerUIDRange: narrow(uid)}); !errors.As(err, &rangeErr) || rangeErr.Actual != uid {
+			t.Errorf("File() error = %v, want *ErrCheckOwnerUIDRange with Actual %d", err, uid)
+		}
+	})
+}
+
+func TestFileOwnerNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owned.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot resolve current user: %v", err)
+	}
+	group, err := user.LookupGroupId(strconv.Itoa(os.Getgid()))
+	if err != nil {
+		t.Skipf("cannot resolve current group: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr error
+	}{
+		{"Numeric owner", Options{RequireOwner: current.Uid}, nil},
+		{"Owner name", Options{RequireOwnerName: current.Username}, nil},
+		{"Wrong owner name", Options{RequireOwnerName: current.Username + "-nope"}, ErrOwnerMismatch},
+		{"Numeric group", Options{RequireGroup: group.Gid}, nil},
+		{"Group name", Options{RequireGroupName: group.Name}, nil},
+		{"Wrong group name", Options{RequireGroupName: group.Name + "-nope"}, ErrGroupMismatch},
+		{"Numeric and names together", Options{
+			RequireOwner:     current.Uid,
+			RequireGroup:     group.Gid,
+			RequireOwnerName: current.Username,
+			RequireGroupName: group.Name,
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(path, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("File() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("File() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileOwnedByCurrentUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mine.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := File(path, Options{RequireOwnedByCurrentUser: true, RequireOwnedByCurrentGroup: true}); err != nil {
+		t.Errorf("File() error = %v, want nil for a file the test created", err)
+	}
+
+	if os.Getuid() != 0 {
+		t.Skip("handing the file to another user needs root")
+	}
+	if err := os.Chown(path, 65534, 65534); err != nil {
+		t.Fatalf("Failed to chown test file: %v", err)
+	}
+	var badOwner *ErrCheckBadOwner
+	if err := File(path, Options{RequireOwnedByCurrentUser: true}); !errors.As(err, &badOwner) || badOwner.Expected != "0" {
+		t.Errorf("File() error = %v, want *ErrCheckBadOwner expecting uid 0", err)
+	}
+	if err := File(path, Options{RequireOwnedByCurrentGroup: true}); !errors.Is(err, ErrGroupMismatch) {
+		t.Errorf("File() error = %v, want ErrGroupMismatch", err)
+	}
+}
+
+func TestFileIsHardLinkCount(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "unique.bin")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := File(path, Options{IsHardLinkCount: 1}); err != nil {
+		t.Fatalf("File() on a file with one link error = %v, want nil", err)
+	}
+
+	if err := os.Link(path, filepath.Join(dir, "extra.bin")); err != nil {
+		t.Fatalf("Failed to create hard link: %v", err)
+	}
+	err := File(path, Options{IsHardLinkCount: 1})
+	var links *ErrCheckHardLinkCount
+	if !errors.As(err, &links) || !errors.Is(err, ErrIdentityMismatch) || links.Expected != 1 || links.Actual != 2 {
+		t.Errorf("File() after os.Link error = %v, want ErrCheckHardLinkCount expecting 1, got 2", err)
+	}
+	if errors.Is(err, ErrSymlinkMismatch) || common.CodeOf(err) != common.CodeIdentityMismatch {
+		t.Errorf("File() after os.Link error = %v, want only ErrIdentityMismatch", err)
+	}
+	if err := File(path, Options{IsHardLinkCount: 2}); err != nil {
+		t.Errorf("File() expecting two links error = %v, want nil", err)
+	}
+	if err := (Options{IsHardLinkCount: -1}).Validate(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Validate() with a negative IsHardLinkCount error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestFileExpectIdentity(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.conf")
+	if err := os.WriteFile(path, []byte("v1"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	dev, ino, err := common.FileIdentity(path)
+	if err != nil {
+		t.Fatalf("FileIdentity() error = %v", err)
+	}
+	opts := Options{ExpectDevice: dev, ExpectInode: ino}
+	if err := File(path, opts); err != nil {
+		t.Fatalf("File() on the original file error = %v, want nil", err)
+	}
+
+	// the replacement is created while the original still exists, so it cannot reuse its inode
+	replacement := filepath.Join(dir, "app.conf.tmp")
+	if err := os.WriteFile(replacement, []byte("v2"), 0644); err != nil {
+		t.Fatalf("Failed to create replacement: %v", err)
+	}
+	if err := os.Rename(replacement, path); err != nil {
+		t.Fatalf("Failed to rename replacement: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  Options
+		field string
+	}{
+		{"Inode", Options{ExpectInode: ino}, "inode"},
+		{"Device and inode", opts, "inode"},
+		{"Device", Options{ExpectDevice: dev + 1}, "device"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(path, tt.opts)
+			var changed *ErrCheckIdentityChanged
+			if !errors.As(err, &changed) || !errors.Is(err, ErrIdentityMismatch) || changed.Field != tt.field {
+				t.Errorf("File() error = %v, want ErrCheckIdentityChanged for the %s", err, tt.field)
+			}
+		})
+	}
+	if err := File(path, Options{ExpectDevice: dev}); err != nil {
+		t.Errorf("File() on the same device error = %v, want nil", err)
+	}
+}
+
+func TestFileOpenAccess(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret.key")
+	if err := os.WriteFile(path, []byte("key"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(path, 0); err != nil {
+		t.Fatalf("Failed to chmod test file: %v", err)
+	}
+
+	// Root opens the file whatever its mode bits say, so the mode checks and the open checks disagree; anyone else
+	// gets the permission error the open really returns
+	root := os.Geteuid() == 0
+	for _, opts := range []Options{{RequireReadable: true}, {RequireOpenWritable: true}} {
+		err := File(path, opts)
+		if root {
+			if err != nil {
+				t.Errorf("File(%+v) as root error = %v, want nil", opts, err)
+			}
+			continue
+		}
+		var pathErr *os.PathError
+		if !errors.Is(err, ErrPermissionMismatch) || !errors.Is(err, os.ErrPermission) || !errors.As(err, &pathErr) {
+			t.Errorf("File(%+v) error = %v, want ErrPermissionMismatch wrapping an *os.PathError", opts, err)
+		}
+	}
+	if root {
+		if err := File(path, Options{RequireWrite: true}); err == nil {
+			t.Errorf("File(RequireWrite) of a 0000 file error = nil, want the mode bits to fail it")
+		}
+	}
+
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatalf("Failed to chmod test file: %v", err)
+	}
+	if err := File(path, Options{RequireOpenWritable: true}); err != nil {
+		t.Fatalf("File(RequireOpenWritable) error = %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "key" {
+		t.Errorf("file after RequireOpenWritable = %q, %v, want it untouched", data, err)
+	}
+
+	fifo := filepath.Join(dir, "pipe")
+	if err := syscall.Mkfifo(fifo, 0644); err != nil {
+		t.Skipf("cannot create a named pipe: %v", err)
+	}
+	// Opening a named pipe blocks until its other end is opened, so it must be rejected before either check opens it
+	for _, opts := range []Options{{RequireReadable: true}, {RequireOpenWritable: true}} {
+		if err := File(fifo, opts); !errors.Is(err, ErrNotRegularFile) {
+			t.Errorf("File(%+v) of a named pipe error = %v, want ErrNotRegularFile", opts, err)
+		}
+	}
+}
+
+func TestFileSpecialBits(t *testing.T) {
+	dir := t.TempDir()
+	withMode := func(name string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Skipf("cannot chmod %s to %s: %v", name, mode, err)
+		}
+		if info, err := os.Stat(path); err != nil || info.Mode() != mode {
+			t.Skipf("the filesystem did not keep %s on %s", mode, name)
+		}
+		return path
+	}
+	plain := withMode("plain", 0755)
+	setuid := withMode("setuid", 0755|os.ModeSetuid)
+	setgid := withMode("setgid", 0755|os.ModeSetgid)
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"Plain file", plain, Options{ForbidSetuid: true, ForbidSetgid: true}, nil},
+		{"Setuid", setuid, Options{ForbidSetuid: true}, &ErrCheckSetuidSet{}},
+		{"Setuid allowed", setuid, Options{ForbidSetgid: true, LessPermissiveThan: 0755}, nil},
+		{"Setgid", setgid, Options{ForbidSetgid: true}, &ErrCheckSetgidSet{}},
+		{"Sticky missing", plain, Options{RequireSticky: true}, &ErrCheckStickyMissing{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("File() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrPermissionMismatch) {
+				t.Errorf("File() error = %v, want ErrPermissionMismatch", err)
+			}
+			if reflect.TypeOf(err) != reflect.TypeOf(tt.wantErr) {
+				t.Errorf("File() error = %T, want %T", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFileReadWriteOnly(t *testing.T) {
+	dir := t.TempDir()
+	withMode := func(mode os.FileMode) string {
+		path := filepath.Join(dir, mode.String())
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("Failed to chmod test file: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		mode    os.FileMode
+		opts    Options
+		wantErr error
+	}{
+		{"Write-only 0200", 0200, Options{WriteOnly: true}, nil},
+		{"Write-only 0000", 0000, Options{WriteOnly: true}, &ErrCheckNoWritePermissions{}},
+		{"Write-only 0600", 0600, Options{WriteOnly: true}, &ErrCheckReadPermissions{}},
+		{"Write-only 0400", 0400, Options{WriteOnly: true}, &ErrCheckReadPermissions{}},
+		{"Read-only 0400", 0400, Options{ReadOnly: true}, nil},
+		{"Read-only 0000", 0000, Options{ReadOnly: true}, &ErrCheckNoReadPermissions{}},
+		{"Read-only 0600", 0600, Options{ReadOnly: true}, &ErrCheckOpenPermissions{}},
+		{"Read-only 0200", 0200, Options{ReadOnly: true}, &ErrCheckOpenPermissions{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(withMode(tt.mode), tt.opts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("File() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrPermissionMismatch) {
+				t.Errorf("File() error = %v, want ErrPermissionMismatch", err)
+			}
+			if reflect.TypeOf(err) != reflect.TypeOf(tt.wantErr) {
+				t.Errorf("File() error = %T, want %T", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilePermissionDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.conf")
+	if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(path, 0666); err != nil {
+		t.Fatalf("Failed to chmod test file: %v", err)
+	}
+	if err := File(path, Options{LessPermissiveThan: 0644}); err == nil || !strings.Contains(err.Error(), "extra bits: 0022") {
+		t.Errorf("File() error = %v, want it to name the extra bits 0022", err)
+	}
+	if err := File(path, Options{MorePermissiveThan: 0755}); err == nil || !strings.Contains(err.Error(), "missing bits: 0111") {
+		t.Errorf("File() error = %v, want it to name the missing bits 0111", err)
+	}
+}
+
+func TestCreateEnsureValid(t *testing.T) {
+	dir := t.TempDir()
+	const content = "listen: 0.0.0.0:8080\n"
+	flag := os.O_CREATE | os.O_WRONLY
+	defer syscall.Umask(syscall.Umask(0077))
+
+	// existing creates path with data and mode, returning its os.FileInfo
+	existing := func(t *testing.T, name, data string, mode os.FileMode) (string, os.FileInfo) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), mode); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatalf("Failed to chmod test file: %v", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("os.Stat() error = %v", err)
+		}
+		return path, info
+	}
+	// check fails unless path holds data with mode, and is still the file before when before is not nil
+	check := func(t *testing.T, path, data string, mode os.FileMode, before os.FileInfo) {
+		t.Helper()
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("os.Stat() error = %v", err)
+		}
+		if info.Mode().Perm() != mode {
+			t.Errorf("mode = %o, want %o", info.Mode().Perm(), mode)
+		}
+		if before != nil && !os.SameFile(info, before) {
+			t.Errorf("%s was replaced, want it converged in place", path)
+		}
+		if got, err := os.ReadFile(path); err != nil || string(got) != data {
+			t.Errorf("contents = %q, %v, want %q", got, err, data)
+		}
+	}
+
+	t.Run("Missing file is created with the exact mode", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.yaml")
+		create := Create{Kind: EnsureValid, Path: path, FileMode: 0640, OpenFlag: flag, Content: []byte(content)}
+		if err := create.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		check(t, path, content, 0640, nil)
+	})
+
+	t.Run("Present and correct is left alone", func(t *testing.T) {
+		path, before := existing(t, "correct.yaml", content, 0640)
+		create := Create{Kind: EnsureValid, Path: path, FileMode: 0640, OpenFlag: flag, Content: []byte(content)}
+		if plan, err := create.Plan(); err != nil || len(plan) != 0 {
+			t.Errorf("Plan() = %q, %v, want nothing to do", plan, err)
+		}
+		if err := create.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		check(t, path, content, 0640, before)
+		if after, _ := os.Stat(path); !after.ModTime().Equal(before.ModTime()) {
+			t.Errorf("Run() rewrote a file that already matched")
+		}
+	})
+
+	t.Run("Present with the wrong mode is chmod'd", func(t *testing.T) {
+		path, before := existing(t, "mode.yaml", content, 0666)
+		create := Create{Kind: EnsureValid, Path: path, FileMode: 0600, OpenFlag: flag}
+		want := []string{"chmod file " + path + " to -rw-------"}
+		if plan, err := create.Plan(); err != nil || !reflect.DeepEqual(plan, want) {
+			t.Errorf("Plan() = %q, %v, want %q", plan, err, want)
+		}
+		if err := create.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		check(t, path, content, 0600, before)
+	})
+
+	t.Run("Present with the wrong size is truncated", func(t *testing.T) {
+		path, before := existing(t, "size.bin", "0123456789", 0600)
+		if err := (&Create{Kind: EnsureValid, Path: path, FileMode: 0600, OpenFlag: flag, Size: 4}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		check(t, path, "0123", 0600, before)
+	})
+
+	t.Run("Present with other contents is rewritten in place", func(t *testing.T) {
+		path, before := existing(t, "stale.yaml", "listen: 127.0.0.1:80\nworkers: 4\n", 0600)
+		if err := (&Create{Kind: EnsureValid, Path: path, FileMode: 0600, OpenFlag: flag, Content: []byte(content)}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		check(t, path, content, 0600, before)
+	})
+
+	t.Run("Through File", func(t *testing.T) {
+		path, _ := existing(t, "options.yaml", content, 0644)
+		opts := Options{Exists: true, IsFileMode: 0600, Create: Create{Kind: EnsureValid, FileMode: 0600, OpenFlag: flag}}
+		if err := File(path, opts); err != nil {
+			t.Errorf("File() error = %v, want the mode converged before it is checked", err)
+		}
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		sub := filepath.Join(dir, "sub")
+		if err := os.Mkdir(sub, 0700); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+		if err := (&Create{Kind: EnsureValid, Path: sub, FileMode: 0600, OpenFlag: flag}).Run(); !errors.Is(err, ErrNotRegularFile) {
+			t.Errorf("Run() over a directory error = %v, want ErrNotRegularFile", err)
+		}
+		reader := Create{Kind: EnsureValid, Path: filepath.Join(dir, "reader"), OpenFlag: flag, ContentReader: strings.NewReader(content)}
+		if err := reader.Run(); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Run() with ContentReader error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
+
+func TestFileRequireType(t *testing.T) {
+	dir := t.TempDir()
+	regular := filepath.Join(dir, "regular.txt")
+	if err := os.WriteFile(regular, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	fifo := filepath.Join(dir, "events.fifo")
+	if err := syscall.Mkfifo(fifo, 0600); err != nil {
+		t.Fatalf("Failed to create FIFO: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(regular, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	broken := filepath.Join(dir, "broken")
+	if err := os.Symlink(filepath.Join(dir, "missing"), broken); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	// unix socket paths are limited to about 100 bytes, more than t.TempDir() can promise
+	socketDir, err := os.MkdirTemp("", "sock")
+	if err != nil {
+		t.Fatalf("Failed to create socket directory: %v", err)
+	}
+	defer os.RemoveAll(socketDir)
+	socket := filepath.Join(socketDir, "app.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Failed to listen on unix socket: %v", err)
+	}
+	defer listener.Close()
+
+	tests := []struct {
+		name string
+		path string
+		opts Options
+		err  error
+	}{
+		{"FIFO", fifo, Options{RequireType: FIFO, IsFileMode: os.ModeNamedPipe | 0600, RequireOwnedByCurrentUser: true}, nil},
+		{"FIFO without RequireType", fifo, Options{}, ErrNotRegularFile},
+		{"Regular file as FIFO", regular, Options{RequireType: FIFO}, &ErrCheckWrongType{Want: FIFO, Got: 0}},
+		{"FIFO as Socket", fifo, Options{RequireType: Socket}, &ErrCheckWrongType{Want: Socket, Got: os.ModeNamedPipe}},
+		{"Socket", socket, Options{RequireType: Socket}, nil},
+		{"Char device", "/dev/null", Options{RequireType: CharDevice}, nil},
+		{"Char device as block device", "/dev/null", Options{RequireType: BlockDevice}, &ErrCheckWrongType{Want: BlockDevice, Got: os.ModeDevice | os.ModeCharDevice}},
+		{"Symlink", link, Options{RequireType: Symlink, IsFileMode: os.ModeSymlink | 0777}, nil},
+		{"Symlink target as regular", link, Options{RequireType: Regular}, nil},
+		{"Regular file as symlink", regular, Options{RequireType: Symlink}, &ErrCheckWrongType{Want: Symlink, Got: 0}},
+		{"Broken symlink", broken, Options{RequireType: Symlink}, nil},
+		{"Broken symlink rejected", broken, Options{RequireType: Symlink, RejectBrokenSymlink: true}, ErrSymlinkMismatch},
+		{"FIFO with a content check", fifo, Options{RequireType: FIFO, RequireReadable: true}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(tt.path, tt.opts)
+			switch want := tt.err.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+			case *ErrCheckWrongType:
+				var got *ErrCheckWrongType
+				if !errors.As(err, &got) || !errors.Is(err, ErrNotRegularFile) {
+					t.Fatalf("File() error = %v, want *ErrCheckWrongType", err)
+				}
+				if got.Path != tt.path || got.Want != want.Want || got.Got != want.Got {
+					t.Errorf("ErrCheckWrongType = %+v, want Path %s, Want %s and Got %v", got, tt.path, want.Want, want.Got)
+				}
+			default:
+				if !errors.Is(err, tt.err) {
+					t.Errorf("File() error = %v, want %v", err, tt.err)
+				}
+			}
+			want := tt.err
+			if _, ok := want.(*ErrCheckWrongType); ok {
+				want = ErrNotRegularFile
+			}
+			report, err := Inspect(tt.path, tt.opts)
+			if want == nil && (err != nil || !report.Passed()) {
+				t.Errorf("Inspect() = %v, %v, want every check passed", report, err)
+			} else if want != nil && !errors.Is(err, want) {
+				t.Errorf("Inspect() error = %v, want %v", err, want)
+			}
+		})
+	}
+}
+
+func TestCreateSparse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk.img")
+	size := int64(256 << 20)
+	if err := (&Create{Kind: IfNotExists, Path: path, OpenFlag: os.O_CREATE | os.O_WRONLY, FileMode: 0644, Size: size, Sparse: true}).Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		t.Skip("no block count available")
+	}
+	if allocated := int64(stat.Blocks) * 512; info.Size() != size || allocated >= size {
+		t.Errorf("size = %d with %d bytes allocated, want %d bytes and a hole", info.Size(), allocated, size)
+	}
+}
@@ -0,0 +1,132 @@
+package file
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FileType selects the kind of file RequireType expects at the path
+type FileType int8
+
+const (
+	// Regular is a regular file, what File requires when RequireType is unset
+	Regular FileType = iota
+
+	// FIFO is a named pipe, as made by mkfifo
+	FIFO
+
+	// Socket is a unix domain socket
+	Socket
+
+	// CharDevice is a character device such as /dev/null
+	CharDevice
+
+	// BlockDevice is a block device such as /dev/sda
+	BlockDevice
+
+	// Symlink is the symlink itself rather than its target; the other checks then run against the link too
+	Symlink
+)
+
+func (t FileType) String() string {
+	switch t {
+	case Regular:
+		return "regular"
+	case FIFO:
+		return "fifo"
+	case Socket:
+		return "socket"
+	case CharDevice:
+		return "char-device"
+	case BlockDevice:
+		return "block-device"
+	case Symlink:
+		return "symlink"
+	}
+	return fmt.Sprintf("FileType(%d)", int8(t))
+}
+
+// matches reports whether mode is of type t
+func (t FileType) matches(mode os.FileMode) bool {
+	switch t {
+	case Regular:
+		return mode.IsRegular()
+	case FIFO:
+		return mode&os.ModeNamedPipe != 0
+	case Socket:
+		return mode&os.ModeSocket != 0
+	case CharDevice:
+		return mode&os.ModeDevice != 0 && mode&os.ModeCharDevice != 0
+	case BlockDevice:
+		return mode&os.ModeDevice != 0 && mode&os.ModeCharDevice == 0
+	case Symlink:
+		return mode&os.ModeSymlink != 0
+	}
+	return false
+}
+
+// typeName names the type of file mode describes for ErrCheckWrongType
+func typeName(mode os.FileMode) string {
+	if mode.IsRegular() {
+		return "regular file"
+	}
+	for _, t := range []FileType{FIFO, Socket, CharDevice, BlockDevice, Symlink} {
+		if t.matches(mode) {
+			return t.String()
+		}
+	}
+	if mode.IsDir() {
+		return "directory"
+	}
+	return "irregular"
+}
+
+// contentChecks open the file, which blocks on a FIFO with no writer and means little for a device or socket, so
+// they need RequireType Regular
+var contentChecks = map[string]bool{
+	"SizeSidecarExt":           true,
+	"RequireSHA256":            true,
+	"ExpectedBlake2b":          true,
+	"RequireContent":           true,
+	"RequireContentRegex":      true,
+	"ForbidContentRegex":       true,
+	"RequireValidUTF8":         true,
+	"ForbidBOM":                true,
+	"RequireBOM":               true,
+	"RequireTrailingNewline":   true,
+	"ForbidTrailingNewline":    true,
+	"IsLineCount":              true,
+	"MinLines":                 true,
+	"MaxLines":                 true,
+	"CanonicalCodec":           true,
+	"RequireEncrypted":         true,
+	"RequireContentType":       true,
+	"RequireContentTypePrefix": true,
+	"RequireReadable":          true,
+	"RequireOpenWritable":      true,
+}
+
+// validateType rejects a RequireType that is unknown, or that is not Regular alongside Create or a content check,
+// naming every offending field
+func (opts Options) validateType() error {
+	if opts.RequireType < Regular || opts.RequireType > Symlink {
+		return fmt.Errorf("%w: unknown RequireType %s", ErrInvalidOptions, opts.RequireType)
+	}
+	if opts.RequireType == Regular {
+		return nil
+	}
+	var conflicts []string
+	for _, c := range checks {
+		if contentChecks[c.name] && c.enabled(&opts) {
+			conflicts = append(conflicts, c.name)
+		}
+	}
+	if opts.Create.Kind != NoAction {
+		conflicts = append(conflicts, "Create")
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: RequireType %s cannot be used with %s", ErrInvalidOptions, opts.RequireType, strings.Join(conflicts, ", "))
+	}
+	return nil
+}
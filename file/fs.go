@@ -0,0 +1,77 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// osOnlyChecks need ownership, timestamps or a real OS path that an fs.FS cannot provide
+var osOnlyChecks = map[string]bool{
+	"CreatedBefore":                   true,
+	"CreatedAfter":                    true,
+	"AccessedBefore":                  true,
+	"AccessedAfter":                   true,
+	"ForbidMetadataChangeAfterCreate": true,
+	"RequireBaseDir":                  true,
+	"RequireBaseDirs":                 true,
+	"ForbidBaseDirs":                  true,
+	"MaxSymlinkComponents":            true,
+	"RequireOpenWritable":             true,
+	"RequireAccess":                   true,
+	"IsHardLinkCount":                 true,
+	"ExpectDevice":                    true,
+	"ExpectInode":                     true,
+	"RequireOwner":                    true,
+	"RequireGroup":                    true,
+	"RequireOwnerName":                true,
+	"RequireGroupName":                true,
+	"RequireOwnedByCurrentUser":       true,
+	"RequireOwnedByCurrentGroup":      true,
+	"OwnerUIDRange":                   true,
+	"GroupGIDRange":                   true,
+}
+
+// FileFS performs the file checks against path inside fsys (embed.FS, fstest.MapFS, os.DirFS, ...) instead of the OS
+// filesystem. path must be a valid fs.FS path such as "config/app.yaml". Existence, size, content, name and mode checks
+// work; Options that need ownership, creation time or a real path, and Create, fail with ErrUnsupportedFS before fsys
+// is touched.
+func FileFS(fsys fs.FS, path string, opts Options) error {
+	if fsys == nil {
+		return fmt.Errorf("%w: FileFS requires a non-nil fs.FS", ErrInvalidOptions)
+	}
+	if failures := run(context.Background(), fsys, path, opts, false); len(failures) > 0 {
+		return failures[0].Err
+	}
+	return nil
+}
+
+// validateFS rejects Options that cannot be checked through an fs.FS, naming every offending field
+func (opts Options) validateFS() error {
+	var unsupported []string
+	for _, c := range checks {
+		if osOnlyChecks[c.name] && c.enabled(&opts) {
+			unsupported = append(unsupported, c.name)
+		}
+	}
+	if opts.RejectBrokenSymlink {
+		unsupported = append(unsupported, "RejectBrokenSymlink")
+	}
+	if opts.NoFollowSymlinks {
+		unsupported = append(unsupported, "NoFollowSymlinks")
+	}
+	if opts.Strict {
+		unsupported = append(unsupported, "Strict")
+	}
+	if opts.RequireType == Symlink {
+		unsupported = append(unsupported, "RequireType")
+	}
+	if opts.Create.Kind != NoAction {
+		unsupported = append(unsupported, "Create")
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFS, strings.Join(unsupported, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package file
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestFileFS(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config", "app.yaml"), []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	filesystems := map[string]fs.FS{
+		"MapFS": fstest.MapFS{
+			"config/app.yaml": {Data: []byte("port: 8080\n"), Mode: 0644, ModTime: time.Now()},
+		},
+		"DirFS": os.DirFS(dir),
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"Exists", "config/app.yaml", Options{Exists: true}, nil},
+		{"Missing", "config/missing.yaml", Options{Exists: true}, ErrDoesNotExist},
+		{"Directory", "config", Options{Exists: true}, ErrNotRegularFile},
+		{"Size", "config/app.yaml", Options{IsSize: 11, IsLessThan: 100, NonEmpty: true}, nil},
+		{"Wrong size", "config/app.yaml", Options{IsSize: 12}, ErrSizeMismatch},
+		{"Extension and prefix", "config/app.yaml", Options{RequireExts: []string{".yml", ".yaml"}, RequirePrefix: "app"}, nil},
+		{"Wrong extension", "config/app.yaml", Options{RequireExt: ".json"}, ErrNameMismatch},
+		{"Mode", "config/app.yaml", Options{IsFileMode: 0644, MorePermissiveThan: 0444, LessPermissiveThan: 0644}, nil},
+		{"Read only", "config/app.yaml", Options{ReadOnly: true}, ErrPermissionMismatch},
+		{"Opens for reading", "config/app.yaml", Options{RequireReadable: true}, nil},
+		{"Opening for writing is unsupported", "config/app.yaml", Options{RequireOpenWritable: true}, ErrUnsupportedFS},
+		{"Access is unsupported", "config/app.yaml", Options{RequireAccess: AccessRead}, ErrUnsupportedFS},
+		{"Content", "config/app.yaml", Options{RequireContent: []byte("port: 8080"), CompareTrimmed: true}, nil},
+		{"Checksum", "config/app.yaml", Options{RequireSHA256: emptySHA256}, ErrContentMismatch},
+		{"Owner is unsupported", "config/app.yaml", Options{RequireOwner: "0"}, ErrUnsupportedFS},
+		{"Access time is unsupported", "config/app.yaml", Options{AccessedBefore: time.Now()}, ErrUnsupportedFS},
+		{"Current user is unsupported", "config/app.yaml", Options{RequireOwnedByCurrentUser: true}, ErrUnsupportedFS},
+		{"Create is unsupported", "config/new.yaml", Options{Create: Create{Kind: IfNotExists}}, ErrUnsupportedFS},
+		{"Wrong type", "config/app.yaml", Options{RequireType: FIFO}, ErrNotRegularFile},
+		{"Symlink type is unsupported", "config/app.yaml", Options{RequireType: Symlink}, ErrUnsupportedFS},
+		{"Strict is unsupported", "config/app.yaml", Options{Strict: true}, ErrUnsupportedFS},
+	}
+
+	for fsName, fsys := range filesystems {
+		for _, tt := range tests {
+			t.Run(fsName+"/"+tt.name, func(t *testing.T) {
+				err := FileFS(fsys, tt.path, tt.opts)
+				if tt.wantErr == nil {
+					if err != nil {
+						t.Errorf("FileFS() error = %v, want nil", err)
+					}
+					return
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("FileFS() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+
+	t.Run("Unsupported fields are named", func(t *testing.T) {
+		err := FileFS(filesystems["MapFS"], "config/app.yaml", Options{CreatedBefore: time.Now(), RequireGroup: "0"})
+		if want := "unsupported on fs.FS: CreatedBefore, RequireGroup"; err == nil || err.Error() != want {
+			t.Errorf("FileFS() error = %v, want %q", err, want)
+		}
+	})
+
+	t.Run("Nil fs.FS", func(t *testing.T) {
+		if err := FileFS(nil, "config/app.yaml", Options{}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("FileFS() error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// Inspect runs every check opts configures against the file at path and reports each one as passed or failed, along
+// with the file's os.FileInfo and resolved owner and group. A failing check is recorded in the Report, not returned;
+// the error is only for a run that cannot check anything: invalid Options, a missing path, a path that is not a regular
+// file or of the type RequireType asks for, or a failed stat. Inspect never runs opts.Create. Passing checks carry no
+// Detail, so a clean report costs one CheckResult per configured check and nothing more. With Strict, a symlink in
+// path fails the Strict check and nothing else runs, since every other check would have to follow it; that report
+// has no Info, owner or group.
+func Inspect(path string, opts Options) (*common.Report, error) {
+	return InspectContext(context.Background(), path, opts)
+}
+
+// InspectContext is Inspect, returning ctx.Err() as soon as ctx is done
+func InspectContext(ctx context.Context, path string, opts Options) (*common.Report, error) {
+	return inspect(ctx, nil, path, opts)
+}
+
+// inspect is InspectContext against fsys, or the OS filesystem when fsys is nil
+func inspect(ctx context.Context, fsys fs.FS, path string, opts Options) (*common.Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if fsys != nil {
+		if err := opts.validateFS(); err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.Strict {
+		var link *ErrCheckSymlinkInPath
+		if err := strictPath(path); errors.As(err, &link) {
+			return &common.Report{Path: path, Checks: []common.CheckResult{{Name: "Strict", Detail: err.Error()}}}, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	info, err := statFor(opts)(ctx, fsys, path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, common.Errorf(ErrDoesNotExist, "file does not exist: %s", path)
+		}
+		return nil, fmt.Errorf("failed to stat file %s: %w", path, err)
+	}
+	if failure := checkType(path, info, opts); failure != nil {
+		return nil, failure.Err
+	}
+	if opts.RequireType == Symlink && opts.RejectBrokenSymlink && info.Mode()&fs.ModeSymlink != 0 {
+		if err := brokenSymlink(path); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &state{
+		ctx:   ctx,
+		fsys:  fsys,
+		path:  path,
+		info:  info,
+		opts:  &opts,
+		owner: &common.OwnerCache{Path: path, Info: info},
+	}
+	enabled := 0
+	for _, c := range checks {
+		if c.enabled(s.opts) {
+			enabled++
+		}
+	}
+	results := make([]common.CheckResult, 0, enabled)
+	for _, c := range checks {
+		if !c.enabled(s.opts) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result := common.CheckResult{Name: c.name, Passed: true}
+		if err := runCheck(s, c); err != nil {
+			result.Passed = false
+			result.Detail = err.Error()
+		}
+		results = append(results, result)
+	}
+	return common.NewReport(path, info, s.owner, results), nil
+}
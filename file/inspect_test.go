@@ -0,0 +1,116 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(path, []byte("hello\nworld\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		opts   Options
+		passed map[string]bool
+	}{
+		{"No checks", Options{}, map[string]bool{}},
+		{"All pass", Options{RequireExt: ".txt", IsSize: 12, MaxLines: 2, ModifiedBefore: time.Now().Add(time.Hour)}, map[string]bool{
+			"RequireExt": true, "IsSize": true, "MaxLines": true, "ModifiedBefore": true,
+		}},
+		{"Mixed", Options{RequireExt: ".csv", IsSize: 12, RequirePrefix: "nope", NonEmpty: true}, map[string]bool{
+			"RequireExt": false, "IsSize": true, "RequirePrefix": false, "NonEmpty": true,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := Inspect(path, tt.opts)
+			if err != nil {
+				t.Fatalf("Inspect() error = %v", err)
+			}
+			if report.Path != path || report.Info == nil || report.Info.Size() != 12 {
+				t.Errorf("Inspect() report = %+v, want path %s and its FileInfo", report, path)
+			}
+			got := make(map[string]bool, len(report.Checks))
+			for _, c := range report.Checks {
+				if _, dup := got[c.Name]; dup {
+					t.Errorf("Inspect() reported %s twice", c.Name)
+				}
+				got[c.Name] = c.Passed
+				if c.Passed != (c.Detail == "") {
+					t.Errorf("Inspect() check %+v, want a Detail exactly when it failed", c)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.passed) {
+				t.Errorf("Inspect() checks = %v, want %v", got, tt.passed)
+			}
+			wantPassed := true
+			for _, p := range tt.passed {
+				wantPassed = wantPassed && p
+			}
+			if report.Passed() != wantPassed {
+				t.Errorf("Passed() = %v, want %v", report.Passed(), wantPassed)
+			}
+			if len(report.Failed()) != len(FileAll(path, tt.opts)) {
+				t.Errorf("Failed() = %v, want one per FileAll error", report.Failed())
+			}
+		})
+	}
+}
+
+func TestInspectOwner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file owners are not numeric on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "owned.txt")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	report, err := Inspect(path, Options{})
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if want := strconv.Itoa(os.Getuid()); report.UID != want {
+		t.Errorf("Inspect() UID = %q, want the current user %s", report.UID, want)
+	}
+	if report.Owner == "" {
+		t.Errorf("Inspect() Owner is empty, want the current user's name")
+	}
+}
+
+func TestInspectErrors(t *testing.T) {
+	dir := t.TempDir()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{"Invalid options", context.Background(), dir, Options{NonEmpty: true, MustBeEmpty: true}, ErrInvalidOptions},
+		{"Missing", context.Background(), filepath.Join(dir, "missing"), Options{}, ErrDoesNotExist},
+		{"Directory", context.Background(), dir, Options{}, ErrNotRegularFile},
+		{"Canceled", canceled, dir, Options{}, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := InspectContext(tt.ctx, tt.path, tt.opts)
+			if !errors.Is(err, tt.wantErr) || report != nil {
+				t.Errorf("InspectContext() = %v, %v, want nil, %v", report, err, tt.wantErr)
+			}
+		})
+	}
+}
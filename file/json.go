@@ -0,0 +1,180 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// String returns the name of the CreateKind constant, such as "IfNotExists"
+func (k CreateKind) String() string {
+	switch k {
+	case NoAction:
+		return "NoAction"
+	case IfNotExists:
+		return "IfNotExists"
+	case IfExists:
+		return "IfExists"
+	case EnsureValid:
+		return "EnsureValid"
+	}
+	return fmt.Sprintf("CreateKind(%d)", int8(k))
+}
+
+// MarshalText encodes k as its constant name
+func (k CreateKind) MarshalText() ([]byte, error) {
+	switch k {
+	case NoAction, IfNotExists, IfExists, EnsureValid:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("%w: unknown CreateKind %d", ErrInvalidOptions, int8(k))
+}
+
+// UnmarshalText decodes a constant name such as "IfNotExists"; an empty string is NoAction
+func (k *CreateKind) UnmarshalText(text []byte) error {
+	for _, kind := range []CreateKind{NoAction, IfNotExists, IfExists, EnsureValid} {
+		if string(text) == kind.String() {
+			*k = kind
+			return nil
+		}
+	}
+	if len(text) == 0 {
+		*k = NoAction
+		return nil
+	}
+	return fmt.Errorf("%w: unknown CreateKind %q, want NoAction, IfNotExists, IfExists or EnsureValid", ErrInvalidOptions, text)
+}
+
+// MarshalText encodes f as its String name, such as "age"
+func (f EncryptionFormat) MarshalText() ([]byte, error) {
+	switch f {
+	case NoEncryption, Age, PGPArmor, PGPBinary:
+		return []byte(f.String()), nil
+	}
+	return nil, fmt.Errorf("%w: unknown EncryptionFormat %d", ErrInvalidOptions, int8(f))
+}
+
+// UnmarshalText decodes a String name such as "pgp-armor"; an empty string is NoEncryption
+func (f *EncryptionFormat) UnmarshalText(text []byte) error {
+	for _, format := range []EncryptionFormat{NoEncryption, Age, PGPArmor, PGPBinary} {
+		if string(text) == format.String() {
+			*f = format
+			return nil
+		}
+	}
+	if len(text) == 0 {
+		*f = NoEncryption
+		return nil
+	}
+	return fmt.Errorf("%w: unknown EncryptionFormat %q, want none, age, pgp-armor or pgp-binary", ErrInvalidOptions, text)
+}
+
+// MarshalText encodes t as its String name, such as "fifo"
+func (t FileType) MarshalText() ([]byte, error) {
+	switch t {
+	case Regular, FIFO, Socket, CharDevice, BlockDevice, Symlink:
+		return []byte(t.String()), nil
+	}
+	return nil, fmt.Errorf("%w: unknown FileType %d", ErrInvalidOptions, int8(t))
+}
+
+// UnmarshalText decodes a String name such as "char-device"; an empty string is Regular
+func (t *FileType) UnmarshalText(text []byte) error {
+	for _, fileType := range []FileType{Regular, FIFO, Socket, CharDevice, BlockDevice, Symlink} {
+		if string(text) == fileType.String() {
+			*t = fileType
+			return nil
+		}
+	}
+	if len(text) == 0 {
+		*t = Regular
+		return nil
+	}
+	return fmt.Errorf("%w: unknown FileType %q, want regular, fifo, socket, char-device, block-device or symlink", ErrInvalidOptions, text)
+}
+
+// createJSON and optionsJSON have the fields of Create and Options without their JSON methods, so those methods can
+// embed them and override the fields that need another encoding
+type (
+	createJSON  Create
+	optionsJSON Options
+)
+
+// MarshalJSON encodes create with FileMode as an octal string such as "0644" and Kind as a name such as
+// "IfNotExists". ContentReader is never encoded.
+func (create Create) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		createJSON
+		FileMode common.OctalMode
+	}{createJSON(create), common.OctalMode(create.FileMode)})
+}
+
+// UnmarshalJSON decodes what MarshalJSON encodes, leaving fields missing from data untouched
+func (create *Create) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*createJSON
+		FileMode common.OctalMode
+	}{(*createJSON)(create), common.OctalMode(create.FileMode)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	create.FileMode = os.FileMode(aux.FileMode)
+	return nil
+}
+
+// MarshalJSON encodes opts for policy files: modes as octal strings such as "0644", durations as strings such as "1h",
+// times as RFC 3339 and enums by name. PermPredicate and CanonicalCodec are code, not data, and are never encoded.
+func (opts Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		optionsJSON
+		IsFileMode         common.OctalMode
+		MorePermissiveThan common.OctalMode
+		LessPermissiveThan common.OctalMode
+		MaxAge             common.Duration
+		MinAge             common.Duration
+		Timeout            common.Duration
+	}{
+		optionsJSON(opts),
+		common.OctalMode(opts.IsFileMode),
+		common.OctalMode(opts.MorePermissiveThan),
+		common.OctalMode(opts.LessPermissiveThan),
+		common.Duration(opts.MaxAge),
+		common.Duration(opts.MinAge),
+		common.Duration(opts.Timeout),
+	})
+}
+
+// UnmarshalJSON decodes what MarshalJSON encodes, leaving fields missing from data untouched. A malformed mode,
+// duration or enum fails with an error wrapping ErrInvalidOptions that names the bad value.
+func (opts *Options) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*optionsJSON
+		IsFileMode         common.OctalMode
+		MorePermissiveThan common.OctalMode
+		LessPermissiveThan common.OctalMode
+		MaxAge             common.Duration
+		MinAge             common.Duration
+		Timeout            common.Duration
+	}{
+		(*optionsJSON)(opts),
+		common.OctalMode(opts.IsFileMode),
+		common.OctalMode(opts.MorePermissiveThan),
+		common.OctalMode(opts.LessPermissiveThan),
+		common.Duration(opts.MaxAge),
+		common.Duration(opts.MinAge),
+		common.Duration(opts.Timeout),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	opts.IsFileMode = os.FileMode(aux.IsFileMode)
+	opts.MorePermissiveThan = os.FileMode(aux.MorePermissiveThan)
+	opts.LessPermissiveThan = os.FileMode(aux.LessPermissiveThan)
+	opts.MaxAge = time.Duration(aux.MaxAge)
+	opts.MinAge = time.Duration(aux.MinAge)
+	opts.Timeout = time.Duration(aux.Timeout)
+	return nil
+}
@@ -0,0 +1,161 @@
+package file
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOptionsJSON(t *testing.T) {
+	stamp := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+	opts := Options{
+		CreatedBefore:                   stamp,
+		ModifiedBefore:                  stamp.Add(time.Hour),
+		CreatedAfter:                    stamp.Add(-time.Hour),
+		ModifiedAfter:                   stamp.Add(-2 * time.Hour),
+		MaxAge:                          36 * time.Hour,
+		MinAge:                          90 * time.Second,
+		Timeout:                         5 * time.Second,
+		IsLessThan:                      1 << 20,
+		IsSize:                          512,
+		IsGreaterThan:                   1,
+		IsLessThanStr:                   "1MB",
+		IsGreaterThanStr:                "1B",
+		RequireExt:                      ".yaml",
+		RequireExts:                     []string{".yaml", ".yml"},
+		RequirePrefix:                   "app",
+		RequireSuffix:                   "_final",
+		RequireOwner:                    "0",
+		RequireGroup:                    "0",
+		RequireOwnerName:                "root",
+		RequireGroupName:                "wheel",
+		OwnerUIDRange:                   [2]uint32{1000, 2000},
+		GroupGIDRange:                   [2]uint32{100, 200},
+		RequireBaseDir:                  "/etc",
+		RequireSHA256:                   strings.Repeat("a", 64),
+		ExpectedBlake2b:                 strings.Repeat("b", 64),
+		Blake2bSize:                     32,
+		RequireContent:                  []byte("key: value\n"),
+		CompareTrimmed:                  true,
+		RequireContentRegex:             "^key:",
+		ForbidContentRegex:              "password",
+		RequireValidUTF8:                true,
+		IsLineCount:                     1,
+		MinLines:                        1,
+		MaxLines:                        10,
+		SizeSidecarExt:                  ".size",
+		IsFileMode:                      0640,
+		MorePermissiveThan:              0400,
+		LessPermissiveThan:              0644,
+		IsBaseNameLen:                   10,
+		MaxSymlinkComponents:            2,
+		IsHardLinkCount:                 1,
+		ExpectDevice:                    64769,
+		ExpectInode:                     1234567,
+		RequireAccess:                   AccessRead | AccessExecute,
+		RequireExecutableBy:             ByOwner | ByGroup,
+		RequireEncrypted:                PGPArmor,
+		RequireContentType:              "text/plain",
+		RequireContentTypePrefix:        "text/",
+		RequireType:                     Socket,
+		NoFollowSymlinks:                true,
+		RequireWrite:                    true,
+		RequireExecutable:               true,
+		ForbidSetuid:                    true,
+		ForbidSetgid:                    true,
+		RequireSticky:                   true,
+		RequireReadable:                 true,
+		RequireOpenWritable:             true,
+		ReadOnly:                        true,
+		WriteOnly:                       true,
+		Exists:                          true,
+		RejectBrokenSymlink:             true,
+		ForbidMetadataChangeAfterCreate: true,
+		NonEmpty:                        true,
+		MustBeEmpty:                     true,
+		Create: Create{
+			Path:           "/etc/app.yaml",
+			Kind:           IfNotExists,
+			FileMode:       0600,
+			OpenFlag:       577,
+			Size:           11,
+			Atomic:         true,
+			Sync:           true,
+			DryRun:         true,
+			BackupDir:      "/var/backups",
+			Content:        []byte("key: value\n"),
+			RequireBaseDir: "/etc",
+		},
+	}
+
+	data, err := json.Marshal(opts)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, want := range []string{
+		`"IsFileMode":"0640"`, `"LessPermissiveThan":"0644"`, `"MaxAge":"36h0m0s"`, `"MinAge":"1m30s"`, `"Timeout":"5s"`,
+		`"CreatedBefore":"2024-06-01T12:30:00Z"`, `"Kind":"IfNotExists"`, `"FileMode":"0600"`, `"RequireEncrypted":"pgp-armor"`,
+		`"RequireAccess":"rx"`, `"RequireType":"socket"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("json.Marshal() = %s, want it to contain %s", data, want)
+		}
+	}
+	for _, unwanted := range []string{"PermPredicate", "CanonicalCodec", "ContentReader"} {
+		if strings.Contains(string(data), unwanted) {
+			t.Errorf("json.Marshal() = %s, want no %s", data, unwanted)
+		}
+	}
+
+	var got Options
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, opts) {
+		t.Errorf("round trip = %+v, want %+v", got, opts)
+	}
+}
+
+func TestOptionsJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"Mode not octal", `{"IsFileMode":"0999"}`, `"0999"`},
+		{"Mode as number", `{"MorePermissiveThan":420}`, "octal string"},
+		{"Bad duration", `{"MaxAge":"soon"}`, `"soon"`},
+		{"Bad CreateKind", `{"Create":{"Kind":"Sometimes"}}`, `"Sometimes"`},
+		{"Bad Create mode", `{"Create":{"FileMode":"rw-r--r--"}}`, `"rw-r--r--"`},
+		{"Bad EncryptionFormat", `{"RequireEncrypted":"rot13"}`, `"rot13"`},
+		{"Bad FileType", `{"RequireType":"door"}`, `"door"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts Options
+			err := json.Unmarshal([]byte(tt.data), &opts)
+			if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("json.Unmarshal() error = %v, want ErrInvalidOptions mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateKindText(t *testing.T) {
+	for _, kind := range []CreateKind{NoAction, IfNotExists, IfExists, EnsureValid} {
+		text, err := kind.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%d) error = %v", kind, err)
+		}
+		var got CreateKind
+		if err := got.UnmarshalText(text); err != nil || got != kind {
+			t.Errorf("UnmarshalText(%s) = %v, %v, want %v", text, got, err, kind)
+		}
+	}
+	if _, err := CreateKind(42).MarshalText(); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("MarshalText(42) error = %v, want ErrInvalidOptions", err)
+	}
+}
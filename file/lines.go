@@ -0,0 +1,90 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// countLines counts the lines of the file at path without loading it into memory. Every "\n" ends a line, so CRLF
+// endings count once, and a final line without a trailing newline still counts; an empty file has no lines. A lone "\r"
+// does not end a line.
+func countLines(ctx context.Context, fsys fs.FS, path string) (int, error) {
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	r := common.ContextReader(ctx, f)
+	buf := make([]byte, 64*KB)
+	lines := 0
+	var last byte = '\n'
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	if last != '\n' {
+		lines++
+	}
+	return lines, nil
+}
+
+// lineCount counts the lines of the file once per run, see countLines
+func (s *state) lineCount() (int, error) {
+	if s.lines == nil {
+		count, err := countLines(s.ctx, s.fsys, s.path)
+		if err != nil {
+			return 0, err
+		}
+		s.lines = &count
+	}
+	return *s.lines, nil
+}
+
+// endsWithNewline reports whether the last byte of the file at path, size bytes long, is "\n". Files that support
+// io.ReaderAt (an *os.File, fstest.MapFS) have only that byte read; other fs.File implementations are streamed to the
+// end. An empty file has no last byte and does not end with a newline.
+func endsWithNewline(ctx context.Context, fsys fs.FS, path string, size int64) (bool, error) {
+	if size == 0 {
+		return false, nil
+	}
+	f, err := common.OpenFS(fsys, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	last := make([]byte, 1)
+	if r, ok := f.(io.ReaderAt); ok {
+		if _, err := r.ReadAt(last, size-1); err != nil {
+			return false, fmt.Errorf("failed to read the last byte of %s: %w", path, err)
+		}
+		return last[0] == '\n', nil
+	}
+	r := common.ContextReader(ctx, f)
+	buf := make([]byte, 64*KB)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			last[0] = buf[n-1]
+		}
+		if err == io.EOF {
+			return last[0] == '\n', nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+}
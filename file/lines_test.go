@@ -0,0 +1,155 @@
+package file
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileLineCount(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"empty.csv":      "",
+		"header.csv":     "id,name\n",
+		"no-newline.csv": "id,name\n1,alice\n2,bob",
+		"crlf.csv":       "id,name\r\n1,alice\r\n2,bob\r\n",
+		"blank.txt":      "\n\n",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		opts    Options
+		wantErr error
+	}{
+		{"Single header line", "header.csv", Options{IsLineCount: 1}, nil},
+		{"Final line without newline counts", "no-newline.csv", Options{IsLineCount: 3}, nil},
+		{"CRLF counts once per line", "crlf.csv", Options{IsLineCount: 3}, nil},
+		{"Blank lines count", "blank.txt", Options{IsLineCount: 2}, nil},
+		{"Empty file has no lines", "empty.csv", Options{MaxLines: 1}, nil},
+		{"Empty file is not one line", "empty.csv", Options{IsLineCount: 1}, &ErrCheckLineCount{}},
+		{"Empty file below MinLines", "empty.csv", Options{MinLines: 1}, &ErrCheckLineRange{}},
+		{"Wrong exact count", "crlf.csv", Options{IsLineCount: 2}, &ErrCheckLineCount{}},
+		{"Within range", "no-newline.csv", Options{MinLines: 2, MaxLines: 3}, nil},
+		{"Above MaxLines", "no-newline.csv", Options{MaxLines: 2}, &ErrCheckLineRange{}},
+		{"Below MinLines", "header.csv", Options{MinLines: 2, MaxLines: 10}, &ErrCheckLineRange{}},
+		{"MinLines above MaxLines", "header.csv", Options{MinLines: 5, MaxLines: 2}, ErrInvalidOptions},
+		{"IsLineCount outside the range", "header.csv", Options{IsLineCount: 1, MinLines: 2}, ErrInvalidOptions},
+		{"Negative MaxLines", "header.csv", Options{MaxLines: -1}, ErrInvalidOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := File(filepath.Join(dir, tt.file), tt.opts)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("File() error = %v", err)
+				}
+				return
+			case *ErrCheckLineCount:
+				if !errors.As(err, &want) || want.Expected != tt.opts.IsLineCount {
+					t.Errorf("File() error = %v, want *ErrCheckLineCount", err)
+				}
+			case *ErrCheckLineRange:
+				if !errors.As(err, &want) || want.Min != tt.opts.MinLines || want.Max != tt.opts.MaxLines {
+					t.Errorf("File() error = %v, want *ErrCheckLineRange", err)
+				}
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("File() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if !errors.Is(err, ErrSizeMismatch) {
+				t.Errorf("File() error = %v, want it to match ErrSizeMismatch", err)
+			}
+		})
+	}
+
+	t.Run("Through fs.FS", func(t *testing.T) {
+		fsys := fstest.MapFS{"fixtures/users.csv": {Data: []byte("id,name\n1,alice\n")}}
+		if err := FileFS(fsys, "fixtures/users.csv", Options{IsLineCount: 2, MinLines: 1, MaxLines: 2}); err != nil {
+			t.Errorf("FileFS() error = %v", err)
+		}
+	})
+}
+
+// streamFS hides io.ReaderAt and io.Seeker from the files of an fs.FS, so they can only be streamed
+type streamFS struct{ fs.FS }
+
+func (fsys streamFS) Open(name string) (fs.File, error) {
+	f, err := fsys.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ fs.File }{f}, nil
+}
+
+func TestFileTrailingNewline(t *testing.T) {
+	files := map[string]string{
+		"newline.txt":    "line one\nline two\n",
+		"no-newline.txt": "line one\nline two",
+		"crlf.txt":       "line one\r\n",
+		"blank.txt":      "\n",
+		"empty.txt":      "",
+	}
+	dir := t.TempDir()
+	mapFS := fstest.MapFS{}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		mapFS[name] = &fstest.MapFile{Data: []byte(contents)}
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		opts    Options
+		wantErr error
+	}{
+		{"Ends with newline", "newline.txt", Options{RequireTrailingNewline: true}, nil},
+		{"CRLF ends with newline", "crlf.txt", Options{RequireTrailingNewline: true}, nil},
+		{"Only a newline", "blank.txt", Options{RequireTrailingNewline: true}, nil},
+		{"Missing newline", "no-newline.txt", Options{RequireTrailingNewline: true}, ErrContentMismatch},
+		{"Empty file fails RequireTrailingNewline", "empty.txt", Options{RequireTrailingNewline: true}, ErrContentMismatch},
+		{"Forbidden newline", "newline.txt", Options{ForbidTrailingNewline: true}, ErrContentMismatch},
+		{"No newline to forbid", "no-newline.txt", Options{ForbidTrailingNewline: true}, nil},
+		{"Empty file passes ForbidTrailingNewline", "empty.txt", Options{ForbidTrailingNewline: true}, nil},
+		{"Require and Forbid", "newline.txt", Options{RequireTrailingNewline: true, ForbidTrailingNewline: true}, ErrInvalidOptions},
+		{"Require and MustBeEmpty", "empty.txt", Options{RequireTrailingNewline: true, MustBeEmpty: true}, ErrInvalidOptions},
+	}
+	check := map[string]func(path string, opts Options) error{
+		"OS":       File,
+		"MapFS":    func(path string, opts Options) error { return FileFS(mapFS, path, opts) },
+		"streamFS": func(path string, opts Options) error { return FileFS(streamFS{mapFS}, path, opts) },
+	}
+	for via, file := range check {
+		for _, tt := range tests {
+			t.Run(via+"/"+tt.name, func(t *testing.T) {
+				path := tt.file
+				if via == "OS" {
+					path = filepath.Join(dir, tt.file)
+				}
+				err := file(path, tt.opts)
+				if tt.wantErr == nil {
+					if err != nil {
+						t.Errorf("File() error = %v", err)
+					}
+					return
+				}
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("File() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
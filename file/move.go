@@ -0,0 +1,71 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// MoveOptions controls Move
+type MoveOptions struct {
+	Overwrite bool // Overwrite replaces an existing dst, otherwise Move fails with ErrAlreadyExists
+	Sync      bool // Sync fsyncs the directories of src and dst, and the copy when Move falls back to copying
+}
+
+// rename is os.Rename, tests replace it to simulate a move across filesystems
+var rename = os.Rename
+
+// Move moves the regular file src to dst with os.Rename. When they are on different filesystems (EXDEV) it falls back
+// to a Copy that preserves the mode and modification time of src, and removes src only once the copy has succeeded; a
+// failed copy leaves src untouched and no partial dst behind.
+func Move(src, dst string, opts MoveOptions) error {
+	srcInfo, err := os.Stat(src)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return common.Errorf(ErrDoesNotExist, "move source does not exist: %s", src)
+	case err != nil:
+		return fmt.Errorf("failed to stat file %s: %w", src, err)
+	case !srcInfo.Mode().IsRegular():
+		return common.Errorf(ErrNotRegularFile, "cannot move %s: not a regular file", src)
+	}
+	dstInfo, err := os.Stat(dst)
+	exists := err == nil
+	switch {
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to stat file %s: %w", dst, err)
+	case exists && !opts.Overwrite:
+		return common.Errorf(ErrAlreadyExists, "cannot move %s to %s: destination exists", src, dst)
+	case exists && !dstInfo.Mode().IsRegular():
+		return common.Errorf(ErrNotRegularFile, "cannot move %s to %s: destination is not a regular file", src, dst)
+	case exists && os.SameFile(srcInfo, dstInfo):
+		return fmt.Errorf("%w: cannot move %s onto itself", ErrInvalidOptions, src)
+	}
+
+	err = rename(src, dst)
+	if err != nil && !errors.Is(err, syscall.EXDEV) {
+		return fmt.Errorf("could not move %s to %s: %w", src, dst, err)
+	}
+	if err != nil {
+		copyOpts := CopyOptions{Overwrite: opts.Overwrite, PreserveMode: true, PreserveTimes: true, Sync: opts.Sync}
+		if err := Copy(src, dst, copyOpts); err != nil {
+			return err
+		}
+		if err := os.Remove(src); err != nil {
+			return fmt.Errorf("failed to remove %s after copying it to %s: %w", src, dst, err)
+		}
+	}
+	if opts.Sync {
+		if err := common.SyncDir(filepath.Dir(dst)); err != nil {
+			return err
+		}
+		if filepath.Dir(src) != filepath.Dir(dst) {
+			return common.SyncDir(filepath.Dir(src))
+		}
+	}
+	return nil
+}
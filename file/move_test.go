@@ -0,0 +1,111 @@
+package file
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestMove(t *testing.T) {
+	stamp := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	exdev := func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EXDEV}
+	}
+
+	tests := []struct {
+		name     string
+		rename   func(string, string) error
+		existing bool
+		opts     MoveOptions
+		wantErr  error
+	}{
+		{"Rename", os.Rename, false, MoveOptions{}, nil},
+		{"Rename with sync", os.Rename, false, MoveOptions{Sync: true}, nil},
+		{"Rename over existing", os.Rename, true, MoveOptions{Overwrite: true}, nil},
+		{"Existing without Overwrite", os.Rename, true, MoveOptions{}, ErrAlreadyExists},
+		{"Cross-device fallback", exdev, false, MoveOptions{Sync: true}, nil},
+		{"Cross-device over existing", exdev, true, MoveOptions{Overwrite: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(r func(string, string) error) { rename = r }(rename)
+			rename = tt.rename
+
+			srcDir, dstDir := t.TempDir(), t.TempDir()
+			src, dst := filepath.Join(srcDir, "src.txt"), filepath.Join(dstDir, "dst.txt")
+			if err := os.WriteFile(src, []byte("payload"), 0644); err != nil {
+				t.Fatalf("Failed to create source: %v", err)
+			}
+			if err := os.Chmod(src, 0640); err != nil {
+				t.Fatalf("Failed to chmod source: %v", err)
+			}
+			if err := os.Chtimes(src, stamp, stamp); err != nil {
+				t.Fatalf("Failed to set source times: %v", err)
+			}
+			if tt.existing {
+				if err := os.WriteFile(dst, []byte("existing"), 0600); err != nil {
+					t.Fatalf("Failed to create destination: %v", err)
+				}
+			}
+
+			err := Move(src, dst, tt.opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Move() error = %v, want %v", err, tt.wantErr)
+				}
+				if _, err := os.Stat(src); err != nil {
+					t.Errorf("source removed by a failed move: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Move() error = %v", err)
+			}
+			if _, err := os.Stat(src); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("source still exists after the move: %v", err)
+			}
+			if got, err := os.ReadFile(dst); err != nil || string(got) != "payload" {
+				t.Errorf("destination = %q, %v, want %q", got, err, "payload")
+			}
+			info, err := os.Stat(dst)
+			if err != nil {
+				t.Fatalf("Failed to stat destination: %v", err)
+			}
+			if runtime.GOOS != "windows" && info.Mode().Perm() != 0640 {
+				t.Errorf("destination mode = %o, want 0640 from the source", info.Mode().Perm())
+			}
+			if !info.ModTime().Equal(stamp) {
+				t.Errorf("destination modtime = %v, want %v", info.ModTime(), stamp)
+			}
+		})
+	}
+}
+
+func TestMoveFailedCopy(t *testing.T) {
+	defer func(r func(string, string) error) { rename = r }(rename)
+	rename = func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EXDEV}
+	}
+	errRead := errors.New("read failed")
+	defer func(c func(io.Writer, io.Reader, []byte) (int64, error)) { copyBuffer = c }(copyBuffer)
+	copyBuffer = func(io.Writer, io.Reader, []byte) (int64, error) { return 0, errRead }
+
+	src, dst := filepath.Join(t.TempDir(), "src.txt"), filepath.Join(t.TempDir(), "dst.txt")
+	if err := os.WriteFile(src, []byte("payload"), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	if err := Move(src, dst, MoveOptions{}); !errors.Is(err, errRead) {
+		t.Fatalf("Move() error = %v, want the copy error", err)
+	}
+	if got, err := os.ReadFile(src); err != nil || string(got) != "payload" {
+		t.Errorf("source = %q, %v, want it kept after a failed copy", got, err)
+	}
+	if _, err := os.Stat(dst); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("partial destination left behind: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package file
+
+import (
+	"os"
+	"time"
+)
+
+// Option sets one or more fields of the Options that Validate and NewOptions assemble. Options apply in order, so
+// when two set the same field the later one wins.
+type Option func(opts *Options)
+
+// NewOptions returns the Options that applying opts, in order, to a zero Options produces
+func NewOptions(opts ...Option) Options {
+	var options Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// Validate performs the file checks with the Options that opts assemble, exactly as File does with them, e.g.
+//
+//	err := file.Validate(path, file.Exists(), file.WithExt(".txt"), file.WithMaxSize(1024))
+func Validate(path string, opts ...Option) error {
+	return File(path, NewOptions(opts...))
+}
+
+// Exists sets Exists
+func Exists() Option {
+	return func(opts *Options) { opts.Exists = true }
+}
+
+// NonEmpty sets NonEmpty
+func NonEmpty() Option {
+	return func(opts *Options) { opts.NonEmpty = true }
+}
+
+// MustBeEmpty sets MustBeEmpty
+func MustBeEmpty() Option {
+	return func(opts *Options) { opts.MustBeEmpty = true }
+}
+
+// ReadOnly sets ReadOnly
+func ReadOnly() Option {
+	return func(opts *Options) { opts.ReadOnly = true }
+}
+
+// Writable sets RequireWrite
+func Writable() Option {
+	return func(opts *Options) { opts.RequireWrite = true }
+}
+
+// Executable sets RequireExecutable
+func Executable() Option {
+	return func(opts *Options) { opts.RequireExecutable = true }
+}
+
+// WithExt sets RequireExt, e.g. ".txt"
+func WithExt(ext string) Option {
+	return func(opts *Options) { opts.RequireExt = ext }
+}
+
+// WithExts sets RequireExts
+func WithExts(exts ...string) Option {
+	return func(opts *Options) { opts.RequireExts = exts }
+}
+
+// WithPrefix sets RequirePrefix
+func WithPrefix(prefix string) Option {
+	return func(opts *Options) { opts.RequirePrefix = prefix }
+}
+
+// WithSuffix sets RequireSuffix
+func WithSuffix(suffix string) Option {
+	return func(opts *Options) { opts.RequireSuffix = suffix }
+}
+
+// WithSize sets IsSize; 0 leaves IsSize unset, so use MustBeEmpty to require an empty file
+func WithSize(n int64) Option {
+	return func(opts *Options) { opts.IsSize = n }
+}
+
+// WithMaxSize sets MaxSize, so a file of exactly n bytes passes; 0 leaves MaxSize unset, so use MustBeEmpty to
+// require an empty file
+func WithMaxSize(n int64) Option {
+	return func(opts *Options) { opts.MaxSize = n }
+}
+
+// WithMinSize sets MinSize, so a file of exactly n bytes passes
+func WithMinSize(n int64) Option {
+	return func(opts *Options) { opts.MinSize = n }
+}
+
+// WithOwner sets RequireOwnerName, e.g. "deploy"
+func WithOwner(name string) Option {
+	return func(opts *Options) { opts.RequireOwnerName = name }
+}
+
+// WithGroup sets RequireGroupName
+func WithGroup(name string) Option {
+	return func(opts *Options) { opts.RequireGroupName = name }
+}
+
+// WithOwnerUID sets RequireOwner, the numeric UID as a string
+func WithOwnerUID(uid string) Option {
+	return func(opts *Options) { opts.RequireOwner = uid }
+}
+
+// WithGroupGID sets RequireGroup, the numeric GID as a string
+func WithGroupGID(gid string) Option {
+	return func(opts *Options) { opts.RequireGroup = gid }
+}
+
+// WithBaseDir sets RequireBaseDir
+func WithBaseDir(dir string) Option {
+	return func(opts *Options) { opts.RequireBaseDir = dir }
+}
+
+// WithMode sets IsFileMode
+func WithMode(mode os.FileMode) Option {
+	return func(opts *Options) { opts.IsFileMode = mode }
+}
+
+// WithMorePermissiveThan sets MorePermissiveThan
+func WithMorePermissiveThan(mode os.FileMode) Option {
+	return func(opts *Options) { opts.MorePermissiveThan = mode }
+}
+
+// WithLessPermissiveThan sets LessPermissiveThan
+func WithLessPermissiveThan(mode os.FileMode) Option {
+	return func(opts *Options) { opts.LessPermissiveThan = mode }
+}
+
+// WithModifiedBefore sets ModifiedBefore
+func WithModifiedBefore(t time.Time) Option {
+	return func(opts *Options) { opts.ModifiedBefore = t }
+}
+
+// WithModifiedAfter sets ModifiedAfter
+func WithModifiedAfter(t time.Time) Option {
+	return func(opts *Options) { opts.ModifiedAfter = t }
+}
+
+// WithMaxAge sets MaxAge
+func WithMaxAge(d time.Duration) Option {
+	return func(opts *Options) { opts.MaxAge = d }
+}
+
+// WithMinAge sets MinAge
+func WithMinAge(d time.Duration) Option {
+	return func(opts *Options) { opts.MinAge = d }
+}
+
+// WithSHA256 sets RequireSHA256, a hex-encoded digest
+func WithSHA256(digest string) Option {
+	return func(opts *Options) { opts.RequireSHA256 = digest }
+}
+
+// WithContent sets RequireContent
+func WithContent(content []byte) Option {
+	return func(opts *Options) { opts.RequireContent = content }
+}
+
+// WithContentType sets RequireContentType, e.g. "image/png"
+func WithContentType(contentType string) Option {
+	return func(opts *Options) { opts.RequireContentType = contentType }
+}
+
+// WithCreate sets Create
+func WithCreate(create Create) Option {
+	return func(opts *Options) { opts.Create = create }
+}
@@ -0,0 +1,72 @@
+package file
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want Options
+	}{
+		{"None", nil, Options{}},
+		{"Composed", []Option{Exists(), WithExt(".txt"), WithMaxSize(1024)},
+			Options{Exists: true, RequireExt: ".txt", MaxSize: 1024}},
+		{"Later wins", []Option{WithExt(".txt"), WithMaxSize(1024), WithExt(".md"), WithMaxSize(10)},
+			Options{RequireExt: ".md", MaxSize: 10}},
+		{"Different fields compose", []Option{MustBeEmpty(), WithMinSize(1), WithOwner("deploy"), WithOwnerUID("1000")},
+			Options{MustBeEmpty: true, MinSize: 1, RequireOwnerName: "deploy", RequireOwner: "1000"}},
+		{"Zero then non-zero", []Option{WithSize(0), WithSize(5), WithMaxSize(0), WithMaxSize(10)}, Options{IsSize: 5, MaxSize: 10}},
+		{"Non-zero then zero", []Option{WithSize(5), WithSize(0), WithMaxSize(10), WithMaxSize(0)}, Options{}},
+		{"Custom option", []Option{WithMode(0600), func(opts *Options) { opts.IsFileMode = 0640 }},
+			Options{IsFileMode: 0640}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewOptions(tt.opts...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := Check("a.txt").With(WithExt(".txt"), Exists()).Options(), NewOptions(Exists(), WithExt(".txt")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.With() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := Validate(path, Exists(), WithExt(".txt"), WithMaxSize(5), NonEmpty()); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := Validate(path, WithMaxSize(4)); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("Validate() error = %v, want ErrSizeMismatch", err)
+	}
+	if err := Validate(path, WithMaxSize(4), WithMaxSize(5)); err != nil {
+		t.Errorf("Validate() with MaxSize overridden error = %v, want nil", err)
+	}
+	if err := Validate(path, WithMinSize(5), WithMaxSize(math.MaxInt64)); err != nil {
+		t.Errorf("Validate() with the largest MaxSize error = %v, want nil", err)
+	}
+	if err := Validate(path, WithMaxSize(0), WithMaxSize(10)); err != nil {
+		t.Errorf("Validate() with MaxSize 0 overridden error = %v, want nil", err)
+	}
+	if err := Validate(path, WithSize(5), WithSize(0)); err != nil {
+		t.Errorf("Validate() with IsSize overridden by 0 error = %v, want nil", err)
+	}
+	if err := Validate(path, WithMinSize(6)); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("Validate() error = %v, want ErrSizeMismatch", err)
+	}
+	if err := Validate(path, NonEmpty(), MustBeEmpty()); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Validate() error = %v, want ErrInvalidOptions", err)
+	}
+}
@@ -0,0 +1,49 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// RemoveOptions guards Remove
+type RemoveOptions struct {
+	RequireBaseDir string // RequireBaseDir refuses to remove a path outside this directory, lexically or through symlinks
+	MustExist      bool   // MustExist fails with ErrDoesNotExist when path is already gone instead of succeeding
+}
+
+// Remove removes the regular file, or symlink, at path after checking opts. It never removes a directory: those fail
+// with ErrNotRegularFile, see directory.Remove. A symlink is removed itself, never its target.
+func Remove(path string, opts RemoveOptions) error {
+	if path == "" || common.IsFilesystemRoot(path) {
+		return fmt.Errorf("%w: refusing to remove %q", ErrInvalidOptions, path)
+	}
+	if opts.RequireBaseDir != "" {
+		inBase, err := common.IsStrictlyInBaseResolved(path, opts.RequireBaseDir)
+		if err != nil {
+			return fmt.Errorf("failed to check base directory: %w", err)
+		}
+		if !inBase {
+			return &ErrCheckBadBaseDir{Path: path, BaseDir: opts.RequireBaseDir}
+		}
+	}
+	info, err := os.Lstat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if opts.MustExist {
+			return common.Errorf(ErrDoesNotExist, "cannot remove missing file: %s", path)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to stat file %s: %w", path, err)
+	case !info.Mode().IsRegular() && info.Mode()&fs.ModeSymlink == 0:
+		return common.Errorf(ErrNotRegularFile, "cannot remove %s: not a regular file", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("could not remove file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRemove(t *testing.T) {
+	base := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	if err := os.WriteFile(outside, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to create outside file: %v", err)
+	}
+	create := func(t *testing.T, name string) string {
+		path := filepath.Join(base, name)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name        string
+		path        func(t *testing.T) string
+		opts        RemoveOptions
+		wantErr     error
+		wantRemoved bool
+	}{
+		{"Inside base", func(t *testing.T) string { return create(t, "inside.txt") }, RemoveOptions{RequireBaseDir: base}, nil, true},
+		{"Outside base", func(t *testing.T) string { return outside }, RemoveOptions{RequireBaseDir: base}, ErrBadBaseDir, false},
+		{"Missing", func(t *testing.T) string { return filepath.Join(base, "missing.txt") }, RemoveOptions{}, nil, true},
+		{"Missing with MustExist", func(t *testing.T) string { return filepath.Join(base, "missing.txt") }, RemoveOptions{MustExist: true}, ErrDoesNotExist, true},
+		{"Directory", func(t *testing.T) string { return t.TempDir() }, RemoveOptions{}, ErrNotRegularFile, false},
+		{"Empty path", func(t *testing.T) string { return "" }, RemoveOptions{}, ErrInvalidOptions, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path(t)
+			err := Remove(path, tt.opts)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("Remove() error = %v, want %v", err, tt.wantErr)
+			}
+			if path == "" {
+				return
+			}
+			_, statErr := os.Lstat(path)
+			if removed := errors.Is(statErr, os.ErrNotExist); removed != tt.wantRemoved {
+				t.Errorf("Remove() left %s removed = %v, want %v", path, removed, tt.wantRemoved)
+			}
+		})
+	}
+
+	t.Run("Symlink", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("symlinks need extra privileges on Windows")
+		}
+		link := filepath.Join(base, "link.txt")
+		if err := os.Symlink(outside, link); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		if err := Remove(link, RemoveOptions{RequireBaseDir: base}); err != nil {
+			t.Fatalf("Remove() error = %v", err)
+		}
+		if _, err := os.Lstat(link); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("symlink still exists: %v", err)
+		}
+		if _, err := os.Stat(outside); err != nil {
+			t.Errorf("symlink target removed: %v", err)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// CreateSymlink describes a symlink at LinkPath pointing at Target, such as current -> releases/v2
+type CreateSymlink struct {
+	Target   string     // Target is what the link points at, relative to the directory of LinkPath unless absolute
+	LinkPath string     // LinkPath is where the link is created
+	Kind     CreateKind // Kind decides what happens to a link already at LinkPath, see Run
+
+	RequireBaseDir string // RequireBaseDir refuses a Target that escapes this directory, lexically or through symlinks
+}
+
+// Run creates the link according to Kind. Whatever the Kind, a LinkPath that exists but is not a symlink fails with
+// ErrAlreadyExists and is left alone:
+//   - IfNotExists creates the link when LinkPath is missing and leaves an existing link as it is
+//   - IfExists points LinkPath at Target whether or not a link is there yet. An existing link is replaced atomically:
+//     a temporary link is made beside it and renamed over it, so LinkPath always resolves to either the old or the
+//     new Target
+//   - EnsureValid is IfExists that leaves a link already pointing at Target untouched
+//   - NoAction does nothing
+//
+// Any other Kind fails with ErrUnknownCreateKind.
+func (link *CreateSymlink) Run() error {
+	switch link.Kind {
+	case NoAction:
+		return nil
+	case IfNotExists, IfExists, EnsureValid:
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownCreateKind, link.Kind)
+	}
+	if link.Target == "" || link.LinkPath == "" {
+		return fmt.Errorf("%w: CreateSymlink requires Target and LinkPath", ErrInvalidOptions)
+	}
+	if link.RequireBaseDir != "" {
+		target := link.Target
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(link.LinkPath), target)
+		}
+		inBase, err := common.IsPathInBaseResolved(target, link.RequireBaseDir)
+		if err != nil {
+			return fmt.Errorf("failed to check base directory: %w", err)
+		}
+		if !inBase {
+			return &ErrCheckBadBaseDir{Path: target, BaseDir: link.RequireBaseDir}
+		}
+	}
+
+	info, err := os.Lstat(link.LinkPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := os.Symlink(link.Target, link.LinkPath); err != nil {
+			return fmt.Errorf("could not create symlink: %w", err)
+		}
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to stat %s: %w", link.LinkPath, err)
+	case info.Mode()&fs.ModeSymlink == 0:
+		return common.Errorf(ErrAlreadyExists, "cannot create symlink %s: it exists and is not a symlink", link.LinkPath)
+	case link.Kind == IfNotExists:
+		return nil
+	case link.Kind == EnsureValid:
+		if current, err := os.Readlink(link.LinkPath); err == nil && current == link.Target {
+			return nil
+		}
+	}
+	return link.replace()
+}
+
+// replace points the link at LinkPath at Target by renaming a new link over it; the new link is removed on any error
+func (link *CreateSymlink) replace() error {
+	tmp, err := os.CreateTemp(filepath.Dir(link.LinkPath), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("could not create temp link: %w", err)
+	}
+	name := tmp.Name()
+	_ = tmp.Close()
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("could not create temp link: %w", err)
+	}
+	if err := os.Symlink(link.Target, name); err != nil {
+		return fmt.Errorf("could not create temp link: %w", err)
+	}
+	if err := os.Rename(name, link.LinkPath); err != nil {
+		_ = os.Remove(name)
+		return fmt.Errorf("could not replace symlink: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateSymlink(t *testing.T) {
+	dir := t.TempDir()
+	for _, release := range []string{"v1", "v2"} {
+		if err := os.MkdirAll(filepath.Join(dir, "releases", release), 0755); err != nil {
+			t.Fatalf("Failed to create test directory: %v", err)
+		}
+	}
+	current := filepath.Join(dir, "current")
+	if err := os.Symlink(filepath.Join("releases", "v1"), filepath.Join(dir, "probe")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	// target fails unless current is a symlink to want and no temp link was left beside it
+	target := func(t *testing.T, want string) os.FileInfo {
+		t.Helper()
+		got, err := os.Readlink(current)
+		if err != nil || got != want {
+			t.Fatalf("Readlink() = %q, %v, want %q", got, err, want)
+		}
+		if tmps, _ := filepath.Glob(filepath.Join(dir, ".tmp-*")); len(tmps) > 0 {
+			t.Errorf("temp links left behind: %v", tmps)
+		}
+		info, err := os.Lstat(current)
+		if err != nil {
+			t.Fatalf("Lstat() error = %v", err)
+		}
+		return info
+	}
+	v1, v2 := filepath.Join("releases", "v1"), filepath.Join("releases", "v2")
+
+	t.Run("Create", func(t *testing.T) {
+		if err := (&CreateSymlink{Target: v1, LinkPath: current, Kind: IfNotExists}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		target(t, v1)
+	})
+
+	t.Run("Idempotent re-create", func(t *testing.T) {
+		before := target(t, v1)
+		for _, link := range []CreateSymlink{
+			{Target: v2, LinkPath: current, Kind: IfNotExists},
+			{Target: v1, LinkPath: current, Kind: EnsureValid},
+		} {
+			if err := link.Run(); err != nil {
+				t.Fatalf("Run(%v) error = %v", link.Kind, err)
+			}
+			if after := target(t, v1); !os.SameFile(before, after) {
+				t.Errorf("Run(%v) replaced a link it should have left alone", link.Kind)
+			}
+		}
+	})
+
+	t.Run("Atomic replace", func(t *testing.T) {
+		before := target(t, v1)
+		if err := (&CreateSymlink{Target: v2, LinkPath: current, Kind: IfExists}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if after := target(t, v2); os.SameFile(before, after) {
+			t.Error("Run() kept the old link, want a new one renamed over it")
+		}
+		if err := (&CreateSymlink{Target: v1, LinkPath: current, Kind: EnsureValid}).Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		target(t, v1)
+	})
+
+	t.Run("Refused", func(t *testing.T) {
+		plain := filepath.Join(dir, "plain")
+		if err := os.WriteFile(plain, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		for _, kind := range []CreateKind{IfNotExists, IfExists, EnsureValid} {
+			if err := (&CreateSymlink{Target: v1, LinkPath: plain, Kind: kind}).Run(); !errors.Is(err, ErrAlreadyExists) {
+				t.Errorf("Run(%v) over a regular file error = %v, want ErrAlreadyExists", kind, err)
+			}
+		}
+		if got, err := os.ReadFile(plain); err != nil || string(got) != "x" {
+			t.Errorf("the regular file was changed: %q, %v", got, err)
+		}
+
+		escape := &CreateSymlink{Target: filepath.Join("..", ".."), LinkPath: current, Kind: IfExists, RequireBaseDir: dir}
+		if err := escape.Run(); !errors.Is(err, ErrBadBaseDir) {
+			t.Errorf("Run() with a Target outside RequireBaseDir error = %v, want ErrBadBaseDir", err)
+		}
+		if err := os.Symlink("..", filepath.Join(dir, "up")); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		through := &CreateSymlink{Target: filepath.Join("up", "missing", "x"), LinkPath: current, Kind: IfExists, RequireBaseDir: dir}
+		if err := through.Run(); !errors.Is(err, ErrBadBaseDir) {
+			t.Errorf("Run() with a missing Target below a symlink out of RequireBaseDir error = %v, want ErrBadBaseDir", err)
+		}
+		inside := &CreateSymlink{Target: v2, LinkPath: current, Kind: IfExists, RequireBaseDir: dir}
+		if err := inside.Run(); err != nil {
+			t.Errorf("Run() with a Target inside RequireBaseDir error = %v", err)
+		}
+		if err := (&CreateSymlink{Target: v1, LinkPath: current, Kind: 42}).Run(); !errors.Is(err, ErrUnknownCreateKind) {
+			t.Errorf("Run() with an unknown Kind error = %v, want ErrUnknownCreateKind", err)
+		}
+		if err := (&CreateSymlink{LinkPath: current, Kind: IfExists}).Run(); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Run() without Target error = %v, want ErrInvalidOptions", err)
+		}
+	})
+}
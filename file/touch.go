@@ -0,0 +1,45 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// TouchMode is the mode Touch creates missing files with, before the umask
+const TouchMode os.FileMode = 0644
+
+// Touch sets the access and modification times of the file at path to t, or to time.Now() when t is zero, like the
+// Unix touch command. A missing file is created empty with TouchMode when createIfMissing is set and fails with
+// ErrDoesNotExist otherwise; a directory or other non-regular file fails with ErrNotRegularFile.
+func Touch(path string, t time.Time, createIfMissing bool) error {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if !createIfMissing {
+			return common.Errorf(ErrDoesNotExist, "cannot touch missing file: %s", path)
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, TouchMode)
+		if err != nil {
+			return fmt.Errorf("could not create file: %w", err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("could not close file: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to stat file %s: %w", path, err)
+	case !info.Mode().IsRegular():
+		return common.Errorf(ErrNotRegularFile, "cannot touch %s: not a regular file", path)
+	}
+	if err := os.Chtimes(path, t, t); err != nil {
+		return fmt.Errorf("could not set times on %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTouch(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("keep"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		path            string
+		t               time.Time
+		createIfMissing bool
+		wantErr         error
+	}{
+		{"Existing file at a time", existing, when, false, nil},
+		{"Missing file created", filepath.Join(dir, "new.txt"), when, true, nil},
+		{"Missing file not created", filepath.Join(dir, "absent.txt"), when, false, ErrDoesNotExist},
+		{"Directory", dir, when, true, ErrNotRegularFile},
+		{"Missing parent", filepath.Join(dir, "nested", "new.txt"), when, true, os.ErrNotExist},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Touch(tt.path, tt.t, tt.createIfMissing)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Touch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Touch() error = %v", err)
+			}
+			info, err := os.Stat(tt.path)
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if !info.ModTime().Equal(when) {
+				t.Errorf("ModTime() = %v, want %v", info.ModTime(), when)
+			}
+		})
+	}
+
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "keep" {
+		t.Errorf("Touch() changed the contents of %s: %q, %v", existing, data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "absent.txt")); !os.IsNotExist(err) {
+		t.Errorf("Touch() without createIfMissing created the file: %v", err)
+	}
+
+	t.Run("Zero time means now", func(t *testing.T) {
+		before := time.Now().Add(-time.Second)
+		if err := Touch(existing, time.Time{}, false); err != nil {
+			t.Fatalf("Touch() error = %v", err)
+		}
+		info, err := os.Stat(existing)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if info.ModTime().Before(before) {
+			t.Errorf("ModTime() = %v, want about now", info.ModTime())
+		}
+	})
+}
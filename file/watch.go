@@ -0,0 +1,121 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/andreimerlescu/checkfs/common"
+)
+
+// WatchKind is the kind of change a WatchEvent reports
+type WatchKind int8
+
+const (
+	Modified WatchKind = iota + 1 // Modified is a change of size, modification time or, on unix, inode (a replacement)
+	Removed                       // Removed is the file disappearing
+	Created                       // Created is the file appearing where there was none
+)
+
+// String returns the name of the WatchKind constant, such as "Modified"
+func (k WatchKind) String() string {
+	switch k {
+	case Modified:
+		return "Modified"
+	case Removed:
+		return "Removed"
+	case Created:
+		return "Created"
+	}
+	return fmt.Sprintf("WatchKind(%d)", int8(k))
+}
+
+// WatchEvent is a change Watch saw between two polls
+type WatchEvent struct {
+	Kind WatchKind
+	Path string
+	Info os.FileInfo // Info is the file after the change, nil for Removed
+}
+
+// watchState is what Watch compares between polls; a zero watchState is a missing file
+type watchState struct {
+	exists   bool
+	size     int64
+	modTime  time.Time
+	dev, ino uint64
+}
+
+// Watch polls path every poll and sends a WatchEvent on the returned channel whenever the file is created, removed or
+// modified, comparing size, modification time and, on unix, device and inode so a file replaced by a rename is seen
+// even when the other two match. It is polling, not inotify or kqueue: changes that come and go within one poll are
+// missed and several in one poll are reported as one. path may be missing when Watch is called, in which case its
+// creation is the first event. Stat errors other than the file missing are skipped until the next poll. Polling stops
+// and the channel is closed once ctx is done.
+func Watch(ctx context.Context, path string, poll time.Duration) (<-chan WatchEvent, error) {
+	if path == "" {
+		return nil, fmt.Errorf("%w: Watch requires a path", ErrInvalidOptions)
+	}
+	if poll <= 0 {
+		return nil, fmt.Errorf("%w: Watch poll interval must be positive, got %s", ErrInvalidOptions, poll)
+	}
+	last, _, err := watchStat(path)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan WatchEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(poll)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current, info, err := watchStat(path)
+			if err != nil || current.same(last) {
+				continue
+			}
+			event := WatchEvent{Kind: Modified, Path: path, Info: info}
+			switch {
+			case !current.exists:
+				event.Kind = Removed
+			case !last.exists:
+				event.Kind = Created
+			}
+			last = current
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// same reports whether w and other describe the same file in the same state
+func (w watchState) same(other watchState) bool {
+	return w.exists == other.exists && w.size == other.size && w.modTime.Equal(other.modTime) &&
+		w.dev == other.dev && w.ino == other.ino
+}
+
+// watchStat stats path for Watch; a missing file is a zero watchState and no error
+func watchStat(path string) (watchState, os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return watchState{}, nil, nil
+	}
+	if err != nil {
+		return watchState{}, nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	state := watchState{exists: true, size: info.Size(), modTime: info.ModTime()}
+	if dev, ino, err := common.FileIdentityInfo(info); err == nil {
+		state.dev, state.ino = dev, ino
+	}
+	return state, info, nil
+}
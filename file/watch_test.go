@@ -0,0 +1,78 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	if err := os.WriteFile(path, []byte("port: 80"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := Watch(ctx, path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	next := func(want WatchKind) {
+		t.Helper()
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("Watch() channel closed, want a %s event", want)
+			}
+			if event.Kind != want || event.Path != path || (event.Info == nil) != (want == Removed) {
+				t.Fatalf("Watch() event = %+v, want %s for %s", event, want, path)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Watch() sent nothing, want a %s event", want)
+		}
+	}
+
+	// The new content is renamed into place so no poll can see it half written, and it has another size so the change
+	// is seen even where modification times are coarse and inodes are not compared
+	if err := os.WriteFile(path+".new", []byte("port: 8080"), 0644); err != nil {
+		t.Fatalf("Failed to write new content: %v", err)
+	}
+	if err := os.Rename(path+".new", path); err != nil {
+		t.Fatalf("Failed to modify test file: %v", err)
+	}
+	next(Modified)
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove test file: %v", err)
+	}
+	next(Removed)
+	if err := os.WriteFile(path, []byte("port: 443"), 0644); err != nil {
+		t.Fatalf("Failed to recreate test file: %v", err)
+	}
+	next(Created)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Errorf("Watch() sent an event after cancel, want the channel closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("Watch() channel still open after cancel")
+	}
+}
+
+func TestWatchErrors(t *testing.T) {
+	ctx := context.Background()
+	if _, err := Watch(ctx, "", time.Second); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Watch() without a path error = %v, want ErrInvalidOptions", err)
+	}
+	if _, err := Watch(ctx, filepath.Join(t.TempDir(), "app.yaml"), 0); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Watch() with a zero poll error = %v, want ErrInvalidOptions", err)
+	}
+	if got := Removed.String(); got != "Removed" {
+		t.Errorf("Removed.String() = %q, want \"Removed\"", got)
+	}
+}
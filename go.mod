@@ -1,3 +1,11 @@
 module github.com/andreimerlescu/checkfs
 
 go 1.20
+
+require (
+	golang.org/x/crypto v0.17.0
+	golang.org/x/net v0.19.0
+	golang.org/x/text v0.14.0
+)
+
+require golang.org/x/sys v0.15.0 // indirect